@@ -1,6 +1,8 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -8,6 +10,7 @@ import (
 	"time"
 
 	"github.com/andiq123/FindVibeFiber/internal/core/constants"
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
 	"github.com/andiq123/FindVibeFiber/internal/utils"
 )
 
@@ -27,6 +30,17 @@ type HTTPConfig struct {
 	// ProviderProxies — comma/newline list for Mp3pm/Mp3mn scrape rotation.
 	// Also honors HTTP_PROXY / HTTPS_PROXY / ALL_PROXY via the scrape transport.
 	ProviderProxies string
+	// ProviderPools — PROVIDER_PROXIES_<NAME> (e.g. _MUSIFY) per-provider lists keyed by
+	// lowercase provider name; providers without one rotate a private copy of ProviderProxies.
+	ProviderPools map[string]string
+	// CookieStore — SCRAPE_COOKIE_STORE=db|file persists scrape cookies per proxy; empty keeps them in memory.
+	CookieStore string
+	CookieFile  string
+	// Warmup — provider homepage warm-ups at boot (SCRAPE_WARMUP=0 disables).
+	Warmup bool
+	// ProxyProbeURL is fetched through every proxy each ProxyProbeEvery (0 disables).
+	ProxyProbeURL   string
+	ProxyProbeEvery time.Duration
 }
 
 type ServerConfig struct {
@@ -34,11 +48,93 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// AdminToken guards /admin/* (ADMIN_TOKEN); empty keeps those routes off.
+	AdminToken string
 }
 
 type SearchConfig struct {
 	Timeout    time.Duration
 	MaxResults int
+	// VerifyLinks — ranged-GET audio sniff before a link is returned (SEARCH_VERIFY_LINKS=1).
+	VerifyLinks  bool
+	VerifyBudget time.Duration
+	VerifyPeek   int
+}
+
+// SubsonicConfig points at a self-hosted Subsonic/Navidrome library (optional).
+type SubsonicConfig struct {
+	URL      string
+	User     string
+	Password string
+	Priority int
+}
+
+// LibraryConfig indexes a local music folder; PublicURL is the https origin
+// clients use to reach this server's /stream (optional).
+type LibraryConfig struct {
+	Dir       string
+	PublicURL string
+	Rescan    time.Duration
+	Priority  int
+}
+
+// StreamConfig — /stream proxy disk cache (CacheDir empty = off) and URL signing
+// (SigningKey empty = open /stream). PreviousKeys verify for SigningGrace after boot.
+type StreamConfig struct {
+	CacheDir     string
+	CacheBytes   int64
+	SigningKey   string
+	PreviousKeys []string
+	URLTTL       time.Duration
+	SigningGrace time.Duration
+	// PublicURL prefixes signed URLs ("" → relative /stream?…).
+	PublicURL string
+	// ZipMaxTracks caps vault / playlist ZIP downloads.
+	ZipMaxTracks int
+	// Daily proxied-byte quota per client IP (0 = unlimited, still metered).
+	QuotaIPBytes int64
+	QuotaFlush   time.Duration
+}
+
+// RadioConfig — artist radio mix: ArtistOwn of the artist's own top tracks for every
+// ArtistSimilar from similar artists, never more than ArtistMaxRun in a row by one artist.
+// Co-saves ("saved X also saved Y") are rebuilt every CoSaveRebuild (0 = off) and only
+// pairs saved by at least CoSaveMinUsers people are used. Users due daily mixes are
+// looked for every DailyMixCheck (0 = off); DailyMixRefreshes caps on-demand rebuilds
+// per day.
+type RadioConfig struct {
+	ArtistOwn     int
+	ArtistSimilar int
+	ArtistMaxRun  int
+
+	CoSaveRebuild  time.Duration
+	CoSaveMinUsers int
+
+	DailyMixCheck     time.Duration
+	DailyMixRefreshes int
+}
+
+// ExploreConfig — Country is served when the client doesn't pick one (EXPLORE_COUNTRY,
+// code or name). Catalog comes from the JSON file at EXPLORE_SHELVES_FILE; empty keeps
+// the built-in shelves. Shelves due a rebuild are looked for every Refresh (0 = build
+// on demand only).
+type ExploreConfig struct {
+	Country string
+	Catalog domain.ExploreCatalog
+	Refresh time.Duration
+}
+
+// RateLimit is one provider's token bucket: Rate requests/s, Burst tokens.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitConfig overrides per-provider buckets. Keys are lowercase provider names.
+// Shared draws every grant from the Postgres bucket too (multi-instance deploys).
+type RateLimitConfig struct {
+	Providers map[string]RateLimit
+	Shared    bool
 }
 
 type AppConfig struct {
@@ -46,6 +142,12 @@ type AppConfig struct {
 	HTTP     HTTPConfig
 	Server   ServerConfig
 	Search   SearchConfig
+	Subsonic SubsonicConfig
+	Library  LibraryConfig
+	Limits   RateLimitConfig
+	Stream   StreamConfig
+	Radio    RadioConfig
+	Explore  ExploreConfig
 }
 
 func LoadConfig() *AppConfig {
@@ -54,6 +156,12 @@ func LoadConfig() *AppConfig {
 		HTTP:     loadHTTPConfig(),
 		Server:   loadServerConfig(),
 		Search:   loadSearchConfig(),
+		Subsonic: loadSubsonicConfig(),
+		Library:  loadLibraryConfig(),
+		Limits:   loadRateLimitConfig(),
+		Stream:   loadStreamConfig(),
+		Radio:    loadRadioConfig(),
+		Explore:  loadExploreConfig(),
 	}
 }
 
@@ -93,7 +201,29 @@ func loadHTTPConfig() HTTPConfig {
 		MaxIdlePerHost:  parseIntEnv("HTTP_MAX_IDLE_PER_HOST", constants.DefaultHTTPMaxIdlePerHost),
 		IdleTimeout:     time.Duration(parseIntEnv("HTTP_IDLE_TIMEOUT_SEC", constants.DefaultHTTPIdleTimeout)) * time.Second,
 		ProviderProxies: firstNonEmpty(os.Getenv("PROVIDER_PROXIES"), os.Getenv("MUZJAM_PROXIES")),
+		ProviderPools:   providerPools(os.Environ()),
+		CookieStore:     strings.ToLower(strings.TrimSpace(os.Getenv("SCRAPE_COOKIE_STORE"))),
+		CookieFile:      utils.GetEnvOrDef("SCRAPE_COOKIE_FILE", "data/scrape_cookies.json"),
+		Warmup:          os.Getenv("SCRAPE_WARMUP") != "0",
+		ProxyProbeURL:   utils.GetEnvOrDef("PROXY_PROBE_URL", "https://www.gstatic.com/generate_204"),
+		ProxyProbeEvery: time.Duration(parseIntEnv("PROXY_PROBE_SEC", 120)) * time.Second,
+	}
+}
+
+const providerPoolPrefix = "PROVIDER_PROXIES_"
+
+func providerPools(environ []string) map[string]string {
+	out := make(map[string]string)
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, providerPoolPrefix) || strings.TrimSpace(value) == "" {
+			continue
+		}
+		if name := strings.ToLower(strings.TrimPrefix(key, providerPoolPrefix)); name != "" {
+			out[name] = value
+		}
 	}
+	return out
 }
 
 func firstNonEmpty(values ...string) string {
@@ -105,20 +235,158 @@ func firstNonEmpty(values ...string) string {
 	return ""
 }
 
+func loadStreamConfig() StreamConfig {
+	return StreamConfig{
+		CacheDir:   strings.TrimSpace(os.Getenv("STREAM_CACHE_DIR")),
+		CacheBytes: int64(parseIntEnv("STREAM_CACHE_MB", 1024)) << 20,
+		SigningKey: strings.TrimSpace(os.Getenv("STREAM_SIGNING_KEY")),
+		PreviousKeys: strings.FieldsFunc(os.Getenv("STREAM_SIGNING_PREVIOUS_KEYS"), func(r rune) bool {
+			return r == ',' || r == ' '
+		}),
+		URLTTL:       time.Duration(parseIntEnv("STREAM_URL_TTL_MIN", 720)) * time.Minute,
+		SigningGrace: time.Duration(parseIntEnv("STREAM_SIGNING_GRACE_MIN", 720)) * time.Minute,
+		PublicURL:    strings.TrimSpace(os.Getenv("STREAM_PUBLIC_URL")),
+		ZipMaxTracks: parseIntEnv("ZIP_MAX_TRACKS", 100),
+		QuotaIPBytes: int64(parseIntEnv("STREAM_QUOTA_IP_MB", 0)) << 20,
+		QuotaFlush:   time.Duration(parseIntEnv("STREAM_QUOTA_FLUSH_SEC", 10)) * time.Second,
+	}
+}
+
+func loadRadioConfig() RadioConfig {
+	return RadioConfig{
+		ArtistOwn:     parseIntEnv("ARTIST_RADIO_OWN", 1),
+		ArtistSimilar: parseIntEnv("ARTIST_RADIO_SIMILAR", 2),
+		ArtistMaxRun:  parseIntEnv("ARTIST_RADIO_MAX_RUN", 2),
+
+		CoSaveRebuild:  time.Duration(parseIntEnv("COSAVE_REBUILD_MIN", 360)) * time.Minute,
+		CoSaveMinUsers: parseIntEnv("COSAVE_MIN_USERS", 3),
+
+		DailyMixCheck:     time.Duration(parseIntEnv("DAILY_MIX_CHECK_MIN", 60)) * time.Minute,
+		DailyMixRefreshes: parseIntEnv("DAILY_MIX_REFRESHES", 3),
+	}
+}
+
+func loadExploreConfig() ExploreConfig {
+	cfg := ExploreConfig{
+		Country: utils.GetEnvOrDef("EXPLORE_COUNTRY", "ro"),
+		Refresh: time.Duration(parseIntEnv("EXPLORE_REFRESH_MIN", 15)) * time.Minute,
+	}
+	path := strings.TrimSpace(os.Getenv("EXPLORE_SHELVES_FILE"))
+	if path == "" {
+		return cfg
+	}
+	raw, err := os.ReadFile(path)
+	if err == nil {
+		cfg.Catalog, err = parseExploreCatalog(raw)
+	}
+	if err != nil {
+		utils.GetLogger().Warn("explore shelves not loaded, using built-in", "file", path, "error", err)
+	}
+	return cfg
+}
+
+// parseExploreCatalog reads shelf definitions; a shelf needs an id, a title and a
+// chart source (names are checked when the shelf is built). Bad shelves (and
+// countries left without any) are skipped.
+func parseExploreCatalog(raw []byte) (domain.ExploreCatalog, error) {
+	var in domain.ExploreCatalog
+	if err := json.Unmarshal(raw, &in); err != nil {
+		return domain.ExploreCatalog{}, err
+	}
+	valid := func(shelves []domain.ExploreShelf) []domain.ExploreShelf {
+		var out []domain.ExploreShelf
+		for _, s := range shelves {
+			s.ID, s.Title = strings.TrimSpace(s.ID), strings.TrimSpace(s.Title)
+			s.Source = strings.ToLower(strings.TrimSpace(s.Source))
+			if s.ID == "" || s.Title == "" || s.Source == "" {
+				continue
+			}
+			out = append(out, s)
+		}
+		return out
+	}
+	out := domain.ExploreCatalog{Worldwide: valid(in.Worldwide)}
+	for _, c := range in.Countries {
+		c.Code, c.Name = strings.ToLower(strings.TrimSpace(c.Code)), strings.TrimSpace(c.Name)
+		c.Shelves = valid(c.Shelves)
+		if (c.Code == "" && c.Name == "") || len(c.Shelves) == 0 {
+			continue
+		}
+		out.Countries = append(out.Countries, c)
+	}
+	if len(out.Countries) == 0 && len(out.Worldwide) == 0 {
+		return out, errors.New("no valid shelves")
+	}
+	return out, nil
+}
+
 func loadServerConfig() ServerConfig {
 	return ServerConfig{
 		Port:         utils.GetEnvOrDef("PORT", constants.DefaultServerPort),
 		ReadTimeout:  time.Duration(parseIntEnv("SERVER_READ_TIMEOUT_SEC", constants.DefaultReadTimeout)) * time.Second,
 		WriteTimeout: time.Duration(parseIntEnv("SERVER_WRITE_TIMEOUT_SEC", constants.DefaultWriteTimeout)) * time.Second,
 		IdleTimeout:  time.Duration(parseIntEnv("SERVER_IDLE_TIMEOUT_SEC", constants.DefaultIdleTimeout)) * time.Second,
+		AdminToken:   strings.TrimSpace(os.Getenv("ADMIN_TOKEN")),
 	}
 }
 
 func loadSearchConfig() SearchConfig {
 	return SearchConfig{
-		Timeout:    time.Duration(parseIntEnv("SEARCH_TIMEOUT_SEC", constants.DefaultSearchTimeout)) * time.Second,
-		MaxResults: parseIntEnv("SEARCH_MAX_RESULTS", constants.DefaultMaxSearchResults),
+		Timeout:      time.Duration(parseIntEnv("SEARCH_TIMEOUT_SEC", constants.DefaultSearchTimeout)) * time.Second,
+		MaxResults:   parseIntEnv("SEARCH_MAX_RESULTS", constants.DefaultMaxSearchResults),
+		VerifyLinks:  os.Getenv("SEARCH_VERIFY_LINKS") == "1",
+		VerifyBudget: time.Duration(parseIntEnv("SEARCH_VERIFY_BUDGET_MS", 1500)) * time.Millisecond,
+		VerifyPeek:   parseIntEnv("SEARCH_VERIFY_PEEK", 3),
+	}
+}
+
+func loadSubsonicConfig() SubsonicConfig {
+	return SubsonicConfig{
+		URL:      strings.TrimSpace(os.Getenv("SUBSONIC_URL")),
+		User:     strings.TrimSpace(os.Getenv("SUBSONIC_USER")),
+		Password: os.Getenv("SUBSONIC_PASSWORD"),
+		Priority: parseIntEnv("SUBSONIC_PRIORITY", 0),
+	}
+}
+
+func loadLibraryConfig() LibraryConfig {
+	return LibraryConfig{
+		Dir:       strings.TrimSpace(os.Getenv("LIBRARY_DIR")),
+		PublicURL: strings.TrimSpace(os.Getenv("LIBRARY_PUBLIC_URL")),
+		Rescan:    time.Duration(parseIntEnv("LIBRARY_RESCAN_MIN", 30)) * time.Minute,
+		Priority:  parseIntEnv("LIBRARY_PRIORITY", 0),
+	}
+}
+
+func loadRateLimitConfig() RateLimitConfig {
+	shared := strings.TrimSpace(os.Getenv("PROVIDER_RATE_SHARED"))
+	return RateLimitConfig{
+		Providers: parseRateLimits(os.Getenv("PROVIDER_RATE_LIMITS")),
+		Shared:    shared == "1" || strings.EqualFold(shared, "true"),
+	}
+}
+
+// parseRateLimits reads "mp3pm=8:2,musify=4" (rate[:burst]); bad entries are skipped.
+func parseRateLimits(raw string) map[string]RateLimit {
+	out := map[string]RateLimit{}
+	for _, entry := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		name, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			continue
+		}
+		rateS, burstS, _ := strings.Cut(spec, ":")
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rateS), 64)
+		if err != nil || rate <= 0 {
+			continue
+		}
+		limit := RateLimit{Rate: rate}
+		if burst, err := strconv.Atoi(strings.TrimSpace(burstS)); err == nil && burst > 0 {
+			limit.Burst = burst
+		}
+		out[name] = limit
 	}
+	return out
 }
 
 func parseIntEnv(key string, defaultValue int) int {
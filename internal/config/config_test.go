@@ -36,3 +36,52 @@ func TestDatabaseDSNFromParts(t *testing.T) {
 		}
 	}
 }
+
+func TestParseRateLimits(t *testing.T) {
+	got := parseRateLimits(" Mp3pm=8:3, musify=2.5 ; bad, mp3mn=x:2")
+	if len(got) != 2 {
+		t.Fatalf("got %#v", got)
+	}
+	if got["mp3pm"] != (RateLimit{Rate: 8, Burst: 3}) || got["musify"] != (RateLimit{Rate: 2.5}) {
+		t.Fatalf("got %#v", got)
+	}
+}
+
+func TestProviderPools(t *testing.T) {
+	got := providerPools([]string{
+		"PROVIDER_PROXIES=http://shared:1",
+		"PROVIDER_PROXIES_MUSIFY=socks5h://u:p@m:1080",
+		"PROVIDER_PROXIES_MP3PM=",
+		"PROVIDER_PROXIES_=http://x:1",
+		"PATH=/bin",
+	})
+	if len(got) != 1 || got["musify"] != "socks5h://u:p@m:1080" {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestParseExploreCatalog(t *testing.T) {
+	got, err := parseExploreCatalog([]byte(`{
+		"countries": [
+			{"code": "DE", "name": "Germany", "shelves": [
+				{"id": "germany", "title": "Germany", "source": "lastfm-geo", "params": {"country": "Germany"}},
+				{"id": "spotify-de", "title": "Spotify Germany", "source": " Kworb-Spotify ", "params": {"country": "de"}},
+				{"id": "sourceless", "title": "Nothing"}
+			]},
+			{"code": "fr", "shelves": [{"id": "untitled", "source": "lastfm-global"}]}
+		],
+		"worldwide": [{"id": "worldwide", "title": "Worldwide", "source": "lastfm-global"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Countries) != 1 || got.Countries[0].Code != "de" || len(got.Countries[0].Shelves) != 2 {
+		t.Fatalf("countries %+v", got.Countries)
+	}
+	if got.Countries[0].Shelves[1].Source != "kworb-spotify" || got.Countries[0].Shelves[0].Params["country"] != "Germany" || len(got.Worldwide) != 1 {
+		t.Fatalf("got %+v", got)
+	}
+	if _, err := parseExploreCatalog([]byte(`{"worldwide": [{"id": "x"}]}`)); err == nil {
+		t.Fatal("catalog without a valid shelf must fail")
+	}
+}
@@ -0,0 +1,9 @@
+package domain
+
+// ChartTrack is one chart entry, best first. Duration (seconds) is 0 when the chart
+// doesn't say.
+type ChartTrack struct {
+	Artist   string
+	Title    string
+	Duration int
+}
@@ -0,0 +1,17 @@
+package domain
+
+// CoSave says Users different listeners saved both SongKey and OtherKey; Score is
+// their cosine similarity (Users / √(savers of one × savers of the other)). Artist and
+// Title name the other track.
+type CoSave struct {
+	SongKey  string  `gorm:"column:song_key;primaryKey;type:varchar(1000)" json:"-"`
+	OtherKey string  `gorm:"column:other_key;primaryKey;type:varchar(1000)" json:"songKey"`
+	Artist   string  `gorm:"type:varchar(500);not null" json:"artist"`
+	Title    string  `gorm:"type:varchar(500);not null" json:"title"`
+	Users    int     `gorm:"not null" json:"users"`
+	Score    float64 `gorm:"not null" json:"score"`
+}
+
+func (CoSave) TableName() string {
+	return "song_cosaves"
+}
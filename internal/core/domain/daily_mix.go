@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// DailyMix is one of a user's themed mixes for a UTC day, resolved when it was built.
+// Refreshes counts that day's on-demand rebuilds (the same on every row of the day).
+type DailyMix struct {
+	UserID    string    `gorm:"column:user_uuid;primaryKey;type:varchar(255)" json:"-"`
+	Day       time.Time `gorm:"type:date;primaryKey" json:"day"`
+	Index     int       `gorm:"column:idx;primaryKey" json:"index"`
+	Title     string    `gorm:"type:varchar(255);not null" json:"title"`
+	Subtitle  string    `gorm:"type:varchar(500);not null;default:''" json:"subtitle"`
+	Songs     []Song    `gorm:"type:jsonb;serializer:json;not null" json:"songs"`
+	Refreshes int       `gorm:"not null;default:0" json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (DailyMix) TableName() string {
+	return "daily_mixes"
+}
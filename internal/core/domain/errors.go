@@ -7,4 +7,5 @@ var (
 	ErrAlreadyExists = errors.New("resource already exists")
 	ErrInvalidInput  = errors.New("invalid input")
 	ErrUnavailable   = errors.New("service unavailable")
+	ErrLimitReached  = errors.New("limit reached")
 )
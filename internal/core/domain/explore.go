@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// ExploreShelf defines one Explore chart shelf: the chart source it reads (by name,
+// e.g. "lastfm-geo", "kworb-spotify") and the params that source takes (country, tag…).
+type ExploreShelf struct {
+	ID       string            `json:"id"`
+	Title    string            `json:"title"`
+	Subtitle string            `json:"subtitle"`
+	Source   string            `json:"source"`
+	Params   map[string]string `json:"params,omitempty"`
+}
+
+// ExploreCountry is a country's own shelves; Explore shows them before the worldwide ones.
+// Code (ISO 3166-1 alpha-2) or Name selects it.
+type ExploreCountry struct {
+	Code    string         `json:"code"`
+	Name    string         `json:"name"`
+	Shelves []ExploreShelf `json:"shelves"`
+}
+
+// ExploreCatalog is every Explore shelf the server knows: per-country charts plus the
+// worldwide shelves every country (and any unknown one) gets.
+type ExploreCatalog struct {
+	Countries []ExploreCountry `json:"countries"`
+	Worldwide []ExploreShelf   `json:"worldwide"`
+}
+
+// ExploreSnapshot is the last good build of one shelf, kept across restarts. Key is
+// "<country key or worldwide>|<shelf id>".
+type ExploreSnapshot struct {
+	Key      string    `gorm:"column:shelf_key;primaryKey;type:varchar(255)"`
+	Title    string    `gorm:"type:varchar(255);not null"`
+	Subtitle string    `gorm:"type:varchar(500);not null;default:''"`
+	Songs    []Song    `gorm:"type:jsonb;serializer:json;not null"`
+	BuiltAt  time.Time `gorm:"not null"`
+}
+
+func (ExploreSnapshot) TableName() string {
+	return "explore_shelves"
+}
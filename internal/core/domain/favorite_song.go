@@ -13,6 +13,10 @@ type FavoriteSong struct {
 	UserID    string    `gorm:"column:user_uuid;type:varchar(255);not null;index:,priority:1;index:idx_id_user,priority:2;index:idx_user_order,priority:1" json:"-"`
 	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	StreamURL string    `gorm:"-" json:"streamUrl,omitempty"`
+	// Weights vault radio seeds; bumped by POST /favorites/:songId/play.
+	PlayCount    int        `gorm:"not null;default:0" json:"playCount"`
+	LastPlayedAt *time.Time `json:"lastPlayedAt,omitempty"`
 }
 
 func (FavoriteSong) TableName() string {
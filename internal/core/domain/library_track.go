@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// LibraryTrack is one indexed file under LIBRARY_DIR. Path is relative to the root.
+type LibraryTrack struct {
+	ID          string    `gorm:"primaryKey;type:varchar(64)" json:"id"`
+	Path        string    `gorm:"type:text;not null;uniqueIndex" json:"-"`
+	Artist      string    `gorm:"type:varchar(500);not null" json:"artist"`
+	Title       string    `gorm:"type:varchar(500);not null" json:"title"`
+	Album       string    `gorm:"type:varchar(500)" json:"album,omitempty"`
+	DurationSec int       `gorm:"column:duration_sec;not null;default:0" json:"duration,omitempty"`
+	Size        int64     `gorm:"not null;default:0" json:"-"`
+	ModTime     time.Time `gorm:"column:mod_time" json:"-"`
+	SearchText  string    `gorm:"column:search_text;type:text" json:"-"`
+	ScannedAt   time.Time `gorm:"column:scanned_at" json:"-"`
+}
+
+func (LibraryTrack) TableName() string {
+	return "library_tracks"
+}
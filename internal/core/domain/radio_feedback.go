@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// Radio feedback kinds accepted by POST /recommend/feedback.
+const (
+	FeedbackLike    = "like"
+	FeedbackDislike = "dislike"
+	FeedbackSkip    = "skip"  // an early skip
+	FeedbackClear   = "clear" // forget a like/dislike
+)
+
+// RadioFeedback is a user's standing opinion of one track (by SongKey): Verdict is
+// 1 liked, -1 disliked, 0 neither; Skips counts early skips.
+type RadioFeedback struct {
+	UserID    string    `gorm:"column:user_uuid;primaryKey;type:varchar(255)" json:"-"`
+	SongKey   string    `gorm:"column:song_key;primaryKey;type:varchar(1000)" json:"songKey"`
+	Artist    string    `gorm:"type:varchar(500);not null" json:"artist"`
+	Title     string    `gorm:"type:varchar(500);not null" json:"title"`
+	Verdict   int       `gorm:"not null;default:0" json:"verdict"`
+	Skips     int       `gorm:"not null;default:0" json:"skips"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (RadioFeedback) TableName() string {
+	return "radio_feedback"
+}
@@ -9,6 +9,11 @@ type Song struct {
 	Image    string `json:"image"`
 	Link     string `json:"link"`
 	Provider string `json:"provider,omitempty"`
+	// Duration in seconds, Bitrate in kbps — 0 when the source doesn't say.
+	Duration int `json:"duration,omitempty"`
+	Bitrate  int `json:"bitrate,omitempty"`
+	// StreamURL is a signed /stream link minted per response (never stored).
+	StreamURL string `json:"streamUrl,omitempty"`
 }
 
 func NewSong(title string, artist string, image string, link string) *Song {
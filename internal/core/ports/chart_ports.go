@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+// IChartSource is one public chart (Last.fm, kworb, Apple RSS…). Explore shelves name
+// a source and pass it their params (country, tag…).
+type IChartSource interface {
+	// Chart is up to limit tracks, best first, deduped.
+	Chart(ctx context.Context, params map[string]string, limit int) ([]domain.ChartTrack, error)
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+// ICoSaveRepository holds the "people who saved X also saved Y" table built from
+// every user's favorites.
+type ICoSaveRepository interface {
+	// Saves is each user's newest perUser favorites (UserID, Artist, Title only).
+	Saves(ctx context.Context, perUser int) ([]domain.FavoriteSong, error)
+	// ReplaceCoSaves swaps the whole table for rows in one transaction.
+	ReplaceCoSaves(ctx context.Context, rows []domain.CoSave) error
+	// GetCoSaves is the best perKey rows for each of keys.
+	GetCoSaves(ctx context.Context, keys []string, perKey int) ([]domain.CoSave, error)
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+// IDailyMixRepository stores each user's latest day of daily mixes.
+type IDailyMixRepository interface {
+	// UsersDue is every user with at least minSaves favorites and no mixes for day.
+	UsersDue(ctx context.Context, day time.Time, minSaves int) ([]string, error)
+	// GetMixes is the user's newest stored day, in order (empty when none).
+	GetMixes(ctx context.Context, userId string) ([]domain.DailyMix, error)
+	// SaveMixes replaces everything stored for the user with mixes.
+	SaveMixes(ctx context.Context, userId string, mixes []domain.DailyMix) error
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+// IExploreRepository keeps the last good build of every Explore shelf.
+type IExploreRepository interface {
+	// GetSnapshots is every stored shelf.
+	GetSnapshots(ctx context.Context) ([]domain.ExploreSnapshot, error)
+	// SaveSnapshot inserts or replaces one shelf.
+	SaveSnapshot(ctx context.Context, snap domain.ExploreSnapshot) error
+}
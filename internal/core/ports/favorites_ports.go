@@ -14,6 +14,7 @@ type IFavoritesService interface {
 	UpdateFavoriteImage(ctx context.Context, songId, image string) error
 	UpdateFavoriteLyrics(ctx context.Context, songId, lyrics string) error
 	UpdateFavoriteLink(ctx context.Context, songId, link string) error
+	RecordFavoritePlay(ctx context.Context, songId string) error
 }
 
 type IFavoritesRepository interface {
@@ -24,4 +25,5 @@ type IFavoritesRepository interface {
 	UpdateFavoriteImage(ctx context.Context, songId, image string) error
 	UpdateFavoriteLyrics(ctx context.Context, songId, lyrics string) error
 	UpdateFavoriteLink(ctx context.Context, songId, link string) error
+	RecordFavoritePlay(ctx context.Context, songId string) error
 }
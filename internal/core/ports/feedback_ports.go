@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+// IFeedbackRepository keeps per-user likes, dislikes and early skips for radio.
+type IFeedbackRepository interface {
+	// SetVerdict upserts the user's like (1), dislike (-1) or neither (0) for a track.
+	SetVerdict(ctx context.Context, fb domain.RadioFeedback) error
+	// AddSkip counts one early skip of the track.
+	AddSkip(ctx context.Context, fb domain.RadioFeedback) error
+	// GetFeedback is the user's most recently touched rows, newest first.
+	GetFeedback(ctx context.Context, userId string, limit int) ([]domain.RadioFeedback, error)
+}
@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// IJobLease picks one instance per period for a background job (Postgres-backed).
+type IJobLease interface {
+	// TryLease takes job for ttl unless another holder's lease is still running.
+	TryLease(ctx context.Context, job string, ttl time.Duration) (bool, error)
+}
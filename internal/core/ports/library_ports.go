@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+type ILibraryRepository interface {
+	GetTrack(ctx context.Context, id string) (*domain.LibraryTrack, error)
+	// SearchTracks matches every query token against the normalized search text.
+	SearchTracks(ctx context.Context, query string, limit, offset int) ([]domain.LibraryTrack, error)
+	// TrackStamps maps id → (size, mtime) so re-scans skip unchanged files.
+	TrackStamps(ctx context.Context) (map[string]domain.LibraryTrack, error)
+	UpsertTrack(ctx context.Context, track domain.LibraryTrack) error
+	DeleteTracks(ctx context.Context, ids []string) error
+}
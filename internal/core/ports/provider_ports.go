@@ -11,3 +11,9 @@ type IMusicProvider interface {
 	SearchWithPage(ctx context.Context, query string, page int) ([]domain.ProviderResult, error)
 	Priority() int
 }
+
+// ISharedRateBudget is a token bucket shared by every instance (Postgres-backed).
+type ISharedRateBudget interface {
+	// TryTake refills provider's bucket at rate/s (cap burst) and takes one token; false when empty.
+	TryTake(ctx context.Context, provider string, rate float64, burst int) (bool, error)
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// IStreamUsageStore keeps daily proxied-byte totals per subject ("user:<id>" / "ip:<addr>"),
+// shared by every instance.
+type IStreamUsageStore interface {
+	// AddUsage adds n bytes to subject's total for day (UTC date).
+	AddUsage(ctx context.Context, subject string, day time.Time, n int64) error
+	// Usage is subject's total for day (0 when none yet).
+	Usage(ctx context.Context, subject string, day time.Time) (int64, error)
+}
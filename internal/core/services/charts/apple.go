@@ -0,0 +1,63 @@
+package charts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+// Apple's marketing RSS: most-played songs per storefront, JSON, no key.
+const appleRSSURL = "https://rss.applemarketingtools.com/api/v2/%s/music/most-played/%d/songs.json"
+
+// Feed sizes Apple serves; anything else is a 404.
+var appleRSSSizes = []int{10, 25, 50, 100}
+
+// AppleRSS reads Apple Music's top songs for params["country"] (default us).
+type AppleRSS struct {
+	client *http.Client
+}
+
+func NewAppleRSS(client *http.Client) *AppleRSS {
+	return &AppleRSS{client: client}
+}
+
+func (a *AppleRSS) Chart(ctx context.Context, params map[string]string, limit int) ([]domain.ChartTrack, error) {
+	country, err := countryCode(params, "us")
+	if err != nil {
+		return nil, err
+	}
+	size := appleRSSSizes[len(appleRSSSizes)-1]
+	for _, s := range appleRSSSizes {
+		if s >= limit {
+			size = s
+			break
+		}
+	}
+	body, err := fetch(ctx, a.client, fmt.Sprintf(appleRSSURL, country, size), "application/json")
+	if err != nil {
+		return nil, err
+	}
+	return parseAppleRSS(body, limit)
+}
+
+func parseAppleRSS(body []byte, limit int) ([]domain.ChartTrack, error) {
+	var doc struct {
+		Feed struct {
+			Results []struct {
+				ArtistName string `json:"artistName"`
+				Name       string `json:"name"`
+			} `json:"results"`
+		} `json:"feed"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	out := newCollector(limit)
+	for _, r := range doc.Feed.Results {
+		out.add(domain.ChartTrack{Artist: r.ArtistName, Title: r.Name})
+	}
+	return out.out, nil
+}
@@ -0,0 +1,27 @@
+package charts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseAppleRSS(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "apple_rss_us.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseAppleRSS(body, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Morgan Wallen|Love Somebody", "Lady Gaga & Bruno Mars|Die With A Smile", "Sabrina Carpenter|Espresso"}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i, tr := range got {
+		if tr.Artist+"|"+tr.Title != want[i] {
+			t.Fatalf("%d: got %+v want %s", i, tr, want[i])
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Package charts reads public charts as artist/title lists for Explore shelves.
+package charts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/ports"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+)
+
+// Source names shelves refer to (EXPLORE_SHELVES_FILE "source").
+const (
+	SourceLastFMGeo    = "lastfm-geo"    // params: country (Last.fm name, e.g. "Romania")
+	SourceLastFMTag    = "lastfm-tag"    // params: tag
+	SourceLastFMGlobal = "lastfm-global" // no params
+	SourceKworbYouTube = "kworb-youtube" // params: country (two letters)
+	SourceKworbSpotify = "kworb-spotify" // params: country (two letters; default global)
+	SourceAppleMusic   = "apple-music"   // params: country (two letters; default us)
+)
+
+const maxPage = 2 << 20 // kworb's Spotify pages run ~1 MB
+
+// Defaults is every built-in source by name.
+func Defaults(client *http.Client, lastfmKey string) map[string]ports.IChartSource {
+	return map[string]ports.IChartSource{
+		SourceLastFMGeo:    NewLastFMChart(client, lastfmKey, "geo.getTopTracks", "country"),
+		SourceLastFMTag:    NewLastFMChart(client, lastfmKey, "tag.getTopTracks", "tag"),
+		SourceLastFMGlobal: NewLastFMChart(client, lastfmKey, "chart.getTopTracks", ""),
+		SourceKworbYouTube: NewKworbYouTube(client),
+		SourceKworbSpotify: NewKworbSpotify(client),
+		SourceAppleMusic:   NewAppleRSS(client),
+	}
+}
+
+var countryRe = regexp.MustCompile(`^[a-z]{2}$`)
+
+// countryCode is params["country"] as a two-letter code (def when unset).
+func countryCode(params map[string]string, def string) (string, error) {
+	c := strings.ToLower(strings.TrimSpace(params["country"]))
+	if c == "" {
+		c = def
+	}
+	if !countryRe.MatchString(c) {
+		return "", fmt.Errorf("charts: bad country %q", params["country"])
+	}
+	return c, nil
+}
+
+func fetch(ctx context.Context, client *http.Client, url, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "FindVibe/1.0")
+	req.Header.Set("Accept", accept)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("charts: %s: HTTP %d", req.URL.Host, res.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, maxPage))
+}
+
+// collector keeps the first limit distinct, non-empty tracks.
+type collector struct {
+	limit int
+	seen  map[string]bool
+	out   []domain.ChartTrack
+}
+
+func newCollector(limit int) *collector {
+	return &collector{limit: limit, seen: map[string]bool{}}
+}
+
+func (c *collector) full() bool { return c.limit > 0 && len(c.out) >= c.limit }
+
+func (c *collector) add(t domain.ChartTrack) {
+	t.Artist, t.Title = strings.TrimSpace(t.Artist), strings.TrimSpace(t.Title)
+	k := utils.NormalizeString(t.Artist) + "|" + utils.NormalizeString(t.Title)
+	if t.Artist == "" || t.Title == "" || c.seen[k] || c.full() {
+		return
+	}
+	c.seen[k] = true
+	c.out = append(c.out, t)
+}
@@ -0,0 +1,60 @@
+package charts
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// Sources build the right URL from shelf params and refuse params that would escape it.
+func TestDefaultsURLs(t *testing.T) {
+	var got string
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = r.URL.String()
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}")), Header: http.Header{}}, nil
+	})}
+	sources := Defaults(client, "k")
+	for _, tc := range []struct {
+		source string
+		params map[string]string
+		limit  int
+		want   string
+	}{
+		{SourceLastFMGeo, map[string]string{"country": "Romania"}, 12, "method=geo.getTopTracks"},
+		{SourceLastFMTag, map[string]string{"tag": "hip-hop"}, 12, "tag=hip-hop"},
+		{SourceLastFMGlobal, nil, 12, "method=chart.getTopTracks"},
+		{SourceKworbYouTube, map[string]string{"country": "RO"}, 12, "https://kworb.net/youtube/trending/ro.html"},
+		{SourceKworbSpotify, nil, 12, "https://kworb.net/spotify/country/global_daily.html"},
+		{SourceKworbSpotify, map[string]string{"country": "de"}, 12, "https://kworb.net/spotify/country/de_daily.html"},
+		{SourceAppleMusic, map[string]string{"country": "gb"}, 12, "https://rss.applemarketingtools.com/api/v2/gb/music/most-played/25/songs.json"},
+	} {
+		got = ""
+		if _, err := sources[tc.source].Chart(context.Background(), tc.params, tc.limit); err != nil {
+			t.Fatalf("%s: %v", tc.source, err)
+		}
+		if !strings.Contains(got, tc.want) {
+			t.Fatalf("%s: fetched %q, want %q", tc.source, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		source string
+		params map[string]string
+	}{
+		{SourceKworbYouTube, nil},
+		{SourceKworbYouTube, map[string]string{"country": "../x"}},
+		{SourceLastFMGeo, nil},
+		{SourceAppleMusic, map[string]string{"country": "usa"}},
+	} {
+		got = ""
+		if _, err := sources[tc.source].Chart(context.Background(), tc.params, 10); err == nil || got != "" {
+			t.Fatalf("%s %v: err %v, fetched %q", tc.source, tc.params, err, got)
+		}
+	}
+}
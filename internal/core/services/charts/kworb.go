@@ -0,0 +1,155 @@
+package charts
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+const (
+	// YouTube Music trending per country (public HTML). Closest free stand-in for TikTok.
+	kworbYouTubeURL = "https://kworb.net/youtube/trending/%s.html"
+	// Spotify daily chart per country ("global" for the worldwide one).
+	kworbSpotifyURL = "https://kworb.net/spotify/country/%s_daily.html"
+)
+
+var (
+	kworbAnchorRe = regexp.MustCompile(`(?i)<a[^>]*>([^<]+)</a>`)
+	kworbLinkRe   = regexp.MustCompile(`(?i)<a[^>]*href="([^"]*)"[^>]*>([^<]+)</a>`)
+	kworbCellRe   = regexp.MustCompile(`(?is)<td class="text mp">(.*?)</td>`)
+	parenRe       = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+	spaceRe       = regexp.MustCompile(`\s+`)
+)
+
+// KworbYouTube reads kworb's YouTube trending page for params["country"].
+type KworbYouTube struct {
+	client *http.Client
+}
+
+func NewKworbYouTube(client *http.Client) *KworbYouTube {
+	return &KworbYouTube{client: client}
+}
+
+func (k *KworbYouTube) Chart(ctx context.Context, params map[string]string, limit int) ([]domain.ChartTrack, error) {
+	country, err := countryCode(params, "")
+	if err != nil {
+		return nil, err
+	}
+	body, err := fetch(ctx, k.client, fmt.Sprintf(kworbYouTubeURL, country), "text/html")
+	if err != nil {
+		return nil, err
+	}
+	return parseKworbYouTube(string(body), limit), nil
+}
+
+// parseKworbYouTube reads the music-only table (not overall trending with gaming/vlogs).
+// Rows are video titles, "Artist x Guest - Title | Official Video".
+func parseKworbYouTube(page string, limit int) []domain.ChartTrack {
+	start := strings.Index(page, `class="music"`)
+	if start < 0 {
+		return nil
+	}
+	chunk := page[start:]
+	if i := strings.Index(chunk, "</table>"); i >= 0 {
+		chunk = chunk[:i]
+	}
+	out := newCollector(limit)
+	for _, m := range kworbAnchorRe.FindAllStringSubmatch(chunk, -1) {
+		if out.full() {
+			break
+		}
+		if artist, title, ok := ParseVideoTitle(m[1]); ok {
+			out.add(domain.ChartTrack{Artist: artist, Title: title})
+		}
+	}
+	return out.out
+}
+
+// KworbSpotify reads kworb's Spotify daily chart for params["country"] (default global).
+type KworbSpotify struct {
+	client *http.Client
+}
+
+func NewKworbSpotify(client *http.Client) *KworbSpotify {
+	return &KworbSpotify{client: client}
+}
+
+func (k *KworbSpotify) Chart(ctx context.Context, params map[string]string, limit int) ([]domain.ChartTrack, error) {
+	country := "global"
+	if strings.TrimSpace(params["country"]) != "" && !strings.EqualFold(strings.TrimSpace(params["country"]), "global") {
+		var err error
+		if country, err = countryCode(params, ""); err != nil {
+			return nil, err
+		}
+	}
+	body, err := fetch(ctx, k.client, fmt.Sprintf(kworbSpotifyURL, country), "text/html")
+	if err != nil {
+		return nil, err
+	}
+	return parseKworbSpotify(string(body), limit), nil
+}
+
+// parseKworbSpotify reads the "Artist and Title" cells: the first artist/ link is the
+// lead artist, the track/ link the title ("(w/ Guest)" sits outside it).
+func parseKworbSpotify(page string, limit int) []domain.ChartTrack {
+	out := newCollector(limit)
+	for _, cell := range kworbCellRe.FindAllStringSubmatch(page, -1) {
+		if out.full() {
+			break
+		}
+		var artist, title string
+		for _, a := range kworbLinkRe.FindAllStringSubmatch(cell[1], -1) {
+			switch {
+			case artist == "" && strings.Contains(a[1], "artist/"):
+				artist = html.UnescapeString(a[2])
+			case title == "" && strings.Contains(a[1], "track/"):
+				title = html.UnescapeString(a[2])
+			}
+		}
+		out.add(domain.ChartTrack{Artist: artist, Title: title})
+	}
+	return out.out
+}
+
+// ParseVideoTitle splits a music video title into lead artist and song:
+// "VANILLA x ALEX VELEA - 7 din 7 | Official Video" → ("VANILLA", "7 din 7").
+func ParseVideoTitle(raw string) (artist, title string, ok bool) {
+	raw = strings.TrimSpace(html.UnescapeString(raw))
+	if raw == "" {
+		return "", "", false
+	}
+	if i := strings.Index(raw, "|"); i >= 0 {
+		raw = strings.TrimSpace(raw[:i])
+	}
+	sep := " - "
+	i := strings.Index(raw, sep)
+	if i < 0 {
+		sep = " – "
+		i = strings.Index(raw, sep)
+	}
+	if i <= 0 {
+		return "", "", false
+	}
+	artist = primaryArtist(strings.TrimSpace(raw[:i]))
+	title = strings.TrimSpace(raw[i+len(sep):])
+	title = parenRe.ReplaceAllString(title, " ")
+	title = spaceRe.ReplaceAllString(strings.TrimSpace(title), " ")
+	if artist == "" || title == "" {
+		return "", "", false
+	}
+	return artist, title, true
+}
+
+func primaryArtist(a string) string {
+	for _, sep := range []string{" x ", " ❌", " ✘", " × ", " & ", " feat.", " ft.", " featuring "} {
+		if i := strings.Index(strings.ToLower(a), strings.ToLower(sep)); i > 0 {
+			return strings.TrimSpace(a[:i])
+		}
+	}
+	return strings.TrimSpace(a)
+}
@@ -0,0 +1,97 @@
+package charts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+)
+
+const lastfmAPI = "https://ws.audioscrobbler.com/2.0/"
+
+// LastFMChart is one Last.fm *.getTopTracks method; param names the shelf param it
+// needs ("" = none).
+type LastFMChart struct {
+	client *http.Client
+	apiKey string
+	method string
+	param  string
+}
+
+func NewLastFMChart(client *http.Client, apiKey, method, param string) *LastFMChart {
+	return &LastFMChart{client: client, apiKey: apiKey, method: method, param: param}
+}
+
+func (c *LastFMChart) Chart(ctx context.Context, params map[string]string, limit int) ([]domain.ChartTrack, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("charts: %s: no Last.fm key", c.method)
+	}
+	q := url.Values{
+		"method":  {c.method},
+		"limit":   {strconv.Itoa(limit)},
+		"api_key": {c.apiKey},
+		"format":  {"json"},
+	}
+	if c.param != "" {
+		v := strings.TrimSpace(params[c.param])
+		if v == "" {
+			return nil, fmt.Errorf("charts: %s needs %s", c.method, c.param)
+		}
+		q.Set(c.param, v)
+	}
+	body, err := fetch(ctx, c.client, lastfmAPI+"?"+q.Encode(), "application/json")
+	if err != nil {
+		return nil, err
+	}
+	return parseLastFMTracks(body, limit)
+}
+
+// parseLastFMTracks reads {"tracks": {"track": [...]}} — geo, tag and chart top tracks
+// all answer in that shape (a lone track comes back as an object, not a list).
+func parseLastFMTracks(body []byte, limit int) ([]domain.ChartTrack, error) {
+	var doc struct {
+		Tracks struct {
+			Track json.RawMessage `json:"track"`
+		} `json:"tracks"`
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Error != 0 {
+		return nil, fmt.Errorf("charts: last.fm error %d: %s", doc.Error, doc.Message)
+	}
+	type track struct {
+		Name     string        `json:"name"`
+		Duration utils.FlexInt `json:"duration"`
+		Artist   struct {
+			Name string `json:"name"`
+		} `json:"artist"`
+	}
+	raw := bytes.TrimSpace(doc.Tracks.Track)
+	var tracks []track
+	if len(raw) > 0 && raw[0] == '{' {
+		var one track
+		if err := json.Unmarshal(raw, &one); err != nil {
+			return nil, err
+		}
+		tracks = []track{one}
+	} else if len(raw) > 0 && !bytes.Equal(raw, []byte("null")) {
+		if err := json.Unmarshal(raw, &tracks); err != nil {
+			return nil, err
+		}
+	}
+	out := newCollector(limit)
+	for _, t := range tracks {
+		out.add(domain.ChartTrack{Artist: t.Artist.Name, Title: t.Name, Duration: int(t.Duration)})
+	}
+	return out.out, nil
+}
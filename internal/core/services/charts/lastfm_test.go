@@ -0,0 +1,42 @@
+package charts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLastFMTracks(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "lastfm_geo_romania.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseLastFMTracks(body, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// "Mira - caramela" repeats MIRA's and the nameless row is dropped.
+	want := []string{"Denis Ramniceanu|Vara nu e vara", "MIRA|Caramela", "O-Zone|Dragostea din tei", "Edward Maya|Stereo Love"}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i, tr := range got {
+		if tr.Artist+"|"+tr.Title != want[i] {
+			t.Fatalf("%d: got %+v want %s", i, tr, want[i])
+		}
+	}
+	if got[0].Duration != 178 || got[1].Duration != 0 {
+		t.Fatalf("durations %+v", got)
+	}
+	if got, _ := parseLastFMTracks(body, 2); len(got) != 2 {
+		t.Fatalf("limit: %+v", got)
+	}
+
+	one, err := parseLastFMTracks([]byte(`{"tracks":{"track":{"name":"Solo","artist":{"name":"A"}}}}`), 10)
+	if err != nil || len(one) != 1 || one[0].Title != "Solo" {
+		t.Fatalf("lone track: %+v %v", one, err)
+	}
+	if _, err := parseLastFMTracks([]byte(`{"error":6,"message":"country param invalid"}`), 10); err == nil {
+		t.Fatal("Last.fm error body must fail")
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/ports"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+)
+
+const (
+	coSavePerUser = 200 // newest saves per user that count in a build
+	coSavePerItem = 50  // neighbours kept per track
+	// Below this many shared savers a pair could point at one person's library.
+	coSaveMinUsersFloor = 2
+	coSaveLeaseJob      = "cosave-rebuild"
+)
+
+// CoSaveService builds and reads "people who saved X also saved Y" from everyone's
+// favorites. The table is rebuilt on a timer; reads never touch favorite_songs.
+type CoSaveService struct {
+	repo     ports.ICoSaveRepository
+	minUsers int
+	lease    ports.IJobLease // nil = every tick rebuilds (single instance)
+}
+
+func NewCoSaveService(repo ports.ICoSaveRepository, minUsers int) *CoSaveService {
+	return &CoSaveService{repo: repo, minUsers: max(minUsers, coSaveMinUsersFloor)}
+}
+
+// UseLease makes Run rebuild on one instance per period instead of on every one.
+func (s *CoSaveService) UseLease(lease ports.IJobLease) {
+	s.lease = lease
+}
+
+// Run rebuilds now and then every `every` until ctx ends (every ≤ 0 = never).
+func (s *CoSaveService) Run(ctx context.Context, every time.Duration) {
+	if every <= 0 {
+		return
+	}
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		s.tick(ctx, every)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// jobLeaseTTL is how long a job run every `every` holds its lease: a tenth short of
+// the period, so a tick that fires a little early (timer jitter, another instance's
+// clock) still finds the last lease expired instead of skipping a whole period.
+func jobLeaseTTL(every time.Duration) time.Duration {
+	return every - every/10
+}
+
+// tick rebuilds unless another instance holds this period's lease.
+func (s *CoSaveService) tick(ctx context.Context, every time.Duration) {
+	if s.lease != nil {
+		ok, err := s.lease.TryLease(ctx, coSaveLeaseJob, jobLeaseTTL(every))
+		if err != nil {
+			utils.GetLogger().Warn("co-save lease failed", "error", err)
+			return
+		}
+		if !ok {
+			utils.GetLogger().Debug("co-save rebuild left to another instance")
+			return
+		}
+	}
+	if n, err := s.Rebuild(ctx); err != nil {
+		utils.GetLogger().Warn("co-save rebuild failed", "error", err)
+	} else {
+		utils.GetLogger().Info("co-saves rebuilt", "rows", n)
+	}
+}
+
+// Rebuild recounts every pair and swaps the table; it returns the rows written.
+func (s *CoSaveService) Rebuild(ctx context.Context) (int, error) {
+	saves, err := s.repo.Saves(ctx, coSavePerUser)
+	if err != nil {
+		return 0, err
+	}
+	rows := buildCoSaves(saves, s.minUsers, coSavePerItem)
+	if err := s.repo.ReplaceCoSaves(ctx, rows); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// Related is the best perKey co-saves of each key, grouped by key.
+func (s *CoSaveService) Related(ctx context.Context, keys []string, perKey int) (map[string][]domain.CoSave, error) {
+	rows, err := s.repo.GetCoSaves(ctx, keys, perKey)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string][]domain.CoSave, len(keys))
+	for _, r := range rows {
+		out[r.SongKey] = append(out[r.SongKey], r)
+	}
+	for _, list := range out {
+		slices.SortFunc(list, compareCoSaves)
+	}
+	return out, nil
+}
+
+func compareCoSaves(a, b domain.CoSave) int {
+	return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(b.Users, a.Users), cmp.Compare(a.OtherKey, b.OtherKey))
+}
+
+// buildCoSaves counts, per pair of tracks, how many users saved both (each user's
+// saves deduped by SongKey), drops pairs under minUsers and keeps each track's best
+// perItem neighbours. Tracks are counted one at a time through a saver index, so
+// memory is O(saves) plus one track's neighbour counts — never every pair at once.
+func buildCoSaves(saves []domain.FavoriteSong, minUsers, perItem int) []domain.CoSave {
+	type track struct{ artist, title string }
+	names := map[string]track{}
+	byUser := map[string][]string{}
+	savers := map[string][]string{} // key → users who saved it
+	seen := map[[2]string]bool{}
+	for _, f := range saves {
+		k := SongKey(f.Artist, f.Title)
+		if k == "" || seen[[2]string{f.UserID, k}] {
+			continue
+		}
+		seen[[2]string{f.UserID, k}] = true
+		byUser[f.UserID] = append(byUser[f.UserID], k)
+		savers[k] = append(savers[k], f.UserID)
+		if _, ok := names[k]; !ok {
+			names[k] = track{f.Artist, f.Title}
+		}
+	}
+	seen = nil
+
+	keys := make([]string, 0, len(savers))
+	for k, users := range savers {
+		if len(users) >= minUsers {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var out []domain.CoSave
+	counts := map[string]int{}
+	for _, a := range keys {
+		clear(counts)
+		for _, u := range savers[a] {
+			for _, b := range byUser[u] {
+				if b != a {
+					counts[b]++
+				}
+			}
+		}
+		var list []domain.CoSave
+		for b, users := range counts {
+			if users < minUsers {
+				continue
+			}
+			t := names[b]
+			list = append(list, domain.CoSave{
+				SongKey: a, OtherKey: b, Artist: t.artist, Title: t.title, Users: users,
+				Score: float64(users) / math.Sqrt(float64(len(savers[a]))*float64(len(savers[b]))),
+			})
+		}
+		slices.SortFunc(list, compareCoSaves)
+		out = append(out, list[:min(len(list), perItem)]...)
+	}
+	return out
+}
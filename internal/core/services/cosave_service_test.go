@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+type memCoSaveRepo struct {
+	saves []domain.FavoriteSong
+	rows  []domain.CoSave
+}
+
+func (m *memCoSaveRepo) Saves(context.Context, int) ([]domain.FavoriteSong, error) {
+	return m.saves, nil
+}
+
+func (m *memCoSaveRepo) ReplaceCoSaves(_ context.Context, rows []domain.CoSave) error {
+	m.rows = rows
+	return nil
+}
+
+func (m *memCoSaveRepo) GetCoSaves(_ context.Context, keys []string, perKey int) ([]domain.CoSave, error) {
+	var out []domain.CoSave
+	for _, k := range keys {
+		n := 0
+		for _, r := range m.rows {
+			if r.SongKey == k && n < perKey {
+				out = append(out, r)
+				n++
+			}
+		}
+	}
+	return out, nil
+}
+
+func TestCoSaves(t *testing.T) {
+	save := func(user, artist, title string) domain.FavoriteSong {
+		return domain.FavoriteSong{UserID: user, Artist: artist, Title: title}
+	}
+	repo := &memCoSaveRepo{}
+	for u := range 3 {
+		user := fmt.Sprint("u", u)
+		repo.saves = append(repo.saves,
+			save(user, "Nero", "Promises"),
+			save(user, "Nero", "Promises (Radio Edit)"), // same track, counted once
+			save(user, "Burial", "Archangel"),
+		)
+	}
+	// Only u0 and u1 share Moby — below the three-user bar. u3 saved Nero alone.
+	repo.saves = append(repo.saves,
+		save("u0", "Moby", "Porcelain"), save("u1", "Moby", "Porcelain"),
+		save("u3", "Nero", "Promises"), save("u3", "Skrillex", "Bangarang"),
+	)
+
+	cs := NewCoSaveService(repo, 3)
+	if n, err := cs.Rebuild(context.Background()); err != nil || n != 2 {
+		t.Fatalf("rebuild: %d rows, %v", n, err)
+	}
+	related, err := cs.Related(context.Background(), []string{SongKey("Nero", "Promises")}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := related[SongKey("Nero", "Promises")]
+	if len(got) != 1 || got[0].OtherKey != SongKey("Burial", "Archangel") || got[0].Users != 3 {
+		t.Fatalf("related %+v", got)
+	}
+	// 3 shared savers / √(4 Nero savers × 3 Burial savers)
+	if s := got[0].Score; s < 0.866 || s > 0.867 {
+		t.Fatalf("score %v", s)
+	}
+
+	// The floor keeps single-user pairs (Nero–Skrillex) out whatever is configured.
+	rows := buildCoSaves(repo.saves, NewCoSaveService(repo, 1).minUsers, 10)
+	for _, r := range rows {
+		if r.Users < 2 {
+			t.Fatalf("single-user pair leaked: %+v", r)
+		}
+	}
+	if len(rows) != 6 {
+		t.Fatalf("min 2: %d rows %+v", len(rows), rows)
+	}
+}
+
+type fakeLease struct {
+	grant bool
+	ttl   *time.Duration // last TTL asked for, when set
+}
+
+func (f fakeLease) TryLease(_ context.Context, _ string, ttl time.Duration) (bool, error) {
+	if f.ttl != nil {
+		*f.ttl = ttl
+	}
+	return f.grant, nil
+}
+
+func TestCoSaveTickHonoursLease(t *testing.T) {
+	repo := &memCoSaveRepo{saves: []domain.FavoriteSong{
+		{UserID: "u0", Artist: "Nero", Title: "Promises"}, {UserID: "u0", Artist: "Burial", Title: "Archangel"},
+		{UserID: "u1", Artist: "Nero", Title: "Promises"}, {UserID: "u1", Artist: "Burial", Title: "Archangel"},
+	}}
+	cs := NewCoSaveService(repo, 2)
+	cs.UseLease(fakeLease{grant: false})
+	cs.tick(context.Background(), time.Hour)
+	if repo.rows != nil {
+		t.Fatalf("rebuilt without the lease: %+v", repo.rows)
+	}
+	var ttl time.Duration
+	cs.UseLease(fakeLease{grant: true, ttl: &ttl})
+	cs.tick(context.Background(), time.Hour)
+	if len(repo.rows) != 2 {
+		t.Fatalf("lease holder wrote %d rows, want 2", len(repo.rows))
+	}
+	if ttl <= 0 || ttl >= time.Hour {
+		t.Fatalf("lease TTL %v must end before the next tick", ttl)
+	}
+}
@@ -59,6 +59,28 @@ func HasRealCover(image string) bool {
 
 func hasRealCover(image string) bool { return HasRealCover(image) }
 
+// coverCDNHosts serve the art Lookup returns (Last.fm, Apple); subdomains included.
+var coverCDNHosts = []string{"lastfm.freetls.fastly.net", "mzstatic.com"}
+
+// IsCoverCDN is true for an https cover on a known art CDN — the only images the
+// server fetches itself. Stored favorites can carry any URL and must not be dereferenced.
+func IsCoverCDN(image string) bool {
+	if !HasRealCover(image) {
+		return false
+	}
+	u, err := url.Parse(strings.TrimSpace(image))
+	if err != nil || u.Scheme != "https" || u.User != nil || u.Port() != "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range coverCDNHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
 // Lookup returns artwork for a free-text query: cache → race Last.fm + Apple → first hit.
 func (cs *CoverService) Lookup(ctx context.Context, q string) string {
 	if cs == nil {
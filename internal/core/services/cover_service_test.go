@@ -64,3 +64,19 @@ func TestCoverMissExpiresSooner(t *testing.T) {
 		t.Fatalf("hit should use long TTL, got %v", hitTTL)
 	}
 }
+
+func TestIsCoverCDN(t *testing.T) {
+	for img, want := range map[string]bool{
+		"https://lastfm.freetls.fastly.net/i/u/300x300/abc.png":                  true,
+		"https://is1-ssl.mzstatic.com/image/thumb/Music/600x600bb.jpg":           true,
+		"http://is1-ssl.mzstatic.com/image/a.jpg":                                false,
+		"https://mzstatic.com.evil.example/a.jpg":                                false,
+		"https://is1-ssl.mzstatic.com:8443/a.jpg":                                false,
+		"https://169.254.169.254/latest/meta-data/":                              false,
+		"https://lastfm.freetls.fastly.net/i/u/64s/" + lastfmStubMarker + ".png": false,
+	} {
+		if got := IsCoverCDN(img); got != want {
+			t.Errorf("IsCoverCDN(%q) = %v", img, got)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/ports"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/providers"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	dailyMixMinSaves     = 5 // smaller vaults don't get mixes from the job
+	dailyMixBuildTimeout = 2 * time.Minute
+	dailyMixRetry        = 3 * time.Hour // the job leaves a failed user alone this long
+	dailyMixLeaseJob     = "daily-mix-build"
+)
+
+// DailyMixBuilder builds a user's mixes from scratch (titles, subtitles and resolved
+// songs); the service stamps user, day and order.
+type DailyMixBuilder func(ctx context.Context, userId string) ([]domain.DailyMix, error)
+
+// DailyMixService keeps one day of daily mixes per user: the job builds them ahead
+// of time, Today serves them, Refresh rebuilds on demand a few times a day.
+type DailyMixService struct {
+	repo         ports.IDailyMixRepository
+	build        DailyMixBuilder
+	refreshLimit int
+	now          func() time.Time
+	sf           singleflight.Group
+	lease        ports.IJobLease // nil = every tick builds (single instance)
+
+	mu     sync.Mutex
+	failed map[string]time.Time // user → when the job's last build for them failed
+}
+
+func NewDailyMixService(repo ports.IDailyMixRepository, build DailyMixBuilder, refreshLimit int) *DailyMixService {
+	return &DailyMixService{
+		repo: repo, build: build, refreshLimit: max(refreshLimit, 0), now: time.Now,
+		failed: map[string]time.Time{},
+	}
+}
+
+// UseLease makes Run build on one instance per period instead of on every one.
+func (s *DailyMixService) UseLease(lease ports.IJobLease) {
+	s.lease = lease
+}
+
+func (s *DailyMixService) today() time.Time {
+	return s.now().UTC().Truncate(24 * time.Hour)
+}
+
+// Today is the user's mixes for the current UTC day, built now if the job hasn't got
+// to them yet; when that fails the last stored day is served instead.
+func (s *DailyMixService) Today(ctx context.Context, userId string) ([]domain.DailyMix, error) {
+	day := s.today()
+	stored, err := s.repo.GetMixes(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if len(stored) > 0 && stored[0].Day.Equal(day) {
+		return stored, nil
+	}
+	fresh, err := s.rebuild(ctx, userId, day, 0)
+	if err != nil {
+		if len(stored) > 0 {
+			utils.GetLogger().Debug("daily mix build failed, serving last day", "error", err)
+			return stored, nil
+		}
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// Refresh rebuilds today's mixes, at most refreshLimit times per UTC day. Past that it
+// returns domain.ErrLimitReached and the time left until the day rolls over.
+func (s *DailyMixService) Refresh(ctx context.Context, userId string) ([]domain.DailyMix, time.Duration, error) {
+	day := s.today()
+	stored, err := s.repo.GetMixes(ctx, userId)
+	if err != nil {
+		return nil, 0, err
+	}
+	used := 0
+	if len(stored) > 0 && stored[0].Day.Equal(day) {
+		used = stored[0].Refreshes
+	}
+	if used >= s.refreshLimit {
+		return nil, day.Add(24 * time.Hour).Sub(s.now()), domain.ErrLimitReached
+	}
+	mixes, err := s.rebuild(ctx, userId, day, used+1)
+	return mixes, 0, err
+}
+
+// Run builds the day's mixes for every user still due, checking every `every`
+// (≤ 0 = never). Its provider calls queue behind interactive ones.
+func (s *DailyMixService) Run(ctx context.Context, every time.Duration) {
+	if every <= 0 {
+		return
+	}
+	ctx = providers.WithLane(ctx, providers.LaneBackground)
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		if s.holdsLease(ctx, every) {
+			if n := s.BuildDue(ctx); n > 0 {
+				utils.GetLogger().Info("daily mixes built", "users", n)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// holdsLease reports whether this instance runs the job this period.
+func (s *DailyMixService) holdsLease(ctx context.Context, every time.Duration) bool {
+	if s.lease == nil {
+		return true
+	}
+	ok, err := s.lease.TryLease(ctx, dailyMixLeaseJob, jobLeaseTTL(every))
+	if err != nil {
+		utils.GetLogger().Warn("daily mix lease failed", "error", err)
+		return false
+	}
+	if !ok {
+		utils.GetLogger().Debug("daily mix build left to another instance")
+	}
+	return ok
+}
+
+// BuildDue builds today's mixes for each user without them, one user at a time; it
+// returns how many users got mixes. A user whose build failed is skipped until
+// dailyMixRetry has passed.
+func (s *DailyMixService) BuildDue(ctx context.Context) int {
+	day := s.today()
+	users, err := s.repo.UsersDue(ctx, day, dailyMixMinSaves)
+	if err != nil {
+		utils.GetLogger().Warn("daily mix due users read failed", "error", err)
+		return 0
+	}
+	built := 0
+	for _, u := range users {
+		if ctx.Err() != nil {
+			break
+		}
+		if s.backingOff(u) {
+			continue
+		}
+		if _, err := s.rebuild(ctx, u, day, 0); err != nil {
+			utils.GetLogger().Debug("daily mix build failed", "error", err)
+			s.mu.Lock()
+			s.failed[u] = s.now()
+			s.mu.Unlock()
+			continue
+		}
+		s.mu.Lock()
+		delete(s.failed, u)
+		s.mu.Unlock()
+		built++
+	}
+	return built
+}
+
+// backingOff reports whether u's last job build failed under dailyMixRetry ago;
+// expired entries are dropped so the map only holds recent failures.
+func (s *DailyMixService) backingOff(u string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, ok := s.failed[u]
+	if ok && s.now().Sub(at) >= dailyMixRetry {
+		delete(s.failed, u)
+		return false
+	}
+	return ok
+}
+
+func (s *DailyMixService) rebuild(ctx context.Context, userId string, day time.Time, refreshes int) ([]domain.DailyMix, error) {
+	v, err, _ := s.sf.Do(userId+"|"+strconv.Itoa(refreshes), func() (any, error) {
+		// Don't waste a build (or a refresh) when the first caller goes away.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), dailyMixBuildTimeout)
+		defer cancel()
+		mixes, err := s.build(ctx, userId)
+		if err != nil {
+			return nil, err
+		}
+		if len(mixes) == 0 {
+			return nil, domain.ErrNotFound
+		}
+		now := s.now()
+		for i := range mixes {
+			mixes[i].UserID, mixes[i].Day, mixes[i].Index = userId, day, i
+			mixes[i].Refreshes, mixes[i].CreatedAt = refreshes, now
+		}
+		if err := s.repo.SaveMixes(ctx, userId, mixes); err != nil {
+			return nil, err
+		}
+		return mixes, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	mixes, _ := v.([]domain.DailyMix)
+	return mixes, nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+type memDailyMixRepo struct {
+	mixes  map[string][]domain.DailyMix
+	savers []string
+}
+
+func (m *memDailyMixRepo) UsersDue(_ context.Context, day time.Time, _ int) ([]string, error) {
+	var due []string
+	for _, u := range m.savers {
+		if rows := m.mixes[u]; len(rows) == 0 || !rows[0].Day.Equal(day) {
+			due = append(due, u)
+		}
+	}
+	return due, nil
+}
+
+func (m *memDailyMixRepo) GetMixes(_ context.Context, userId string) ([]domain.DailyMix, error) {
+	return m.mixes[userId], nil
+}
+
+func (m *memDailyMixRepo) SaveMixes(_ context.Context, userId string, mixes []domain.DailyMix) error {
+	m.mixes[userId] = mixes
+	return nil
+}
+
+func TestDailyMixes(t *testing.T) {
+	ctx := context.Background()
+	repo := &memDailyMixRepo{mixes: map[string][]domain.DailyMix{}, savers: []string{"u1", "u2"}}
+	builds := map[string]int{}
+	build := func(_ context.Context, userId string) ([]domain.DailyMix, error) {
+		builds[userId]++
+		if userId == "empty" {
+			return nil, nil
+		}
+		return []domain.DailyMix{{Title: "Daily Mix 1"}, {Title: "Daily Mix 2"}}, nil
+	}
+	now := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	s := NewDailyMixService(repo, build, 2)
+	s.now = func() time.Time { return now }
+
+	if n := s.BuildDue(ctx); n != 2 || builds["u1"] != 1 {
+		t.Fatalf("job built %d users, %v", n, builds)
+	}
+	if n := s.BuildDue(ctx); n != 0 {
+		t.Fatalf("second pass rebuilt %d users", n)
+	}
+	mixes, err := s.Today(ctx, "u1")
+	if err != nil || len(mixes) != 2 || mixes[1].Index != 1 || builds["u1"] != 1 {
+		t.Fatalf("today: %+v %v, builds %v", mixes, err, builds)
+	}
+
+	for range 2 {
+		if _, _, err := s.Refresh(ctx, "u1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, retry, err := s.Refresh(ctx, "u1")
+	if !errors.Is(err, domain.ErrLimitReached) || retry != 2*time.Hour {
+		t.Fatalf("third refresh: %v, retry %v", err, retry)
+	}
+
+	// A new day: the job is due again and refreshes start over.
+	now = now.Add(3 * time.Hour)
+	if _, _, err := s.Refresh(ctx, "u1"); err != nil || builds["u1"] != 4 {
+		t.Fatalf("next day refresh: %v, builds %v", err, builds)
+	}
+	if mixes, _ := s.Today(ctx, "u1"); !mixes[0].Day.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("day %v", mixes[0].Day)
+	}
+
+	if _, err := s.Today(ctx, "empty"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("nothing built: %v", err)
+	}
+}
+
+func TestDailyMixJobBacksOffFailedUsers(t *testing.T) {
+	ctx := context.Background()
+	repo := &memDailyMixRepo{mixes: map[string][]domain.DailyMix{}, savers: []string{"u1"}}
+	builds := 0
+	build := func(context.Context, string) ([]domain.DailyMix, error) {
+		builds++
+		return nil, errors.New("last.fm down")
+	}
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	s := NewDailyMixService(repo, build, 2)
+	s.now = func() time.Time { return now }
+
+	s.BuildDue(ctx)
+	now = now.Add(time.Hour)
+	s.BuildDue(ctx)
+	if builds != 1 {
+		t.Fatalf("failed user rebuilt inside the retry window: %d builds", builds)
+	}
+	now = now.Add(dailyMixRetry)
+	s.BuildDue(ctx)
+	if builds != 2 {
+		t.Fatalf("failed user not retried after the window: %d builds", builds)
+	}
+}
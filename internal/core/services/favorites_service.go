@@ -28,6 +28,7 @@ func (fs *FavoritesService) AddFavorite(ctx context.Context, userId string, song
 		return fmt.Errorf("add favorite: %w", err)
 	}
 	song.UserID = user.ID
+	song.PlayCount, song.LastPlayedAt = 0, nil // only POST …/play counts
 	song.Link = utils.UpgradeHTTPS(song.Link)
 	song.Image = utils.UpgradeHTTPS(song.Image)
 	if song.Link == "" || !strings.HasPrefix(song.Link, "https://") {
@@ -93,6 +94,16 @@ func (fs *FavoritesService) UpdateFavoriteLyrics(ctx context.Context, songId, ly
 	return nil
 }
 
+func (fs *FavoritesService) RecordFavoritePlay(ctx context.Context, songId string) error {
+	if strings.TrimSpace(songId) == "" {
+		return domain.ErrInvalidInput
+	}
+	if err := fs.favoritesRepository.RecordFavoritePlay(ctx, songId); err != nil {
+		return fmt.Errorf("record favorite play: %w", err)
+	}
+	return nil
+}
+
 func (fs *FavoritesService) UpdateFavoriteLink(ctx context.Context, songId, link string) error {
 	link = strings.TrimSpace(link)
 	// FavoriteSong.Link is varchar(1000); https-only matches stream playback.
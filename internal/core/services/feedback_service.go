@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/ports"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+)
+
+const (
+	// Rows read into a profile — older opinions stop steering radio.
+	feedbackProfileRows = 500
+	// An artist at or below this score is left out of radio entirely.
+	FeedbackArtistDrop = -2.0
+	feedbackSkipWeight = 0.5
+)
+
+type FeedbackService struct {
+	feedbackRepository ports.IFeedbackRepository
+	authRepository     ports.IAuthRepository
+}
+
+func NewFeedbackService(feedbackRepository ports.IFeedbackRepository, authRepository ports.IAuthRepository) *FeedbackService {
+	return &FeedbackService{
+		feedbackRepository: feedbackRepository,
+		authRepository:     authRepository,
+	}
+}
+
+// Record stores one like / dislike / early skip / clear of artist – title for userId.
+func (fs *FeedbackService) Record(ctx context.Context, userId, kind, artist, title string) error {
+	artist, title = strings.TrimSpace(artist), strings.TrimSpace(title)
+	key := SongKey(artist, title)
+	if key == "" || len(artist) > 500 || len(title) > 500 {
+		return fmt.Errorf("record feedback: %w", domain.ErrInvalidInput)
+	}
+	if _, err := fs.authRepository.GetUserById(ctx, userId); err != nil {
+		return fmt.Errorf("record feedback: %w", err)
+	}
+	fb := domain.RadioFeedback{UserID: userId, SongKey: key, Artist: artist, Title: title}
+	var err error
+	switch kind {
+	case domain.FeedbackLike:
+		fb.Verdict = 1
+		err = fs.feedbackRepository.SetVerdict(ctx, fb)
+	case domain.FeedbackDislike:
+		fb.Verdict = -1
+		err = fs.feedbackRepository.SetVerdict(ctx, fb)
+	case domain.FeedbackClear:
+		err = fs.feedbackRepository.SetVerdict(ctx, fb)
+	case domain.FeedbackSkip:
+		err = fs.feedbackRepository.AddSkip(ctx, fb)
+	default:
+		return fmt.Errorf("record feedback: %w", domain.ErrInvalidInput)
+	}
+	if err != nil {
+		return fmt.Errorf("record feedback: %w", err)
+	}
+	return nil
+}
+
+// Profile folds userId's recent feedback into what radio needs to steer by.
+func (fs *FeedbackService) Profile(ctx context.Context, userId string) (*FeedbackProfile, error) {
+	rows, err := fs.feedbackRepository.GetFeedback(ctx, userId, feedbackProfileRows)
+	if err != nil {
+		return nil, fmt.Errorf("feedback profile: %w", err)
+	}
+	p := NewFeedbackProfile()
+	// Oldest first so a newer verdict on the same track wins.
+	for i := len(rows) - 1; i >= 0; i-- {
+		r := rows[i]
+		switch {
+		case r.Verdict > 0:
+			p.Note(domain.FeedbackLike, r.Artist, r.Title)
+		case r.Verdict < 0:
+			p.Note(domain.FeedbackDislike, r.Artist, r.Title)
+		}
+		for range r.Skips {
+			p.Note(domain.FeedbackSkip, r.Artist, r.Title)
+		}
+	}
+	return p, nil
+}
+
+// FeedbackProfile is likes/dislikes/skips folded per artist and track. Not safe for
+// concurrent writes; a nil profile steers nothing.
+type FeedbackProfile struct {
+	artists  map[string]float64 // normalized artist → likes − dislikes − skips/2
+	liked    map[string]bool    // by SongKey
+	disliked map[string]bool
+	skipped  map[string]bool
+	// Liked tracks, newest last; new radio sessions boost their neighborhoods.
+	Liked []domain.RadioFeedback
+}
+
+func NewFeedbackProfile() *FeedbackProfile {
+	return &FeedbackProfile{
+		artists:  map[string]float64{},
+		liked:    map[string]bool{},
+		disliked: map[string]bool{},
+		skipped:  map[string]bool{},
+	}
+}
+
+// Note applies one piece of feedback.
+func (p *FeedbackProfile) Note(kind, artist, title string) {
+	key, a := SongKey(artist, title), utils.NormalizeString(artist)
+	if key == "" {
+		return
+	}
+	switch kind {
+	case domain.FeedbackLike:
+		p.clearVerdict(key, a)
+		p.artists[a]++
+		p.liked[key] = true
+		p.Liked = append(p.Liked, domain.RadioFeedback{SongKey: key, Artist: artist, Title: title, Verdict: 1})
+	case domain.FeedbackDislike:
+		p.clearVerdict(key, a)
+		p.artists[a]--
+		p.disliked[key] = true
+	case domain.FeedbackSkip:
+		p.artists[a] -= feedbackSkipWeight
+		p.skipped[key] = true
+	case domain.FeedbackClear:
+		p.clearVerdict(key, a)
+	}
+}
+
+// clearVerdict undoes an earlier like/dislike of key so verdicts don't stack.
+func (p *FeedbackProfile) clearVerdict(key, artist string) {
+	if p.liked[key] {
+		delete(p.liked, key)
+		p.artists[artist]--
+		for i, l := range p.Liked {
+			if l.SongKey == key {
+				p.Liked = append(p.Liked[:i], p.Liked[i+1:]...)
+				break
+			}
+		}
+	} else if p.disliked[key] {
+		delete(p.disliked, key)
+		p.artists[artist]++
+	}
+}
+
+// ArtistScore is > 0 for artists the user likes, < 0 for ones they don't.
+func (p *FeedbackProfile) ArtistScore(artist string) float64 {
+	if p == nil {
+		return 0
+	}
+	return p.artists[utils.NormalizeString(artist)]
+}
+
+// Dropped: the track was disliked or skipped early, or its artist scored too low.
+func (p *FeedbackProfile) Dropped(artist, title string) bool {
+	if p == nil {
+		return false
+	}
+	key := SongKey(artist, title)
+	return p.disliked[key] || p.skipped[key] || p.ArtistScore(artist) <= FeedbackArtistDrop
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+// memFeedbackRepo mimics the upserts: one row per user+track, newest first on read.
+type memFeedbackRepo struct {
+	rows []domain.RadioFeedback
+	tick time.Time
+}
+
+func (m *memFeedbackRepo) upsert(fb domain.RadioFeedback, apply func(*domain.RadioFeedback)) {
+	m.tick = m.tick.Add(time.Second)
+	i := slices.IndexFunc(m.rows, func(r domain.RadioFeedback) bool { return r.UserID == fb.UserID && r.SongKey == fb.SongKey })
+	if i < 0 {
+		m.rows = append(m.rows, fb)
+		i = len(m.rows) - 1
+	}
+	apply(&m.rows[i])
+	m.rows[i].UpdatedAt = m.tick
+}
+
+func (m *memFeedbackRepo) SetVerdict(_ context.Context, fb domain.RadioFeedback) error {
+	m.upsert(fb, func(r *domain.RadioFeedback) { r.Verdict = fb.Verdict })
+	return nil
+}
+
+func (m *memFeedbackRepo) AddSkip(_ context.Context, fb domain.RadioFeedback) error {
+	m.upsert(fb, func(r *domain.RadioFeedback) { r.Skips++ })
+	return nil
+}
+
+func (m *memFeedbackRepo) GetFeedback(_ context.Context, userId string, limit int) ([]domain.RadioFeedback, error) {
+	var out []domain.RadioFeedback
+	for _, r := range m.rows {
+		if r.UserID == userId {
+			out = append(out, r)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.RadioFeedback) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+	return out[:min(limit, len(out))], nil
+}
+
+type knownUsers map[string]bool
+
+func (k knownUsers) AuthenticateUser(context.Context, string) (*domain.User, error) {
+	return nil, errors.New("unused")
+}
+
+func (k knownUsers) GetUserById(_ context.Context, id string) (*domain.User, error) {
+	if !k[id] {
+		return nil, domain.ErrNotFound
+	}
+	return &domain.User{ID: id}, nil
+}
+
+func TestFeedbackProfile(t *testing.T) {
+	ctx := context.Background()
+	fs := NewFeedbackService(&memFeedbackRepo{}, knownUsers{"u1": true})
+
+	if err := fs.Record(ctx, "u1", "love", "Nero", "Promises"); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("bad kind: %v", err)
+	}
+	if err := fs.Record(ctx, "ghost", domain.FeedbackLike, "Nero", "Promises"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("unknown user: %v", err)
+	}
+	for _, r := range []struct{ kind, artist, title string }{
+		{domain.FeedbackLike, "Nero", "Promises"},
+		{domain.FeedbackLike, "Nero", "Guilt"},
+		{domain.FeedbackDislike, "Nero", "Guilt"}, // newer verdict replaces the like
+		{domain.FeedbackSkip, "Burial", "Archangel"},
+		{domain.FeedbackDislike, "Skrillex", "Bangarang"},
+		{domain.FeedbackDislike, "Skrillex", "Scary Monsters"},
+		{domain.FeedbackDislike, "Moby", "Porcelain"},
+		{domain.FeedbackClear, "Moby", "Porcelain"},
+	} {
+		if err := fs.Record(ctx, "u1", r.kind, r.artist, r.title); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, err := fs.Profile(ctx, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Liked) != 1 || p.Liked[0].Title != "Promises" {
+		t.Fatalf("liked %+v", p.Liked)
+	}
+	if got := p.ArtistScore("nero"); got != 0 {
+		t.Fatalf("nero score %v, want like and dislike to cancel", got)
+	}
+	for _, c := range []struct {
+		artist, title string
+		dropped       bool
+	}{
+		{"Nero", "Promises", false},
+		{"Nero", "Guilt", true},
+		{"Burial", "Archangel", true},
+		{"Burial", "Near Dark", false},
+		{"Skrillex", "Kill Everybody", true}, // two dislikes drop the artist
+		{"Moby", "Porcelain", false},
+	} {
+		if got := p.Dropped(c.artist, c.title); got != c.dropped {
+			t.Errorf("Dropped(%s, %s) = %v", c.artist, c.title, got)
+		}
+	}
+	if (*FeedbackProfile)(nil).Dropped("Nero", "Guilt") {
+		t.Fatal("nil profile must not drop")
+	}
+}
@@ -0,0 +1,184 @@
+// Package id3 swaps an MP3's tags while it streams: leading ID3v2 tags and a trailing
+// ID3v1 tag are dropped and one fresh ID3v2.3 tag is written in front.
+package id3
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+)
+
+const (
+	headerSize = 10
+	v1Size     = 128 // "TAG" + fixed fields at the very end
+	maxSkip    = 16 << 20
+)
+
+// Tags is what the new tag carries; empty fields are left out.
+type Tags struct {
+	Title, Artist, Album string
+	Lyrics               string
+	LyricsLang           string // ISO-639-2, "eng" when empty
+	Cover                []byte
+	CoverMIME            string // "image/jpeg" when empty
+}
+
+// Encode renders t as an ID3v2.3 tag (UTF-16 text so every player reads it).
+func (t Tags) Encode() []byte {
+	var frames bytes.Buffer
+	for _, f := range []struct{ id, v string }{{"TIT2", t.Title}, {"TPE1", t.Artist}, {"TALB", t.Album}} {
+		if f.v != "" {
+			writeFrame(&frames, f.id, append([]byte{1}, utf16Text(f.v)...))
+		}
+	}
+	if t.Lyrics != "" {
+		lang := t.LyricsLang
+		if len(lang) != 3 {
+			lang = "eng"
+		}
+		body := append([]byte{1}, lang...)
+		body = append(body, utf16Text("")...)
+		body = append(body, 0, 0) // empty content descriptor
+		body = append(body, utf16Text(t.Lyrics)...)
+		writeFrame(&frames, "USLT", body)
+	}
+	if len(t.Cover) > 0 {
+		mimeType := t.CoverMIME
+		if mimeType == "" {
+			mimeType = "image/jpeg"
+		}
+		body := append([]byte{0}, mimeType...)
+		body = append(body, 0, 3, 0) // MIME terminator, front cover, empty description
+		body = append(body, t.Cover...)
+		writeFrame(&frames, "APIC", body)
+	}
+
+	out := make([]byte, headerSize, headerSize+frames.Len())
+	copy(out, "ID3\x03\x00\x00")
+	putSynchsafe(out[6:], frames.Len())
+	return append(out, frames.Bytes()...)
+}
+
+func writeFrame(buf *bytes.Buffer, id string, body []byte) {
+	var hdr [headerSize]byte
+	copy(hdr[:], id)
+	binary.BigEndian.PutUint32(hdr[4:], uint32(len(body))) // v2.3 frame sizes are plain
+	buf.Write(hdr[:])
+	buf.Write(body)
+}
+
+// utf16Text is BOM + UTF-16LE, no terminator.
+func utf16Text(s string) []byte {
+	out := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = append(out, byte(u), byte(u>>8))
+	}
+	return out
+}
+
+func putSynchsafe(b []byte, n int) {
+	b[0], b[1], b[2], b[3] = byte(n>>21&0x7F), byte(n>>14&0x7F), byte(n>>7&0x7F), byte(n&0x7F)
+}
+
+// TagSize is the full length of the ID3v2 tag hdr starts (footer included), 0 when hdr
+// isn't one.
+func TagSize(hdr []byte) int {
+	if len(hdr) < headerSize || string(hdr[:3]) != "ID3" || hdr[3] == 0xFF || hdr[4] == 0xFF {
+		return 0
+	}
+	n := 0
+	for _, b := range hdr[6:10] {
+		if b&0x80 != 0 {
+			return 0
+		}
+		n = n<<7 | int(b)
+	}
+	n += headerSize
+	if hdr[5]&0x10 != 0 { // v2.4 footer
+		n += headerSize
+	}
+	return n
+}
+
+// NewRewriter returns src with its tags replaced by t.Encode(). Only the last 128 bytes
+// are held back (to spot an ID3v1 tag), so output starts before src is fully read.
+func NewRewriter(src io.Reader, t Tags) io.Reader {
+	return &rewriter{src: bufio.NewReaderSize(src, 32<<10), out: t.Encode()}
+}
+
+type rewriter struct {
+	src     *bufio.Reader
+	started bool
+	eof     bool
+	out     []byte // ready to hand out
+	hold    []byte // possible ID3v1 tail
+	buf     []byte
+}
+
+func (r *rewriter) Read(p []byte) (int, error) {
+	if !r.started {
+		r.started = true
+		if err := r.skipTags(); err != nil {
+			return 0, err
+		}
+	}
+	for len(r.out) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// skipTags drops every ID3v2 tag at the head (some rips carry two).
+func (r *rewriter) skipTags() error {
+	skipped := 0
+	for {
+		hdr, err := r.src.Peek(headerSize)
+		if err == io.EOF {
+			return nil // shorter than a header
+		}
+		if err != nil {
+			return err
+		}
+		n := TagSize(hdr)
+		if n == 0 || skipped+n > maxSkip {
+			return nil
+		}
+		if _, err := r.src.Discard(n); err != nil {
+			return err
+		}
+		skipped += n
+	}
+}
+
+func (r *rewriter) fill() error {
+	if r.buf == nil {
+		r.buf = make([]byte, 32<<10)
+	}
+	n, err := r.src.Read(r.buf)
+	r.hold = append(r.hold, r.buf[:n]...)
+	switch {
+	case err == io.EOF:
+		r.eof = true
+		if len(r.hold) >= v1Size && string(r.hold[len(r.hold)-v1Size:len(r.hold)-v1Size+3]) == "TAG" {
+			r.hold = r.hold[:len(r.hold)-v1Size]
+		}
+		r.out, r.hold = r.hold, nil
+		return nil
+	case err != nil:
+		return err
+	}
+	if cut := len(r.hold) - v1Size; cut > 0 {
+		r.out = append(make([]byte, 0, cut), r.hold[:cut]...)
+		r.hold = append(r.hold[:0], r.hold[cut:]...)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package id3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+)
+
+// frames parses the ID3v2.3 tag at the head of b; rest is what follows it.
+func frames(t *testing.T, b []byte) (map[string][]byte, []byte) {
+	t.Helper()
+	n := TagSize(b)
+	if n == 0 || b[3] != 3 {
+		t.Fatalf("no v2.3 tag at head: % x", b[:min(len(b), 10)])
+	}
+	out := map[string][]byte{}
+	for body := b[headerSize:n]; len(body) >= headerSize && body[0] != 0; {
+		size := int(binary.BigEndian.Uint32(body[4:8]))
+		out[string(body[:4])] = body[headerSize : headerSize+size]
+		body = body[headerSize+size:]
+	}
+	return out, b[n:]
+}
+
+func decodeUTF16(t *testing.T, b []byte) string {
+	t.Helper()
+	if len(b) < 2 || b[0] != 0xFF || b[1] != 0xFE {
+		t.Fatalf("missing BOM: % x", b)
+	}
+	u := make([]uint16, 0, len(b)/2)
+	for i := 2; i+1 < len(b); i += 2 {
+		u = append(u, uint16(b[i])|uint16(b[i+1])<<8)
+	}
+	return string(utf16.Decode(u))
+}
+
+func TestRewriteFixtures(t *testing.T) {
+	audio, err := os.ReadFile(filepath.Join("testdata", "audio.raw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cover := []byte{0xFF, 0xD8, 0xFF, 0xE0, 'j', 'p', 'g'}
+	tags := Tags{Title: "Promises", Artist: "Nero & Ünïcode", Lyrics: "line one\nline two", Cover: cover}
+
+	for _, name := range []string{"junk_v23.mp3", "two_tags.mp3", "bare.mp3"} {
+		src, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// One byte at a time: tag detection must not depend on read sizes.
+		got, err := io.ReadAll(NewRewriter(iotest.OneByteReader(bytes.NewReader(src)), tags))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		fr, rest := frames(t, got)
+		if !bytes.Equal(rest, audio) {
+			t.Fatalf("%s: audio changed (%d bytes, want %d)", name, len(rest), len(audio))
+		}
+		if v := decodeUTF16(t, fr["TIT2"][1:]); v != "Promises" {
+			t.Fatalf("%s: title %q", name, v)
+		}
+		if v := decodeUTF16(t, fr["TPE1"][1:]); v != tags.Artist {
+			t.Fatalf("%s: artist %q", name, v)
+		}
+		if _, ok := fr["COMM"]; ok {
+			t.Fatalf("%s: old frames kept", name)
+		}
+		if _, ok := fr["TALB"]; ok {
+			t.Fatalf("%s: empty album written", name)
+		}
+		uslt := fr["USLT"]
+		if string(uslt[1:4]) != "eng" || decodeUTF16(t, uslt[8:]) != tags.Lyrics {
+			t.Fatalf("%s: USLT % x", name, uslt)
+		}
+		if apic := fr["APIC"]; !bytes.HasPrefix(apic, []byte("\x00image/jpeg\x00\x03\x00")) || !bytes.HasSuffix(apic, cover) {
+			t.Fatalf("%s: APIC % x", name, apic)
+		}
+	}
+}
+
+func TestTagSize(t *testing.T) {
+	if n := TagSize([]byte("ID3\x04\x00\x10\x00\x00\x01\x00")); n != 128+20 {
+		t.Fatalf("footer tag: %d", n)
+	}
+	if n := TagSize([]byte("ID3\x03\x00\x00\x00\x00\x80\x00")); n != 0 {
+		t.Fatalf("bad synchsafe accepted: %d", n)
+	}
+	if n := TagSize([]byte{0xFF, 0xFB, 0x90, 0x64, 0, 0, 0, 0, 0, 0}); n != 0 {
+		t.Fatalf("mpeg frame as tag: %d", n)
+	}
+}
@@ -26,6 +26,8 @@ type CatalogHit struct {
 	Artist string
 	Title  string
 	Image  string
+	// Duration in seconds when Last.fm listed it (0 = unknown).
+	Duration int
 }
 
 // CatalogArtist is a Last.fm artist.search match for search UI.
@@ -45,8 +47,13 @@ type CatalogPage struct {
 type LastFMCatalog struct {
 	client *http.Client
 	apiKey string
+
+	durMu    sync.Mutex
+	durCache map[string]int
 }
 
+const trackDurationCacheCap = 2048
+
 func NewLastFMCatalog(client *http.Client, apiKey string) *LastFMCatalog {
 	return &LastFMCatalog{client: client, apiKey: strings.TrimSpace(apiKey)}
 }
@@ -418,14 +425,61 @@ func (l *LastFMCatalog) artistTopTracks(ctx context.Context, artist string, limi
 			continue
 		}
 		out = append(out, CatalogHit{
-			Artist: a,
-			Title:  title,
-			Image:  utils.UpgradeHTTPS(bestSearchImage(t.Image)),
+			Artist:   a,
+			Title:    title,
+			Image:    utils.UpgradeHTTPS(bestSearchImage(t.Image)),
+			Duration: int(t.Duration),
 		})
 	}
 	return out, nil
 }
 
+// TrackDuration is track.getInfo's duration in seconds (0 = unknown). Cached per SongKey,
+// misses included — the lookup only runs when provider candidates disagree on length.
+func (l *LastFMCatalog) TrackDuration(ctx context.Context, artist, title string) int {
+	if !l.Configured() {
+		return 0
+	}
+	key := SongKey(artist, title)
+	if key == "" {
+		return 0
+	}
+	l.durMu.Lock()
+	if d, ok := l.durCache[key]; ok {
+		l.durMu.Unlock()
+		return d
+	}
+	l.durMu.Unlock()
+
+	q := url.Values{
+		"method":      {"track.getInfo"},
+		"artist":      {artist},
+		"track":       {title},
+		"autocorrect": {"1"},
+		"api_key":     {l.apiKey},
+		"format":      {"json"},
+	}
+	raw, err := l.getJSON(ctx, q)
+	if err != nil {
+		return 0 // transient — don't cache
+	}
+	var payload struct {
+		Track struct {
+			Duration utils.FlexInt `json:"duration"` // milliseconds
+		} `json:"track"`
+	}
+	_ = json.Unmarshal(raw, &payload)
+	d := int(payload.Track.Duration) / 1000
+
+	l.durMu.Lock()
+	if l.durCache == nil || len(l.durCache) >= trackDurationCacheCap {
+		l.durCache = make(map[string]int)
+	}
+	l.durCache[key] = d
+	l.durMu.Unlock()
+	return d
+}
+
 func (l *LastFMCatalog) getJSON(ctx context.Context, q url.Values) (json.RawMessage, error) {
 	u := "https://ws.audioscrobbler.com/2.0/?" + q.Encode()
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
@@ -477,8 +531,9 @@ type catalogAlbumRow struct {
 }
 
 type lastfmTrackRow struct {
-	Name   string `json:"name"`
-	Artist struct {
+	Name     string        `json:"name"`
+	Duration utils.FlexInt `json:"duration"` // seconds on top-track lists
+	Artist   struct {
 		Name string `json:"name"`
 	} `json:"artist"`
 	Image []struct {
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+)
+
+// Refresh with a real key: LASTFM_API_KEY=… make record-fixtures
+func TestReplayLastFMCatalogSearch(t *testing.T) {
+	mode := utils.RecordReplay
+	key := "replay"
+	if os.Getenv("RECORD") == "1" {
+		mode = utils.RecordRecord
+		key = os.Getenv("LASTFM_API_KEY")
+		if key == "" {
+			t.Skip("RECORD=1 needs LASTFM_API_KEY")
+		}
+	}
+	client := utils.NewHTTPRecorder(mode, filepath.Join("testdata", "replay"), nil).
+		Wrap(&http.Client{Timeout: 20 * time.Second})
+
+	page, err := NewLastFMCatalog(client, key).Search(context.Background(), "adele", 1, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Hits) == 0 || len(page.Artists) == 0 {
+		t.Fatalf("hits=%d artists=%d", len(page.Hits), len(page.Artists))
+	}
+	for _, h := range page.Hits {
+		if h.Artist == "" || h.Title == "" {
+			t.Fatalf("empty hit: %#v", h)
+		}
+	}
+	if page.Pagination == nil || page.Pagination.TotalPages < 1 {
+		t.Fatalf("pagination: %#v", page.Pagination)
+	}
+}
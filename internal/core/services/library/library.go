@@ -0,0 +1,200 @@
+package library
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/ports"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+)
+
+// Library indexes audio files under one root and resolves ids back to paths
+// that are guaranteed to stay inside that root.
+type Library struct {
+	root     string
+	repo     ports.ILibraryRepository
+	readTags func(path string) (Tags, error)
+}
+
+type ScanStats struct {
+	Seen, Indexed, Unchanged, Failed, Removed int
+}
+
+// New resolves root (symlinks included) once; every served path is checked against it.
+func New(root string, repo ports.ILibraryRepository) (*Library, error) {
+	abs, err := filepath.Abs(strings.TrimSpace(root))
+	if err != nil {
+		return nil, fmt.Errorf("library: root: %w", err)
+	}
+	abs, err = filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, fmt.Errorf("library: root: %w", err)
+	}
+	st, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("library: root: %w", err)
+	}
+	if !st.IsDir() {
+		return nil, fmt.Errorf("library: root %s is not a directory", abs)
+	}
+	return &Library{root: abs, repo: repo, readTags: ReadTags}, nil
+}
+
+func (l *Library) Root() string { return l.root }
+
+// TrackID is stable per relative path so links survive re-scans.
+func TrackID(rel string) string {
+	sum := sha1.Sum([]byte(filepath.ToSlash(rel)))
+	return hex.EncodeToString(sum[:])[:20]
+}
+
+// SearchText is what SearchTracks matches query tokens against.
+func SearchText(artist, title, album string) string {
+	return utils.NormalizeString(artist + " " + title + " " + album)
+}
+
+// Run scans immediately, then every interval until ctx ends. interval <= 0 scans once.
+func (l *Library) Run(ctx context.Context, interval time.Duration) {
+	log := utils.GetLogger()
+	for {
+		start := time.Now()
+		stats, err := l.Scan(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Warn("library scan failed", "root", l.root, "error", err)
+		} else if err == nil {
+			log.Info("library scan done", "root", l.root, "seen", stats.Seen, "indexed", stats.Indexed,
+				"unchanged", stats.Unchanged, "failed", stats.Failed, "removed", stats.Removed,
+				"took", time.Since(start).Round(time.Millisecond))
+		}
+		if interval <= 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Scan walks the root, re-reads files whose size or mtime changed and drops rows for vanished files.
+// Symlinks are not followed — a link out of the root must never become streamable.
+func (l *Library) Scan(ctx context.Context) (ScanStats, error) {
+	var stats ScanStats
+	known, err := l.repo.TrackStamps(ctx)
+	if err != nil {
+		return stats, err
+	}
+	seen := make(map[string]bool, len(known))
+
+	walkErr := filepath.WalkDir(l.root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if d != nil && d.IsDir() && path != l.root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != l.root && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !Supported(path) {
+			return nil
+		}
+		rel, err := filepath.Rel(l.root, path)
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		stats.Seen++
+		id := TrackID(rel)
+		seen[id] = true
+		if k, ok := known[id]; ok && k.Size == info.Size() && k.ModTime.Equal(info.ModTime()) {
+			stats.Unchanged++
+			return nil
+		}
+
+		tags, err := l.readTags(path)
+		if err != nil || tags.Title == "" {
+			stats.Failed++
+			return nil
+		}
+		track := domain.LibraryTrack{
+			ID:          id,
+			Path:        filepath.ToSlash(rel),
+			Artist:      tags.Artist,
+			Title:       tags.Title,
+			Album:       tags.Album,
+			DurationSec: int(tags.Duration.Round(time.Second) / time.Second),
+			Size:        info.Size(),
+			ModTime:     info.ModTime(),
+			SearchText:  SearchText(tags.Artist, tags.Title, tags.Album),
+			ScannedAt:   time.Now(),
+		}
+		if err := l.repo.UpsertTrack(ctx, track); err != nil {
+			return err
+		}
+		stats.Indexed++
+		return nil
+	})
+	if walkErr != nil {
+		return stats, walkErr
+	}
+
+	var gone []string
+	for id := range known {
+		if !seen[id] {
+			gone = append(gone, id)
+		}
+	}
+	if err := l.repo.DeleteTracks(ctx, gone); err != nil {
+		return stats, err
+	}
+	stats.Removed = len(gone)
+	return stats, nil
+}
+
+// FilePath maps a track id to an absolute path inside the root, or domain.ErrNotFound.
+func (l *Library) FilePath(ctx context.Context, id string) (string, error) {
+	t, err := l.repo.GetTrack(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return l.resolve(t.Path)
+}
+
+func (l *Library) resolve(rel string) (string, error) {
+	rel = filepath.FromSlash(rel)
+	if rel == "" || filepath.IsAbs(rel) {
+		return "", domain.ErrNotFound
+	}
+	full, err := filepath.EvalSymlinks(filepath.Join(l.root, rel))
+	if err != nil {
+		return "", domain.ErrNotFound
+	}
+	inside, err := filepath.Rel(l.root, full)
+	if err != nil || inside == ".." || strings.HasPrefix(inside, ".."+string(filepath.Separator)) {
+		return "", domain.ErrNotFound
+	}
+	st, err := os.Stat(full)
+	if err != nil || !st.Mode().IsRegular() {
+		return "", domain.ErrNotFound
+	}
+	return full, nil
+}
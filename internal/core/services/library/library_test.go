@@ -0,0 +1,126 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+type memRepo struct {
+	tracks  map[string]domain.LibraryTrack
+	upserts int
+}
+
+func (m *memRepo) GetTrack(_ context.Context, id string) (*domain.LibraryTrack, error) {
+	t, ok := m.tracks[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &t, nil
+}
+
+func (m *memRepo) SearchTracks(_ context.Context, query string, limit, offset int) ([]domain.LibraryTrack, error) {
+	var out []domain.LibraryTrack
+	for _, t := range m.tracks {
+		if strings.Contains(t.SearchText, query) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (m *memRepo) TrackStamps(context.Context) (map[string]domain.LibraryTrack, error) {
+	out := make(map[string]domain.LibraryTrack, len(m.tracks))
+	for k, v := range m.tracks {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (m *memRepo) UpsertTrack(_ context.Context, t domain.LibraryTrack) error {
+	m.upserts++
+	m.tracks[t.ID] = t
+	return nil
+}
+
+func (m *memRepo) DeleteTracks(_ context.Context, ids []string) error {
+	for _, id := range ids {
+		delete(m.tracks, id)
+	}
+	return nil
+}
+
+func TestScanIndexesSkipsUnchangedAndPrunes(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "Adele/hello.mp3", mp3Bytes("Adele", "Hello"))
+	gone := writeFile(t, root, "Daft Punk - Aerodynamic.mp3", cbrFrames(10))
+	writeFile(t, root, "cover.jpg", []byte("jpg"))
+	writeFile(t, root, ".trash/old.mp3", mp3Bytes("X", "Y"))
+
+	repo := &memRepo{tracks: map[string]domain.LibraryTrack{}}
+	lib, err := New(root, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	stats, err := lib.Scan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Indexed != 2 || len(repo.tracks) != 2 {
+		t.Fatalf("stats %#v tracks %d", stats, len(repo.tracks))
+	}
+	hello := repo.tracks[TrackID("Adele/hello.mp3")]
+	if hello.Artist != "Adele" || hello.Path != "Adele/hello.mp3" || hello.SearchText != "adele hello 25" || hello.DurationSec == 0 {
+		t.Fatalf("row: %#v", hello)
+	}
+
+	if err := os.Remove(gone); err != nil {
+		t.Fatal(err)
+	}
+	stats, err = lib.Scan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Unchanged != 1 || stats.Removed != 1 || repo.upserts != 2 {
+		t.Fatalf("rescan stats %#v upserts %d", stats, repo.upserts)
+	}
+
+	p, err := lib.FilePath(ctx, hello.ID)
+	if err != nil || !strings.HasPrefix(p, lib.Root()) {
+		t.Fatalf("path %q err %v", p, err)
+	}
+}
+
+func TestFilePathStaysInsideRoot(t *testing.T) {
+	root := t.TempDir()
+	outside := writeFile(t, t.TempDir(), "secret.mp3", []byte("x"))
+	if err := os.Symlink(outside, filepath.Join(root, "link.mp3")); err != nil {
+		t.Skip("symlinks unsupported:", err)
+	}
+	repo := &memRepo{tracks: map[string]domain.LibraryTrack{
+		"up":   {ID: "up", Path: "../secret.mp3", ModTime: time.Now()},
+		"abs":  {ID: "abs", Path: outside},
+		"link": {ID: "link", Path: "link.mp3"},
+	}}
+	lib, err := New(root, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id := range repo.tracks {
+		if _, err := lib.FilePath(context.Background(), id); !errors.Is(err, domain.ErrNotFound) {
+			t.Fatalf("%s: escaped root (err %v)", id, err)
+		}
+	}
+	// Symlinked files are never indexed either.
+	stats, _ := lib.Scan(context.Background())
+	if stats.Seen != 0 {
+		t.Fatalf("symlink indexed: %#v", stats)
+	}
+}
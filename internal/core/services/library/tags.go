@@ -0,0 +1,84 @@
+package library
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Tags is the subset of file metadata the index stores.
+type Tags struct {
+	Artist   string
+	Title    string
+	Album    string
+	Duration time.Duration
+}
+
+// Supported reports whether the scanner indexes files with this extension.
+func Supported(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3", ".flac", ".m4a", ".mp4":
+		return true
+	default:
+		return false
+	}
+}
+
+// ReadTags reads ID3v2/ID3v1 (mp3), Vorbis comments (flac) or iTunes ilst atoms (m4a).
+// Missing artist/title fall back to the "Artist - Title" file name.
+func ReadTags(path string) (Tags, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Tags{}, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return Tags{}, err
+	}
+
+	var tags Tags
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3":
+		tags, err = readMP3(f, st.Size())
+	case ".flac":
+		tags, err = readFLAC(f)
+	case ".m4a", ".mp4":
+		tags, err = readMP4(f, st.Size())
+	default:
+		return Tags{}, fmt.Errorf("library: unsupported file %s", filepath.Base(path))
+	}
+	if err != nil {
+		return Tags{}, err
+	}
+	tags.Artist = cleanTag(tags.Artist)
+	tags.Title = cleanTag(tags.Title)
+	tags.Album = cleanTag(tags.Album)
+	if tags.Artist == "" || tags.Title == "" {
+		artist, title := nameFromFile(path)
+		if tags.Artist == "" {
+			tags.Artist = artist
+		}
+		if tags.Title == "" {
+			tags.Title = title
+		}
+	}
+	return tags, nil
+}
+
+func cleanTag(s string) string {
+	s = strings.TrimRight(s, "\x00")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// "Adele - Hello.mp3" → ("Adele", "Hello"); no separator → ("", base name).
+func nameFromFile(path string) (artist, title string) {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	base = strings.ReplaceAll(base, "_", " ")
+	if i := strings.Index(base, " - "); i > 0 {
+		return cleanTag(base[:i]), cleanTag(base[i+3:])
+	}
+	return "", cleanTag(base)
+}
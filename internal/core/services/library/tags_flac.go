@@ -0,0 +1,117 @@
+package library
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// readFLAC walks metadata blocks: STREAMINFO (duration) and VORBIS_COMMENT (tags).
+// A leading ID3v2 tag (some rippers add one) is skipped.
+func readFLAC(r io.ReadSeeker) (Tags, error) {
+	var tags Tags
+	head := make([]byte, 10)
+	if _, err := io.ReadFull(r, head[:4]); err != nil {
+		return tags, err
+	}
+	if bytes.HasPrefix(head[:3], []byte("ID3")) {
+		if _, err := io.ReadFull(r, head[4:10]); err != nil {
+			return tags, err
+		}
+		if _, err := r.Seek(10+int64(syncsafe(head[6:10])), io.SeekStart); err != nil {
+			return tags, err
+		}
+		if _, err := io.ReadFull(r, head[:4]); err != nil {
+			return tags, err
+		}
+	}
+	if string(head[:4]) != "fLaC" {
+		return tags, errors.New("library: not a flac stream")
+	}
+
+	hdr := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			return tags, nil
+		}
+		last := hdr[0]&0x80 != 0
+		kind := hdr[0] & 0x7f
+		n := int64(hdr[1])<<16 | int64(hdr[2])<<8 | int64(hdr[3])
+		switch kind {
+		case 0, 4:
+			if n > maxID3Size {
+				return tags, nil
+			}
+			block := make([]byte, n)
+			if _, err := io.ReadFull(r, block); err != nil {
+				return tags, nil
+			}
+			if kind == 0 {
+				tags.Duration = flacDuration(block)
+			} else {
+				parseVorbisComment(block, &tags)
+			}
+		default:
+			if _, err := r.Seek(n, io.SeekCurrent); err != nil {
+				return tags, nil
+			}
+		}
+		if last {
+			return tags, nil
+		}
+	}
+}
+
+func flacDuration(info []byte) time.Duration {
+	if len(info) < 18 {
+		return 0
+	}
+	// Bytes 10..17: 20-bit sample rate, 3-bit channels, 5-bit bps, 36-bit total samples.
+	rate := uint64(info[10])<<12 | uint64(info[11])<<4 | uint64(info[12])>>4
+	total := uint64(info[13]&0x0f)<<32 | uint64(binary.BigEndian.Uint32(info[14:18]))
+	if rate == 0 || total == 0 {
+		return 0
+	}
+	return time.Duration(float64(total) / float64(rate) * float64(time.Second))
+}
+
+func parseVorbisComment(b []byte, tags *Tags) {
+	if len(b) < 8 {
+		return
+	}
+	pos := 4 + int(binary.LittleEndian.Uint32(b[:4])) // vendor string
+	if pos+4 > len(b) {
+		return
+	}
+	count := int(binary.LittleEndian.Uint32(b[pos:]))
+	pos += 4
+	for i := 0; i < count && pos+4 <= len(b); i++ {
+		n := int(binary.LittleEndian.Uint32(b[pos:]))
+		pos += 4
+		if n < 0 || pos+n > len(b) {
+			return
+		}
+		key, value, ok := strings.Cut(string(b[pos:pos+n]), "=")
+		pos += n
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(key) {
+		case "TITLE":
+			if tags.Title == "" {
+				tags.Title = value
+			}
+		case "ARTIST":
+			if tags.Artist == "" {
+				tags.Artist = value
+			}
+		case "ALBUM":
+			if tags.Album == "" {
+				tags.Album = value
+			}
+		}
+	}
+}
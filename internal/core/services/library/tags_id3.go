@@ -0,0 +1,260 @@
+package library
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const maxID3Size = 4 << 20 // APIC-heavy tags are rarely larger; beyond that skip frames
+
+// readMP3 parses a leading ID3v2 tag (2.2–2.4), falls back to ID3v1, and estimates
+// duration from TLEN, a Xing/Info header or the first frame's CBR bitrate.
+func readMP3(r io.ReadSeeker, size int64) (Tags, error) {
+	var tags Tags
+	head := make([]byte, 10)
+	audioStart := int64(0)
+	if _, err := io.ReadFull(r, head); err == nil && bytes.HasPrefix(head, []byte("ID3")) {
+		tagSize := int64(syncsafe(head[6:10]))
+		audioStart = 10 + tagSize
+		if head[5]&0x10 != 0 {
+			audioStart += 10 // footer
+		}
+		if tagSize <= maxID3Size {
+			body := make([]byte, tagSize)
+			if _, err := io.ReadFull(r, body); err == nil {
+				tags = parseID3v2(head[3], head[5], body)
+			}
+		}
+	}
+
+	if tags.Artist == "" || tags.Title == "" {
+		if v1, ok := readID3v1(r, size); ok {
+			if tags.Artist == "" {
+				tags.Artist = v1.Artist
+			}
+			if tags.Title == "" {
+				tags.Title = v1.Title
+			}
+			if tags.Album == "" {
+				tags.Album = v1.Album
+			}
+		}
+	}
+	if tags.Duration == 0 {
+		tags.Duration = mp3Duration(r, audioStart, size)
+	}
+	return tags, nil
+}
+
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7f)<<21 | uint32(b[1]&0x7f)<<14 | uint32(b[2]&0x7f)<<7 | uint32(b[3]&0x7f)
+}
+
+func parseID3v2(major, flags byte, body []byte) Tags {
+	var tags Tags
+	if flags&0x80 != 0 && major < 4 {
+		body = removeUnsync(body)
+	}
+	pos := 0
+	if flags&0x40 != 0 && len(body) >= 4 {
+		// Extended header: v2.4 size includes itself (syncsafe), v2.3 excludes its 4 size bytes.
+		if major >= 4 {
+			pos = int(syncsafe(body[:4]))
+		} else {
+			pos = 4 + int(binary.BigEndian.Uint32(body[:4]))
+		}
+	}
+
+	idLen, hdrLen := 4, 10
+	if major == 2 {
+		idLen, hdrLen = 3, 6
+	}
+	for pos+hdrLen <= len(body) {
+		id := string(body[pos : pos+idLen])
+		if id[0] == 0 {
+			break // padding
+		}
+		var n int
+		switch major {
+		case 2:
+			n = int(body[pos+3])<<16 | int(body[pos+4])<<8 | int(body[pos+5])
+		case 3:
+			n = int(binary.BigEndian.Uint32(body[pos+4 : pos+8]))
+		default:
+			n = int(syncsafe(body[pos+4 : pos+8]))
+		}
+		pos += hdrLen
+		if n <= 0 || pos+n > len(body) {
+			break
+		}
+		frame := body[pos : pos+n]
+		pos += n
+
+		switch id {
+		case "TIT2", "TT2":
+			tags.Title = decodeID3Text(frame)
+		case "TPE1", "TP1":
+			tags.Artist = decodeID3Text(frame)
+		case "TALB", "TAL":
+			tags.Album = decodeID3Text(frame)
+		case "TLEN", "TLE":
+			if ms, err := strconv.Atoi(strings.TrimSpace(decodeID3Text(frame))); err == nil && ms > 0 {
+				tags.Duration = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return tags
+}
+
+func removeUnsync(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte{0xff, 0x00}, []byte{0xff})
+}
+
+// decodeID3Text handles the four text encodings; multi-value frames keep the first value.
+func decodeID3Text(frame []byte) string {
+	if len(frame) < 1 {
+		return ""
+	}
+	enc, data := frame[0], frame[1:]
+	var s string
+	switch enc {
+	case 1, 2:
+		s = decodeUTF16(data, enc == 2)
+	case 3:
+		s = string(data)
+	default:
+		s = latin1(data)
+	}
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+func decodeUTF16(b []byte, bigEndian bool) string {
+	if len(b) >= 2 {
+		switch {
+		case b[0] == 0xff && b[1] == 0xfe:
+			bigEndian, b = false, b[2:]
+		case b[0] == 0xfe && b[1] == 0xff:
+			bigEndian, b = true, b[2:]
+		}
+	}
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		var v uint16
+		if bigEndian {
+			v = binary.BigEndian.Uint16(b[i:])
+		} else {
+			v = binary.LittleEndian.Uint16(b[i:])
+		}
+		if v == 0 {
+			break
+		}
+		u = append(u, v)
+	}
+	return string(utf16.Decode(u))
+}
+
+func latin1(b []byte) string {
+	r := make([]rune, len(b))
+	for i, c := range b {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
+
+func readID3v1(r io.ReadSeeker, size int64) (Tags, bool) {
+	if size < 128 {
+		return Tags{}, false
+	}
+	buf := make([]byte, 128)
+	if _, err := r.Seek(size-128, io.SeekStart); err != nil {
+		return Tags{}, false
+	}
+	if _, err := io.ReadFull(r, buf); err != nil || !bytes.HasPrefix(buf, []byte("TAG")) {
+		return Tags{}, false
+	}
+	field := func(b []byte) string {
+		if i := bytes.IndexByte(b, 0); i >= 0 {
+			b = b[:i]
+		}
+		return strings.TrimSpace(latin1(b))
+	}
+	return Tags{Title: field(buf[3:33]), Artist: field(buf[33:63]), Album: field(buf[63:93])}, true
+}
+
+var (
+	mpeg1L3Kbps = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mpeg2L3Kbps = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+	mpegRates   = [4][3]int{{11025, 12000, 8000}, {0, 0, 0}, {22050, 24000, 16000}, {44100, 48000, 32000}} // by version bits
+)
+
+// mp3Duration reads the first Layer III frame after start: Xing/Info frame count when present,
+// otherwise CBR estimate from file size.
+func mp3Duration(r io.ReadSeeker, start, size int64) time.Duration {
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return 0
+	}
+	buf := make([]byte, 64<<10)
+	n, _ := io.ReadFull(r, buf)
+	buf = buf[:n]
+	for i := 0; i+4 <= len(buf); i++ {
+		if buf[i] != 0xff || buf[i+1]&0xe0 != 0xe0 {
+			continue
+		}
+		ver := (buf[i+1] >> 3) & 0x03
+		layer := (buf[i+1] >> 1) & 0x03
+		brIdx := buf[i+2] >> 4
+		srIdx := (buf[i+2] >> 2) & 0x03
+		if ver == 1 || layer != 1 || brIdx == 0 || brIdx == 15 || srIdx == 3 {
+			continue
+		}
+		rate := mpegRates[ver][srIdx]
+		kbps := mpeg1L3Kbps[brIdx]
+		samples := 1152
+		if ver != 3 {
+			kbps = mpeg2L3Kbps[brIdx]
+			samples = 576
+		}
+		mono := buf[i+3]>>6 == 3
+		if frames := xingFrames(buf[i:], ver == 3, mono); frames > 0 {
+			return time.Duration(float64(frames) * float64(samples) / float64(rate) * float64(time.Second))
+		}
+		audio := size - (start + int64(i))
+		if audio <= 0 || kbps == 0 {
+			return 0
+		}
+		return time.Duration(float64(audio*8) / float64(kbps*1000) * float64(time.Second))
+	}
+	return 0
+}
+
+func xingFrames(frame []byte, mpeg1, mono bool) int {
+	off := 4
+	switch {
+	case mpeg1 && !mono:
+		off += 32
+	case mpeg1 && mono, !mpeg1 && !mono:
+		off += 17
+	default:
+		off += 9
+	}
+	if len(frame) < off+12 {
+		return 0
+	}
+	tag := string(frame[off : off+4])
+	if tag != "Xing" && tag != "Info" {
+		return 0
+	}
+	flags := binary.BigEndian.Uint32(frame[off+4 : off+8])
+	if flags&0x1 == 0 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint32(frame[off+8 : off+12]))
+}
@@ -0,0 +1,128 @@
+package library
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+const maxMoovSize = 16 << 20
+
+// readMP4 finds the top-level moov box (it may sit after mdat) and reads
+// mvhd duration plus ©nam/©ART/©alb from moov/udta/meta/ilst.
+func readMP4(r io.ReadSeeker, size int64) (Tags, error) {
+	var tags Tags
+	hdr := make([]byte, 16)
+	for pos := int64(0); pos+8 <= size; {
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			return tags, err
+		}
+		if _, err := io.ReadFull(r, hdr[:8]); err != nil {
+			return tags, err
+		}
+		boxSize := int64(binary.BigEndian.Uint32(hdr[:4]))
+		kind := string(hdr[4:8])
+		headLen := int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = size - pos
+		case 1:
+			if _, err := io.ReadFull(r, hdr[8:16]); err != nil {
+				return tags, err
+			}
+			boxSize = int64(binary.BigEndian.Uint64(hdr[8:16]))
+			headLen = 16
+		}
+		if boxSize < headLen {
+			return tags, errors.New("library: bad mp4 box")
+		}
+		if kind == "moov" {
+			n := boxSize - headLen
+			if n > maxMoovSize {
+				return tags, errors.New("library: mp4 moov too large")
+			}
+			body := make([]byte, n)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return tags, err
+			}
+			parseMP4Boxes(body, "moov", &tags)
+			return tags, nil
+		}
+		pos += boxSize
+	}
+	return tags, errors.New("library: mp4 without moov")
+}
+
+func parseMP4Boxes(b []byte, parent string, tags *Tags) {
+	for len(b) >= 8 {
+		n := int(binary.BigEndian.Uint32(b[:4]))
+		kind := string(b[4:8])
+		if n < 8 || n > len(b) {
+			return
+		}
+		body := b[8:n]
+		b = b[n:]
+
+		switch {
+		case kind == "mvhd":
+			tags.Duration = mvhdDuration(body)
+		case kind == "udta", kind == "ilst":
+			parseMP4Boxes(body, kind, tags)
+		case kind == "meta":
+			if len(body) >= 4 {
+				parseMP4Boxes(body[4:], kind, tags) // full box: version+flags first
+			}
+		case parent == "ilst":
+			value := mp4DataString(body)
+			switch kind {
+			case "\xa9nam":
+				tags.Title = value
+			case "\xa9ART":
+				tags.Artist = value
+			case "aART":
+				if tags.Artist == "" {
+					tags.Artist = value
+				}
+			case "\xa9alb":
+				tags.Album = value
+			}
+		}
+	}
+}
+
+func mvhdDuration(b []byte) time.Duration {
+	if len(b) < 20 {
+		return 0
+	}
+	var scale, dur uint64
+	if b[0] == 1 {
+		if len(b) < 32 {
+			return 0
+		}
+		scale = uint64(binary.BigEndian.Uint32(b[20:24]))
+		dur = binary.BigEndian.Uint64(b[24:32])
+	} else {
+		scale = uint64(binary.BigEndian.Uint32(b[12:16]))
+		dur = uint64(binary.BigEndian.Uint32(b[16:20]))
+	}
+	if scale == 0 {
+		return 0
+	}
+	return time.Duration(float64(dur) / float64(scale) * float64(time.Second))
+}
+
+// mp4DataString reads the first 'data' child: 4-byte type, 4-byte locale, UTF-8 payload.
+func mp4DataString(b []byte) string {
+	for len(b) >= 8 {
+		n := int(binary.BigEndian.Uint32(b[:4]))
+		if n < 8 || n > len(b) {
+			return ""
+		}
+		if string(b[4:8]) == "data" && n >= 16 {
+			return string(b[16:n])
+		}
+		b = b[n:]
+	}
+	return ""
+}
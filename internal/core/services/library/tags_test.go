@@ -0,0 +1,149 @@
+package library
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func id3Frame(id, text string) []byte {
+	body := append([]byte{3}, text...) // UTF-8
+	var b bytes.Buffer
+	b.WriteString(id)
+	_ = binary.Write(&b, binary.BigEndian, uint32(len(body)))
+	b.Write([]byte{0, 0})
+	b.Write(body)
+	return b.Bytes()
+}
+
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+}
+
+// 128 kbps / 44.1 kHz MPEG-1 Layer III frames, no Xing header.
+func cbrFrames(n int) []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xff, 0xfb, 0x90, 0x00})
+	return bytes.Repeat(frame, n)
+}
+
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func mp3Bytes(artist, title string) []byte {
+	frames := append(id3Frame("TIT2", title), id3Frame("TPE1", artist)...)
+	frames = append(frames, id3Frame("TALB", "25")...)
+	frames = append(frames, make([]byte, 32)...) // padding
+	var b bytes.Buffer
+	b.WriteString("ID3")
+	b.Write([]byte{3, 0, 0})
+	b.Write(syncsafeBytes(len(frames)))
+	b.Write(frames)
+	b.Write(cbrFrames(480)) // 480 × 417 B × 8 / 128 kbps ≈ 12.5 s
+	return b.Bytes()
+}
+
+func TestReadTagsMP3(t *testing.T) {
+	p := writeFile(t, t.TempDir(), "x.mp3", mp3Bytes("Adele", "Hello"))
+	tags, err := ReadTags(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags.Artist != "Adele" || tags.Title != "Hello" || tags.Album != "25" {
+		t.Fatalf("tags: %#v", tags)
+	}
+	if d := tags.Duration.Round(time.Second); d != 13*time.Second && d != 12*time.Second {
+		t.Fatalf("duration: %v", tags.Duration)
+	}
+}
+
+func TestReadTagsFileNameFallback(t *testing.T) {
+	p := writeFile(t, t.TempDir(), "Daft Punk - One More Time.mp3", cbrFrames(10))
+	tags, err := ReadTags(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags.Artist != "Daft Punk" || tags.Title != "One More Time" {
+		t.Fatalf("tags: %#v", tags)
+	}
+}
+
+func TestReadTagsFLAC(t *testing.T) {
+	info := make([]byte, 34)
+	// 44100 Hz, 2ch, 16 bps, 44100*200 samples.
+	rate, total := uint64(44100), uint64(44100*200)
+	info[10] = byte(rate >> 12)
+	info[11] = byte(rate >> 4)
+	info[12] = byte(rate<<4) | (1 << 1)
+	info[13] = 0xf0 | byte(total>>32&0x0f)
+	binary.BigEndian.PutUint32(info[14:18], uint32(total))
+
+	var vc bytes.Buffer
+	le := func(n int) { _ = binary.Write(&vc, binary.LittleEndian, uint32(n)) }
+	le(3)
+	vc.WriteString("ref")
+	comments := []string{"TITLE=Halo", "artist=Beyoncé", "ALBUM=I Am"}
+	le(len(comments))
+	for _, c := range comments {
+		le(len(c))
+		vc.WriteString(c)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("fLaC")
+	b.Write([]byte{0x00, 0, 0, byte(len(info))})
+	b.Write(info)
+	n := vc.Len()
+	b.Write([]byte{0x84, byte(n >> 16), byte(n >> 8), byte(n)})
+	b.Write(vc.Bytes())
+
+	tags, err := ReadTags(writeFile(t, t.TempDir(), "a.flac", b.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags.Artist != "Beyoncé" || tags.Title != "Halo" || tags.Album != "I Am" || tags.Duration != 200*time.Second {
+		t.Fatalf("tags: %#v", tags)
+	}
+}
+
+func mp4Box(kind string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(out, uint32(8+len(body)))
+	copy(out[4:], kind)
+	return append(out, body...)
+}
+
+func TestReadTagsMP4(t *testing.T) {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)   // timescale
+	binary.BigEndian.PutUint32(mvhd[16:], 245000) // 245 s
+	item := func(kind, v string) []byte {
+		return mp4Box(kind, mp4Box("data", []byte{0, 0, 0, 1, 0, 0, 0, 0}, []byte(v)))
+	}
+	ilst := mp4Box("ilst", item("\xa9nam", "Levitating"), item("\xa9ART", "Dua Lipa"), item("\xa9alb", "Future Nostalgia"))
+	moov := mp4Box("moov", mp4Box("mvhd", mvhd), mp4Box("udta", mp4Box("meta", []byte{0, 0, 0, 0}, ilst)))
+	// moov after mdat, like most encoders that don't "fast start".
+	file := append(mp4Box("ftyp", []byte("M4A \x00\x00\x00\x00")), mp4Box("mdat", make([]byte, 64))...)
+	file = append(file, moov...)
+
+	tags, err := ReadTags(writeFile(t, t.TempDir(), "b.m4a", file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags.Artist != "Dua Lipa" || tags.Title != "Levitating" || tags.Album != "Future Nostalgia" || tags.Duration != 245*time.Second {
+		t.Fatalf("tags: %#v", tags)
+	}
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	verifyOKTTL    = 6 * time.Hour
+	verifyBadTTL   = 30 * time.Minute
+	verifyCacheCap = 4096
+	verifySniffLen = 16
+)
+
+type linkVerdict struct {
+	ok bool
+	at time.Time
+}
+
+// LinkVerifier sniffs the first bytes of a candidate link (ranged GET) and caches
+// whether it is real audio. Network errors and budget overruns are "unknown" —
+// never cached, never demoted, so a slow CDN doesn't cost us a good link.
+type LinkVerifier struct {
+	client   *http.Client
+	decorate func(*http.Request)
+	budget   time.Duration
+	peek     int
+
+	mu    sync.Mutex
+	cache map[string]linkVerdict
+	sf    singleflight.Group
+}
+
+// NewLinkVerifier checks at most peek candidates per SearchFirst within budget.
+// decorate adds per-host headers (Referer/UA) the mirrors expect; may be nil.
+func NewLinkVerifier(client *http.Client, budget time.Duration, peek int, decorate func(*http.Request)) *LinkVerifier {
+	if budget <= 0 {
+		budget = 1500 * time.Millisecond
+	}
+	if peek <= 0 {
+		peek = 3
+	}
+	return &LinkVerifier{
+		client:   client,
+		decorate: decorate,
+		budget:   budget,
+		peek:     peek,
+		cache:    make(map[string]linkVerdict),
+	}
+}
+
+// Filter verifies the first peek songs concurrently and drops the ones that are
+// definitely not audio, so the next candidate in the list takes their place.
+func (v *LinkVerifier) Filter(ctx context.Context, songs []domain.Song) []domain.Song {
+	if v == nil || len(songs) == 0 {
+		return songs
+	}
+	n := min(v.peek, len(songs))
+	ctx, cancel := context.WithTimeout(ctx, v.budget)
+	defer cancel()
+
+	bad := make([]bool, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ok, known := v.Verify(ctx, songs[i].Link)
+			bad[i] = known && !ok
+		}(i)
+	}
+	wg.Wait()
+
+	out := make([]domain.Song, 0, len(songs))
+	for i, s := range songs {
+		if i < n && bad[i] {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// Verify reports (isAudio, known). known=false means the probe didn't finish.
+func (v *LinkVerifier) Verify(ctx context.Context, link string) (bool, bool) {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return false, true
+	}
+	if ok, hit := v.cached(link); hit {
+		return ok, true
+	}
+	res, _, _ := v.sf.Do(link, func() (any, error) {
+		ok, known := v.probe(ctx, link)
+		if known {
+			v.store(link, ok)
+		}
+		return [2]bool{ok, known}, nil
+	})
+	r := res.([2]bool)
+	return r[0], r[1]
+}
+
+func (v *LinkVerifier) probe(ctx context.Context, link string) (bool, bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return false, true
+	}
+	if v.decorate != nil {
+		v.decorate(req)
+	}
+	req.Header.Set("Range", "bytes=0-"+strconv.Itoa(verifySniffLen-1))
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		// 5xx may be a blip; 4xx (403 hotlink, 404 gone) is a verdict.
+		return false, resp.StatusCode < 500
+	}
+	ct := strings.ToLower(resp.Header.Get("Content-Type"))
+	if strings.HasPrefix(ct, "text/") || strings.Contains(ct, "json") || strings.Contains(ct, "xml") {
+		return false, true
+	}
+	if resp.ContentLength == 0 || contentRangeTotal(resp.Header.Get("Content-Range")) == 0 {
+		return false, true
+	}
+	head := make([]byte, verifySniffLen)
+	n, err := io.ReadFull(resp.Body, head)
+	if n == 0 {
+		if err != nil && ctx.Err() != nil {
+			return false, false
+		}
+		return false, true
+	}
+	return isAudioMagic(head[:n]), true
+}
+
+// contentRangeTotal parses "bytes 0-15/1234" → 1234; -1 when absent or unknown ("*").
+func contentRangeTotal(h string) int64 {
+	i := strings.LastIndexByte(h, '/')
+	if i < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(strings.TrimSpace(h[i+1:]), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
+
+// isAudioMagic: ID3 tag, MPEG audio frame sync, MP4 ftyp box, FLAC or Ogg.
+func isAudioMagic(b []byte) bool {
+	switch {
+	case bytes.HasPrefix(b, []byte("ID3")),
+		bytes.HasPrefix(b, []byte("fLaC")),
+		bytes.HasPrefix(b, []byte("OggS")):
+		return true
+	case len(b) >= 2 && b[0] == 0xff && b[1]&0xe0 == 0xe0 && b[1]&0x06 != 0:
+		return true // frame sync + a real layer
+	case len(b) >= 8 && string(b[4:8]) == "ftyp":
+		return true
+	}
+	return false
+}
+
+func (v *LinkVerifier) cached(link string) (ok, hit bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	e, found := v.cache[link]
+	if !found {
+		return false, false
+	}
+	ttl := verifyBadTTL
+	if e.ok {
+		ttl = verifyOKTTL
+	}
+	if time.Since(e.at) > ttl {
+		delete(v.cache, link)
+		return false, false
+	}
+	return e.ok, true
+}
+
+func (v *LinkVerifier) store(link string, ok bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.cache) >= verifyCacheCap {
+		// Cheap bound: drop everything expired, then an arbitrary half if still full.
+		now := time.Now()
+		for k, e := range v.cache {
+			if now.Sub(e.at) > verifyBadTTL {
+				delete(v.cache, k)
+			}
+		}
+		for k := range v.cache {
+			if len(v.cache) < verifyCacheCap/2 {
+				break
+			}
+			delete(v.cache, k)
+		}
+	}
+	v.cache[link] = linkVerdict{ok: ok, at: time.Now()}
+}
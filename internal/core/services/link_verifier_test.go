@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/ports"
+)
+
+func verifierServer(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/id3.mp3":
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Header().Set("Content-Range", "bytes 0-15/4000000")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte("ID3\x04\x00\x00\x00\x00\x00\x00\xff\xfb\x90\x00\x00\x00"))
+		case "/frame.mp3":
+			// Range ignored — full 200 body, octet-stream.
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write(append([]byte{0xff, 0xfb, 0x90, 0x00}, make([]byte, 4096)...))
+		case "/song.m4a":
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte("\x00\x00\x00\x20ftypM4A \x00\x00\x00\x00"))
+		case "/page.mp3":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html>Just a moment</html>"))
+		case "/html-octet.mp3":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte("<!DOCTYPE html><html></html>"))
+		case "/empty.mp3":
+			w.Header().Set("Content-Range", "bytes */0")
+			w.WriteHeader(http.StatusPartialContent)
+		case "/forbidden.mp3":
+			w.WriteHeader(http.StatusForbidden)
+		case "/slow.mp3":
+			time.Sleep(200 * time.Millisecond)
+			_, _ = w.Write([]byte("ID3"))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLinkVerifierVerdicts(t *testing.T) {
+	var hits atomic.Int32
+	srv := verifierServer(t, &hits)
+	v := NewLinkVerifier(srv.Client(), time.Second, 3, nil)
+	cases := map[string][2]bool{ // path → {ok, known}
+		"/id3.mp3":        {true, true},
+		"/frame.mp3":      {true, true},
+		"/song.m4a":       {true, true},
+		"/page.mp3":       {false, true},
+		"/html-octet.mp3": {false, true},
+		"/empty.mp3":      {false, true},
+		"/forbidden.mp3":  {false, true},
+	}
+	for path, want := range cases {
+		ok, known := v.Verify(context.Background(), srv.URL+path)
+		if ok != want[0] || known != want[1] {
+			t.Fatalf("%s: ok=%v known=%v", path, ok, known)
+		}
+	}
+	before := hits.Load()
+	v.Verify(context.Background(), srv.URL+"/id3.mp3")
+	v.Verify(context.Background(), srv.URL+"/page.mp3")
+	if hits.Load() != before {
+		t.Fatal("verdicts should be cached")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, known := v.Verify(ctx, srv.URL+"/slow.mp3"); known {
+		t.Fatal("budget overrun must be unknown")
+	}
+	if _, hit := v.cached(srv.URL + "/slow.mp3"); hit {
+		t.Fatal("unknown verdict must not be cached")
+	}
+}
+
+func TestSearchFirstDemotesNonAudioLinks(t *testing.T) {
+	var hits atomic.Int32
+	srv := verifierServer(t, &hits)
+	provider := stubProvider{
+		name:     "Mp3pm",
+		priority: 8,
+		results: []domain.ProviderResult{
+			{Song: domain.Song{Title: "Hello", Artist: "Adele", Link: srv.URL + "/page.mp3"}},
+			{Song: domain.Song{Title: "Hello", Artist: "Adele", Link: srv.URL + "/slow.mp3"}},
+			{Song: domain.Song{Title: "Hello", Artist: "Adele", Link: srv.URL + "/id3.mp3"}},
+		},
+	}
+	svc := NewSearchService([]ports.IMusicProvider{provider}, domain.DefaultSearchConfig(), time.Second, stubCatalog{})
+	svc.SetVerifier(NewLinkVerifier(srv.Client(), 60*time.Millisecond, 3, nil))
+
+	start := time.Now()
+	got, err := svc.SearchFirst(context.Background(), "adele hello", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) > 180*time.Millisecond {
+		t.Fatalf("verification ignored its budget: %v", time.Since(start))
+	}
+	// HTML page dropped; slow link (unknown) keeps its place ahead of the verified one.
+	if len(got) != 2 || got[0].Link != srv.URL+"/slow.mp3" || got[1].Link != srv.URL+"/id3.mp3" {
+		t.Fatalf("got %+v", got)
+	}
+}
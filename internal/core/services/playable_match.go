@@ -80,11 +80,17 @@ func titleCoresOverlap(want, got string) bool {
 
 // PickPlayableSong chooses the strongest artist+title match in a provider peek.
 func PickPlayableSong(wantArtist, wantTitle string, songs []domain.Song, seedKey string, peek int) (domain.Song, bool) {
+	return PickPlayableSongNear(wantArtist, wantTitle, 0, songs, seedKey, peek)
+}
+
+// PickPlayableSongNear is PickPlayableSong with the catalog duration (seconds) as a
+// tie-breaker: "Song (Extended Mix)" at 7:30 loses to the 3:40 edit when 3:40 is wanted.
+func PickPlayableSongNear(wantArtist, wantTitle string, wantDuration int, songs []domain.Song, seedKey string, peek int) (domain.Song, bool) {
 	wantKey := SongKey(wantArtist, wantTitle)
 	wantCore := CoreTitle(wantTitle)
 	wantArt := utils.NormalizeString(wantArtist)
 
-	bestRank := -1
+	found, bestRank := false, 0
 	var best domain.Song
 	n := peek
 	if n <= 0 || n > len(songs) {
@@ -114,17 +120,61 @@ func PickPlayableSong(wantArtist, wantTitle string, songs []domain.Song, seedKey
 		if !IsRemixy(s.Title) {
 			rank += 1
 		}
-		if rank > bestRank {
-			bestRank = rank
+		rank += durationRank(wantDuration, s.Duration)
+		if !found || rank > bestRank {
+			found, bestRank = true, rank
 			best = s
 		}
 	}
-	if bestRank < 0 {
+	if !found {
 		return domain.Song{}, false
 	}
 	return best, true
 }
 
+// durationRank: close durations outrank title tie-breaks (core+artist+clean = 7);
+// far-off ones (edits, previews, hour-long mixes) sink. Unknown on either side is neutral.
+func durationRank(want, got int) int {
+	if want <= 0 || got <= 0 {
+		return 0
+	}
+	diff := want - got
+	if diff < 0 {
+		diff = -diff
+	}
+	switch {
+	case diff <= max(5, want*3/100):
+		return 8
+	case diff > max(30, want*15/100):
+		return -8
+	default:
+		return 0
+	}
+}
+
+// DurationsDiffer reports whether matching candidates disagree enough on length
+// that the catalog duration would change the pick (worth a track.getInfo).
+func DurationsDiffer(wantArtist, wantTitle string, songs []domain.Song, peek int) bool {
+	n := peek
+	if n <= 0 || n > len(songs) {
+		n = len(songs)
+	}
+	lo, hi := 0, 0
+	for i := 0; i < n; i++ {
+		d := songs[i].Duration
+		if d <= 0 || !IsPlayableMatch(wantArtist, wantTitle, songs[i]) {
+			continue
+		}
+		if lo == 0 || d < lo {
+			lo = d
+		}
+		if d > hi {
+			hi = d
+		}
+	}
+	return lo > 0 && hi-lo > max(30, lo*15/100)
+}
+
 func IsRemixy(title string) bool {
 	t := utils.NormalizeString(title)
 	return matchJunkRe.MatchString(t) || matchParenRe.MatchString(t)
@@ -1,6 +1,10 @@
 package services
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
 
 func TestCoreTitleStripsRemixVariants(t *testing.T) {
 	a := CoreTitle("Collide (Extended Mix)")
@@ -16,3 +20,21 @@ func TestSongKeyStable(t *testing.T) {
 		t.Fatal("song keys should collapse remix variants")
 	}
 }
+
+func TestPickPlayableSongNearPrefersCloseDuration(t *testing.T) {
+	songs := []domain.Song{
+		{Artist: "Vicetone", Title: "Collide (Extended Mix)", Link: "https://a/ext.mp3", Duration: 412},
+		{Artist: "Vicetone", Title: "Collide (Radio Edit)", Link: "https://a/edit.mp3", Duration: 205},
+	}
+	got, ok := PickPlayableSongNear("Vicetone", "Collide", 210, songs, "", 5)
+	if !ok || got.Link != "https://a/edit.mp3" {
+		t.Fatalf("got %#v", got)
+	}
+	// Far-off duration still matches when it's the only candidate.
+	if got, ok := PickPlayableSongNear("Vicetone", "Collide", 210, songs[:1], "", 5); !ok || got.Duration != 412 {
+		t.Fatalf("single candidate: %#v ok=%v", got, ok)
+	}
+	if !DurationsDiffer("Vicetone", "Collide", songs, 5) || DurationsDiffer("Vicetone", "Collide", songs[1:], 5) {
+		t.Fatal("DurationsDiffer")
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -22,11 +24,19 @@ type proxyRotator interface {
 	AttemptBudget(fallback int) int
 }
 
+// Re-warm at most this often per provider — a block storm must not turn into a homepage storm.
+const warmCooldown = time.Minute
+
 type BaseProvider struct {
 	name     string
 	priority int
 	client   *http.Client
 	rotator  proxyRotator
+
+	// warmURL is a page that hands out session/challenge cookies (homepage).
+	warmURL  string
+	warmMu   sync.Mutex
+	lastWarm time.Time
 }
 
 func NewBaseProvider(name string, priority int, client *http.Client) *BaseProvider {
@@ -39,6 +49,36 @@ func (bp *BaseProvider) WithRotator(r proxyRotator) *BaseProvider {
 	return bp
 }
 
+// WithWarmURL sets the page Warm fetches to earn cookies.
+func (bp *BaseProvider) WithWarmURL(u string) *BaseProvider {
+	bp.warmURL = u
+	return bp
+}
+
+// Warm fetches warmURL so the jar holds fresh session/clearance cookies.
+// No-op without a warm URL; skipped when a warm-up ran within warmCooldown.
+func (bp *BaseProvider) Warm(ctx context.Context) error {
+	if bp.warmURL == "" || bp.client == nil {
+		return nil
+	}
+	bp.warmMu.Lock()
+	if !bp.lastWarm.IsZero() && time.Since(bp.lastWarm) < warmCooldown {
+		bp.warmMu.Unlock()
+		return nil
+	}
+	bp.lastWarm = time.Now()
+	bp.warmMu.Unlock()
+
+	_, blocked, err := bp.doFetch(ctx, bp.warmURL, "")
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return fmt.Errorf("%s: warm-up blocked", bp.name)
+	}
+	return nil
+}
+
 func (bp *BaseProvider) Name() string  { return bp.name }
 func (bp *BaseProvider) Priority() int { return bp.priority }
 
@@ -71,6 +111,12 @@ func (bp *BaseProvider) fetchDocument(ctx context.Context, rawURL, referer strin
 		if bp.rotator != nil {
 			bp.rotator.Rotate()
 		}
+		// Earn cookies on the (possibly new) egress before the retry.
+		if i+1 < attempts {
+			if err := bp.Warm(ctx); err != nil {
+				utils.GetLogger().Debug("provider warm-up failed", "provider", bp.name, "error", err)
+			}
+		}
 	}
 	if lastErr == nil {
 		lastErr = fmt.Errorf("%s: fetch failed", bp.name)
@@ -189,6 +235,53 @@ func playableLink(raw string) string {
 	return u
 }
 
+// parseClock reads "4:55" / "04:55" / "1:02:03" as seconds; 0 when unparsable.
+func parseClock(raw string) int {
+	parts := strings.Split(strings.TrimSpace(raw), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0
+	}
+	total := 0
+	for _, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || n < 0 {
+			return 0
+		}
+		total = total*60 + n
+	}
+	return total
+}
+
+// parseKbps reads "320 Kb/s" / "320kbps" as 320; 0 when there is no bitrate.
+func parseKbps(raw string) int {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if !strings.Contains(raw, "kb") {
+		return 0
+	}
+	end := 0
+	for end < len(raw) && raw[end] >= '0' && raw[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(raw[:end])
+	return n
+}
+
 func text(s *goquery.Selection) string {
 	return strings.TrimSpace(s.Text())
 }
+
+type warmer interface {
+	Name() string
+	Warm(ctx context.Context) error
+}
+
+// WarmUp primes cookies for each provider in turn (startup). Failures are logged, not fatal.
+func WarmUp(ctx context.Context, list ...warmer) {
+	for _, p := range list {
+		if err := p.Warm(ctx); err != nil {
+			utils.GetLogger().Warn("provider warm-up failed", "provider", p.Name(), "error", err)
+			continue
+		}
+		utils.GetLogger().Info("provider warmed", "provider", p.Name())
+	}
+}
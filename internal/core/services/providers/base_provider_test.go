@@ -1,6 +1,11 @@
 package providers
 
 import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 )
 
@@ -40,6 +45,61 @@ func TestIsNotFoundAndBlockedErr(t *testing.T) {
 	}
 }
 
+func TestParseClockAndKbps(t *testing.T) {
+	for raw, want := range map[string]int{"4:55": 295, "04:55": 295, "1:02:03": 3723, "": 0, "4m": 0, "3:x": 0} {
+		if got := parseClock(raw); got != want {
+			t.Fatalf("parseClock(%q) = %d, want %d", raw, got, want)
+		}
+	}
+	for raw, want := range map[string]int{"320 Kb/s": 320, "192kbps": 192, "04:55": 0, "": 0} {
+		if got := parseKbps(raw); got != want {
+			t.Fatalf("parseKbps(%q) = %d, want %d", raw, got, want)
+		}
+	}
+}
+
 type errString string
 
 func (e errString) Error() string { return string(e) }
+
+type stubRotator struct{ rotations atomic.Int32 }
+
+func (r *stubRotator) Rotate()               { r.rotations.Add(1) }
+func (r *stubRotator) AttemptBudget(int) int { return 2 }
+
+func TestFetchDocumentWarmsAfterBlock(t *testing.T) {
+	var warms atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			warms.Add(1)
+			http.SetCookie(w, &http.Cookie{Name: "vt", Value: "ok", Path: "/"})
+			_, _ = w.Write([]byte("<html>home</html>"))
+			return
+		}
+		if c, err := r.Cookie("vt"); err != nil || c.Value != "ok" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`<div class="row">hit</div>`))
+	}))
+	t.Cleanup(srv.Close)
+
+	jar, _ := cookiejar.New(nil)
+	client := srv.Client()
+	client.Jar = jar
+	rot := &stubRotator{}
+	bp := NewBaseProvider("T", 1, client).WithWarmURL(srv.URL + "/").WithRotator(rot)
+
+	doc, err := bp.fetchDocument(context.Background(), srv.URL+"/search", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Find(".row").Length() != 1 || warms.Load() != 1 || rot.rotations.Load() != 1 {
+		t.Fatalf("rows=%d warms=%d rotations=%d", doc.Find(".row").Length(), warms.Load(), rot.rotations.Load())
+	}
+
+	// Cool-down: an immediate second warm is skipped.
+	if err := bp.Warm(context.Background()); err != nil || warms.Load() != 1 {
+		t.Fatalf("warm within cool-down: err=%v warms=%d", err, warms.Load())
+	}
+}
@@ -0,0 +1,92 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/ports"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+)
+
+const (
+	localPageSize = 20
+	// A file on our own disk beats any remote source, Subsonic included.
+	DefaultLocalPriority = 11
+	// LocalStreamParam is the /stream query key carrying a library track id.
+	LocalStreamParam = "local"
+)
+
+// LocalLibraryProvider answers from the library_tracks index. Links point back at
+// this server's /stream?local=<id>, so publicURL must be the https origin clients reach.
+type LocalLibraryProvider struct {
+	*BaseProvider
+	repo   ports.ILibraryRepository
+	public *url.URL
+}
+
+func NewLocalLibraryProvider(repo ports.ILibraryRepository, publicURL string, priority int) (*LocalLibraryProvider, error) {
+	u, err := url.Parse(strings.TrimRight(strings.TrimSpace(publicURL), "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("library: invalid public url")
+	}
+	if !strings.EqualFold(u.Scheme, "https") {
+		return nil, fmt.Errorf("library: public url must be https")
+	}
+	if priority <= 0 {
+		priority = DefaultLocalPriority
+	}
+	return &LocalLibraryProvider{
+		BaseProvider: NewBaseProvider("Library", priority, nil),
+		repo:         repo,
+		public:       u,
+	}, nil
+}
+
+// Host is this server's public authority; /stream treats links on it as local.
+func (p *LocalLibraryProvider) Host() string { return p.public.Host }
+
+func (p *LocalLibraryProvider) SearchWithPage(ctx context.Context, query string, page int) ([]domain.ProviderResult, error) {
+	if page < 1 {
+		page = 1
+	}
+	query = utils.NormalizeString(query)
+	if query == "" {
+		return nil, nil
+	}
+	// One extra row tells us whether a next page exists.
+	tracks, err := p.repo.SearchTracks(ctx, query, localPageSize+1, (page-1)*localPageSize)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p.Name(), err)
+	}
+	hasNext := len(tracks) > localPageSize
+	if hasNext {
+		tracks = tracks[:localPageSize]
+	}
+	pagination := &domain.PaginationInfo{
+		CurrentPage: page,
+		HasPrevPage: page > 1,
+		HasNextPage: hasNext,
+		TotalPages:  page,
+	}
+	if hasNext {
+		pagination.TotalPages = page + 1
+	}
+	results := make([]domain.ProviderResult, 0, len(tracks))
+	for i, t := range tracks {
+		song := domain.NewSong(t.Title, t.Artist, "", p.StreamURL(t.ID))
+		song.Duration = t.DurationSec
+		results = append(results, domain.NewProviderResult(*song, p.Name(), i+1, pagination))
+	}
+	return results, nil
+}
+
+// StreamURL is <public>/stream?local=<id>.
+func (p *LocalLibraryProvider) StreamURL(id string) string {
+	u := *p.public
+	u.Path = strings.TrimRight(u.Path, "/") + "/stream"
+	u.RawQuery = url.Values{LocalStreamParam: {id}}.Encode()
+	return u.String()
+}
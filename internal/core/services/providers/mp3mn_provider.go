@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
-	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/andiq123/FindVibeFiber/internal/core/domain"
@@ -14,12 +13,10 @@ import (
 
 const mp3mnOrigin = "https://mp3mn.net"
 
-var mp3mnPace = newPacer(100 * time.Millisecond)
-
 type Mp3mnProvider struct{ *BaseProvider }
 
 func NewMp3mnProvider(client *http.Client) *Mp3mnProvider {
-	return &Mp3mnProvider{BaseProvider: NewBaseProvider("Mp3mn", 7, client)}
+	return &Mp3mnProvider{BaseProvider: NewBaseProvider("Mp3mn", 7, client).WithWarmURL(mp3mnOrigin + "/")}
 }
 
 func (p *Mp3mnProvider) SearchWithPage(ctx context.Context, query string, page int) ([]domain.ProviderResult, error) {
@@ -27,7 +24,7 @@ func (p *Mp3mnProvider) SearchWithPage(ctx context.Context, query string, page i
 	if page > 1 {
 		return nil, nil
 	}
-	if err := mp3mnPace.wait(ctx); err != nil {
+	if err := mp3mnLimit.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("%s: %w", p.Name(), err)
 	}
 
@@ -53,7 +50,9 @@ func (p *Mp3mnProvider) parseResults(doc *goquery.Document) []domain.ProviderRes
 		if title == "" || artist == "" || link == "" {
 			return
 		}
-		results = append(results, domain.NewProviderResult(*domain.NewSong(title, artist, "", link), p.Name(), rank, nil))
+		song := domain.NewSong(title, artist, "", link)
+		song.Duration = parseClock(text(s.Find(".playlist-duration").First()))
+		results = append(results, domain.NewProviderResult(*song, p.Name(), rank, nil))
 		rank++
 	})
 	return results
@@ -9,7 +9,6 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/andiq123/FindVibeFiber/internal/core/domain"
@@ -20,10 +19,7 @@ const (
 	mp3pmSearch = mp3pmOrigin + "/public/api.search.php"
 )
 
-var (
-	mp3pmPace      = newPacer(120 * time.Millisecond)
-	mp3pmSlugClean = regexp.MustCompile(`[^a-z0-9]+`)
-)
+var mp3pmSlugClean = regexp.MustCompile(`[^a-z0-9]+`)
 
 // Mp3pmProvider scrapes https://mp3.pm/
 // Flow: POST /public/api.search.php → https://s-<slug>.mp3.pm[/page/N/]
@@ -31,7 +27,7 @@ var (
 type Mp3pmProvider struct{ *BaseProvider }
 
 func NewMp3pmProvider(client *http.Client) *Mp3pmProvider {
-	return &Mp3pmProvider{BaseProvider: NewBaseProvider("Mp3pm", 8, client)}
+	return &Mp3pmProvider{BaseProvider: NewBaseProvider("Mp3pm", 8, client).WithWarmURL(mp3pmOrigin + "/")}
 }
 
 func (p *Mp3pmProvider) UseRotator(r proxyRotator) *Mp3pmProvider {
@@ -47,7 +43,7 @@ func (p *Mp3pmProvider) SearchWithPage(ctx context.Context, query string, page i
 	if query == "" {
 		return nil, nil
 	}
-	if err := mp3pmPace.wait(ctx); err != nil {
+	if err := mp3pmLimit.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("%s: %w", p.Name(), err)
 	}
 
@@ -131,6 +127,10 @@ func (p *Mp3pmProvider) parseResults(doc *goquery.Document, page int) []domain.P
 			return
 		}
 		song := domain.NewSong(title, artist, "", link)
+		song.Duration, _ = strconv.Atoi(strings.TrimSpace(s.AttrOr("data-sound-duration", "")))
+		if song.Duration <= 0 {
+			song.Duration = parseClock(text(s.Find(".cplayer-data-sound-time").First()))
+		}
 		results = append(results, domain.NewProviderResult(*song, p.Name(), rank, pagination))
 		rank++
 	})
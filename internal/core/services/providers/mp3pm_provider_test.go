@@ -40,7 +40,7 @@ func TestMp3pmFallbackSlug(t *testing.T) {
 func TestMp3pmParseResults(t *testing.T) {
 	html := `
 <ul class="mp3list">
-<li class="cplayer-sound-item" data-sound-id="1"
+<li class="cplayer-sound-item" data-sound-id="1" data-sound-duration="295"
 	data-sound-url="https://cs1.mp3.pm/listen/1/token/Adele_-_Hello_(mp3.pm).mp3"
 	data-download-url="https://cs1.mp3.pm/download/1/token/Adele_-_Hello_(mp3.pm).mp3">
 	<h4>
@@ -68,6 +68,9 @@ func TestMp3pmParseResults(t *testing.T) {
 	if got[0].Song.Artist != "Adele" || got[0].Song.Title != "Hello" {
 		t.Fatalf("meta: %#v", got[0].Song)
 	}
+	if got[0].Song.Duration != 295 {
+		t.Fatalf("duration: %d", got[0].Song.Duration)
+	}
 	if !strings.Contains(got[0].Song.Link, "cs1.mp3.pm/listen/") {
 		t.Fatalf("link: %q", got[0].Song.Link)
 	}
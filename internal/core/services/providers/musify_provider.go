@@ -7,7 +7,6 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/andiq123/FindVibeFiber/internal/core/domain"
@@ -15,14 +14,12 @@ import (
 
 const musifyOrigin = "https://musify.club"
 
-var musifyPace = newPacer(120 * time.Millisecond)
-
 // MusifyProvider scrapes https://musify.club/en/search
 // Tracks: .tracklist__row.playlist__item with data-artist/data-name + [data-url]=/track/pl/….mp3
 type MusifyProvider struct{ *BaseProvider }
 
 func NewMusifyProvider(client *http.Client) *MusifyProvider {
-	return &MusifyProvider{BaseProvider: NewBaseProvider("Musify", 6, client)}
+	return &MusifyProvider{BaseProvider: NewBaseProvider("Musify", 6, client).WithWarmURL(musifyOrigin + "/en/")}
 }
 
 func (p *MusifyProvider) UseRotator(r proxyRotator) *MusifyProvider {
@@ -38,7 +35,7 @@ func (p *MusifyProvider) SearchWithPage(ctx context.Context, query string, page
 	if query == "" {
 		return nil, nil
 	}
-	if err := musifyPace.wait(ctx); err != nil {
+	if err := musifyLimit.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("%s: %w", p.Name(), err)
 	}
 
@@ -89,6 +86,15 @@ func (p *MusifyProvider) parseResults(doc *goquery.Document, page int) []domain.
 			return
 		}
 		song := domain.NewSong(title, artist, image, link)
+		// .tracklist__duration holds "04:55" and "320 Kb/s" spans, order not guaranteed.
+		s.Find(".tracklist__duration span").Each(func(_ int, d *goquery.Selection) {
+			v := text(d)
+			if sec := parseClock(v); sec > 0 && song.Duration == 0 {
+				song.Duration = sec
+			} else if kbps := parseKbps(v); kbps > 0 && song.Bitrate == 0 {
+				song.Bitrate = kbps
+			}
+		})
 		results = append(results, domain.NewProviderResult(*song, p.Name(), rank, pagination))
 		rank++
 	})
@@ -27,6 +27,7 @@ func TestMusifyParseResults(t *testing.T) {
     <div class="tracklist__title"><a itemprop="url" href="/en/track/x">Bangarang.</a></div>
     <div class="tracklist__artist"><a href="/en/artist/skrillex">Skrillex</a></div>
   </div>
+  <div class="tracklist__duration"><span>03:35</span><span>320 Kb/s</span></div>
 </div>
 <div class="tracklist__row playlist__item" data-artist="Skip" data-name="">
   <div data-url="/track/pl/1/bad.mp3"></div>
@@ -49,6 +50,9 @@ func TestMusifyParseResults(t *testing.T) {
 	if !strings.Contains(s.Image, "musify.club") {
 		t.Fatalf("image: %q", s.Image)
 	}
+	if s.Duration != 215 || s.Bitrate != 320 {
+		t.Fatalf("duration/bitrate: %d %d", s.Duration, s.Bitrate)
+	}
 }
 
 func TestMusifySearchURL(t *testing.T) {
@@ -0,0 +1,242 @@
+package providers
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/ports"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+)
+
+// Lane orders waiters on a TokenBucket: interactive search first, background
+// (explore/radio prefetch) only takes tokens nobody interactive is queued for.
+type Lane int
+
+const (
+	LaneInteractive Lane = iota
+	LaneBackground
+	laneCount
+)
+
+func (l Lane) String() string {
+	if l == LaneBackground {
+		return "background"
+	}
+	return "interactive"
+}
+
+type laneKey struct{}
+
+// WithLane tags ctx so every provider call under it queues in lane.
+func WithLane(ctx context.Context, lane Lane) context.Context {
+	return context.WithValue(ctx, laneKey{}, lane)
+}
+
+func laneFrom(ctx context.Context) Lane {
+	if l, ok := ctx.Value(laneKey{}).(Lane); ok && l >= 0 && l < laneCount {
+		return l
+	}
+	return LaneInteractive
+}
+
+// Per-provider defaults keep the old pacer gaps (≈120ms/100ms) with a small burst.
+var (
+	mp3pmLimit  = NewTokenBucket("Mp3pm", 8, 2)
+	mp3mnLimit  = NewTokenBucket("Mp3mn", 10, 2)
+	musifyLimit = NewTokenBucket("Musify", 8, 2)
+)
+
+// Limiters lists the scraped-provider buckets (config + health).
+func Limiters() []*TokenBucket {
+	return []*TokenBucket{mp3pmLimit, mp3mnLimit, musifyLimit}
+}
+
+// TokenBucket refills rate tokens/s up to burst. With a shared budget attached every
+// local grant must also win a token from the cross-instance bucket.
+type TokenBucket struct {
+	name string
+
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	waiting [laneCount]int
+	shared  ports.ISharedRateBudget
+	// After a store error the shared path is skipped until sharedDown, doubling
+	// sharedBackoff per consecutive failure (sharedRetryMin…sharedRetryMax).
+	sharedDown    time.Time
+	sharedBackoff time.Duration
+}
+
+const (
+	sharedRetryMin = 5 * time.Second
+	sharedRetryMax = 5 * time.Minute
+)
+
+type LimiterStats struct {
+	Name        string  `json:"name"`
+	Rate        float64 `json:"rate"`
+	Burst       int     `json:"burst"`
+	Tokens      float64 `json:"tokens"`
+	Saturation  float64 `json:"saturation"` // 0 = full bucket, 1 = empty
+	Interactive int     `json:"waitingInteractive"`
+	Background  int     `json:"waitingBackground"`
+	Shared      bool    `json:"shared"`
+}
+
+func NewTokenBucket(name string, rate float64, burst int) *TokenBucket {
+	b := &TokenBucket{name: name}
+	b.Configure(rate, burst)
+	return b
+}
+
+func (b *TokenBucket) Name() string { return b.name }
+
+// Configure swaps rate/burst in place; rate <= 0 or burst < 1 keep the current values.
+func (b *TokenBucket) Configure(rate float64, burst int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rate > 0 {
+		b.rate = rate
+	}
+	if burst >= 1 {
+		b.burst = float64(burst)
+	}
+	if b.last.IsZero() || b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// UseShared attaches a cross-instance budget (nil detaches).
+func (b *TokenBucket) UseShared(shared ports.ISharedRateBudget) {
+	b.mu.Lock()
+	b.shared = shared
+	b.sharedDown, b.sharedBackoff = time.Time{}, 0
+	b.mu.Unlock()
+}
+
+// Wait blocks until a token is granted for ctx's lane. It never holds the lock while sleeping.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	lane := laneFrom(ctx)
+	queued := false
+	defer func() {
+		if queued {
+			b.mu.Lock()
+			b.waiting[lane]--
+			b.mu.Unlock()
+		}
+	}()
+
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.refillLocked(now)
+		yield := lane == LaneBackground && b.waiting[LaneInteractive] > 0
+		if b.tokens >= 1 && !yield {
+			b.tokens--
+			shared := b.sharedLocked(now)
+			b.mu.Unlock()
+			if shared == nil {
+				return nil
+			}
+			return b.waitShared(ctx, shared)
+		}
+		if !queued {
+			b.waiting[lane]++
+			queued = true
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		if yield || wait < time.Millisecond {
+			// Re-check soon: the interactive queue may drain before the next token.
+			wait = max(wait, 5*time.Millisecond)
+		}
+		b.mu.Unlock()
+
+		if err := sleepCtx(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *TokenBucket) refillLocked(now time.Time) {
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+}
+
+func (b *TokenBucket) sharedLocked(now time.Time) ports.ISharedRateBudget {
+	if now.Before(b.sharedDown) {
+		return nil
+	}
+	return b.shared
+}
+
+// waitShared polls the shared bucket at the provider's own token interval.
+// Store errors fail open (local bucket still applies) and back the shared path off
+// for a growing delay; the first grant after that clears the backoff.
+func (b *TokenBucket) waitShared(ctx context.Context, shared ports.ISharedRateBudget) error {
+	b.mu.Lock()
+	rate, burst := b.rate, int(b.burst)
+	b.mu.Unlock()
+	interval := time.Duration(float64(time.Second) / rate)
+	for {
+		ok, err := shared.TryTake(ctx, b.name, rate, burst)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			b.mu.Lock()
+			b.sharedBackoff = min(max(2*b.sharedBackoff, sharedRetryMin), sharedRetryMax)
+			b.sharedDown = time.Now().Add(b.sharedBackoff)
+			retry := b.sharedBackoff
+			b.mu.Unlock()
+			utils.GetLogger().Warn("shared rate budget unavailable; local limit only", "provider", b.name, "retry", retry, "error", err)
+			return nil
+		}
+		if ok {
+			b.mu.Lock()
+			b.sharedBackoff = 0
+			b.mu.Unlock()
+			return nil
+		}
+		if err := sleepCtx(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *TokenBucket) Stats() LimiterStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.refillLocked(now)
+	sat := 0.0
+	if b.burst > 0 {
+		sat = 1 - b.tokens/b.burst
+	}
+	return LimiterStats{
+		Name:        b.name,
+		Rate:        b.rate,
+		Burst:       int(b.burst),
+		Tokens:      math.Round(b.tokens*100) / 100,
+		Saturation:  math.Round(math.Max(0, math.Min(1, sat))*100) / 100,
+		Interactive: b.waiting[LaneInteractive],
+		Background:  b.waiting[LaneBackground],
+		Shared:      b.sharedLocked(now) != nil,
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
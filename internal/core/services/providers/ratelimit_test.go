@@ -0,0 +1,144 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketBurstThenRate(t *testing.T) {
+	b := NewTokenBucket("t", 25, 2) // 40ms per token after the burst
+	ctx := context.Background()
+	start := time.Now()
+	for range 3 {
+		if err := b.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Fatalf("third call should wait for a refill, got %v", elapsed)
+	}
+}
+
+func TestTokenBucketDoesNotHoldLockWhileSleeping(t *testing.T) {
+	b := NewTokenBucket("t", 12, 1)
+	ctx := context.Background()
+	_ = b.Wait(ctx) // drain
+
+	var wg sync.WaitGroup
+	started := make(chan struct{}, 2)
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started <- struct{}{}
+			_ = b.Wait(ctx)
+		}()
+	}
+	deadline := time.After(30 * time.Millisecond)
+	for range 2 {
+		select {
+		case <-started:
+		case <-deadline:
+			t.Fatal("callers blocked on mutex while another slept")
+		}
+	}
+	wg.Wait()
+}
+
+func TestTokenBucketInteractiveBeatsBackground(t *testing.T) {
+	b := NewTokenBucket("t", 20, 1)
+	_ = b.Wait(context.Background()) // drain
+
+	var order []Lane
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	run := func(lane Lane) {
+		defer wg.Done()
+		if err := b.Wait(WithLane(context.Background(), lane)); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		order = append(order, lane)
+		mu.Unlock()
+	}
+	wg.Add(1)
+	go run(LaneBackground)
+	time.Sleep(5 * time.Millisecond) // background queued first
+	wg.Add(1)
+	go run(LaneInteractive)
+	wg.Wait()
+	if len(order) != 2 || order[0] != LaneInteractive {
+		t.Fatalf("order: %v", order)
+	}
+}
+
+func TestTokenBucketWaitHonoursContext(t *testing.T) {
+	b := NewTokenBucket("t", 0.5, 1)
+	_ = b.Wait(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := b.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err: %v", err)
+	}
+	if s := b.Stats(); s.Interactive != 0 || s.Saturation < 0.9 {
+		t.Fatalf("stats after cancel: %#v", s)
+	}
+}
+
+type fakeShared struct {
+	denies atomic.Int32
+	calls  atomic.Int32
+	err    error
+}
+
+func (f *fakeShared) TryTake(context.Context, string, float64, int) (bool, error) {
+	f.calls.Add(1)
+	if f.err != nil {
+		return false, f.err
+	}
+	return f.denies.Add(-1) < 0, nil
+}
+
+func TestTokenBucketSharedBudget(t *testing.T) {
+	b := NewTokenBucket("t", 100, 5)
+	shared := &fakeShared{}
+	shared.denies.Store(2)
+	b.UseShared(shared)
+	if err := b.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if shared.calls.Load() != 3 {
+		t.Fatalf("shared calls: %d", shared.calls.Load())
+	}
+
+	// Store errors fail open and back the shared path off instead of latching it off.
+	broken := &fakeShared{err: errors.New("db down")}
+	b.UseShared(broken)
+	for range 2 {
+		if err := b.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b.Stats().Shared || broken.calls.Load() != 1 {
+		t.Fatalf("shared path should back off after an error: calls=%d", broken.calls.Load())
+	}
+	if b.sharedBackoff != sharedRetryMin {
+		t.Fatalf("backoff %v", b.sharedBackoff)
+	}
+
+	// Once the delay passes the store is tried again and a grant clears the backoff.
+	broken.err = nil
+	b.mu.Lock()
+	b.sharedDown = time.Now().Add(-time.Millisecond)
+	b.mu.Unlock()
+	if err := b.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !b.Stats().Shared || broken.calls.Load() != 2 || b.sharedBackoff != 0 {
+		t.Fatalf("shared not resumed: calls=%d backoff=%v", broken.calls.Load(), b.sharedBackoff)
+	}
+}
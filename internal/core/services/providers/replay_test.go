@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+)
+
+// Recorded search pages under testdata/replay catch markup drift offline.
+// Refresh against the live sites with `make record-fixtures` (RECORD=1 go test … -run Replay).
+func replayScrapeClient(t *testing.T) *http.Client {
+	t.Helper()
+	mode := utils.RecordReplay
+	if os.Getenv("RECORD") == "1" {
+		mode = utils.RecordRecord
+	}
+	scrape := utils.NewScrapeClient(20*time.Second, 10, 4, 30*time.Second, nil)
+	return utils.NewHTTPRecorder(mode, filepath.Join("testdata", "replay"), nil).Wrap(scrape.Client)
+}
+
+func TestReplayProvidersSearchWithPage(t *testing.T) {
+	client := replayScrapeClient(t)
+	cases := []struct {
+		name   string
+		query  string
+		search func(ctx context.Context, q string, page int) ([]domain.ProviderResult, error)
+	}{
+		{"Mp3pm", "adele", NewMp3pmProvider(client).SearchWithPage},
+		{"Mp3mn", "adele", NewMp3mnProvider(client).SearchWithPage},
+		{"Musify", "adele", NewMusifyProvider(client).SearchWithPage},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.search(context.Background(), tc.query, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) == 0 {
+				t.Fatal("no tracks parsed — markup drift?")
+			}
+			for _, r := range got {
+				s := r.Song
+				if s.Artist == "" || s.Title == "" {
+					t.Fatalf("empty meta: %#v", s)
+				}
+				if !strings.HasPrefix(s.Link, "https://") {
+					t.Fatalf("non-https link: %q", s.Link)
+				}
+				if r.Provider != tc.name || s.Provider != tc.name {
+					t.Fatalf("provider: %q / %q", r.Provider, s.Provider)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package providers
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+const (
+	subsonicAPIVersion = "1.16.1"
+	subsonicClientName = "FindVibe"
+	subsonicPageSize   = 20
+	// Self-hosted library beats every scraped mirror (Mp3pm is 8).
+	DefaultSubsonicPriority = 10
+)
+
+// SubsonicProvider searches a Subsonic/Navidrome server (search3) and returns
+// bare /rest/stream?id= links. Auth is token+salt (t = md5(password + s)) and is
+// added server-side by Authorize, so links carry no credentials and stay stable.
+type SubsonicProvider struct {
+	*BaseProvider
+	base     *url.URL
+	user     string
+	password string
+}
+
+// NewSubsonicProvider expects an https base URL (AVPlayer + /stream only take https).
+func NewSubsonicProvider(client *http.Client, baseURL, user, password string, priority int) (*SubsonicProvider, error) {
+	u, err := url.Parse(strings.TrimRight(strings.TrimSpace(baseURL), "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("subsonic: invalid base url")
+	}
+	if !strings.EqualFold(u.Scheme, "https") {
+		return nil, fmt.Errorf("subsonic: base url must be https")
+	}
+	if strings.TrimSpace(user) == "" || password == "" {
+		return nil, fmt.Errorf("subsonic: user and password required")
+	}
+	if priority <= 0 {
+		priority = DefaultSubsonicPriority
+	}
+	return &SubsonicProvider{
+		BaseProvider: NewBaseProvider("Subsonic", priority, client),
+		base:         u,
+		user:         strings.TrimSpace(user),
+		password:     password,
+	}, nil
+}
+
+// Host is the server authority /stream must allow (exact match, port included).
+func (p *SubsonicProvider) Host() string { return p.base.Host }
+
+func (p *SubsonicProvider) SearchWithPage(ctx context.Context, query string, page int) ([]domain.ProviderResult, error) {
+	if page < 1 {
+		page = 1
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	q := url.Values{
+		"query":       {query},
+		"songCount":   {strconv.Itoa(subsonicPageSize)},
+		"songOffset":  {strconv.Itoa((page - 1) * subsonicPageSize)},
+		"artistCount": {"0"},
+		"albumCount":  {"0"},
+	}
+	var payload struct {
+		SearchResult3 struct {
+			Song []subsonicSong `json:"song"`
+		} `json:"searchResult3"`
+	}
+	if err := p.call(ctx, "search3", q, &payload); err != nil {
+		return nil, err
+	}
+
+	songs := payload.SearchResult3.Song
+	pagination := &domain.PaginationInfo{
+		CurrentPage: page,
+		HasPrevPage: page > 1,
+		HasNextPage: len(songs) >= subsonicPageSize,
+		TotalPages:  page,
+	}
+	if pagination.HasNextPage {
+		pagination.TotalPages = page + 1
+	}
+	results := make([]domain.ProviderResult, 0, len(songs))
+	rank := 1
+	for _, s := range songs {
+		title, artist := strings.TrimSpace(s.Title), strings.TrimSpace(s.Artist)
+		if s.ID == "" || title == "" || artist == "" || s.IsVideo {
+			continue
+		}
+		// No cover: getCoverArt needs auth too; clients fill empty art via /cover.
+		song := domain.NewSong(title, artist, "", p.StreamURL(s.ID))
+		song.Duration, song.Bitrate = s.Duration, s.BitRate
+		results = append(results, domain.NewProviderResult(*song, p.Name(), rank, pagination))
+		rank++
+	}
+	return results, nil
+}
+
+// StreamURL is the public /rest/stream?id= link for a track — no auth params.
+func (p *SubsonicProvider) StreamURL(id string) string {
+	return p.endpoint("stream", url.Values{"id": {id}})
+}
+
+// Authorize adds fresh token auth to u in place; /stream calls it just before
+// fetching a link on this server so the token never reaches clients.
+func (p *SubsonicProvider) Authorize(u *url.URL) {
+	if u == nil || !strings.EqualFold(u.Host, p.base.Host) {
+		return
+	}
+	q := u.Query()
+	for k, v := range p.authParams() {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+}
+
+func (p *SubsonicProvider) call(ctx context.Context, endpoint string, q url.Values, out any) error {
+	for k, v := range p.authParams() {
+		q[k] = v
+	}
+	q.Set("f", "json")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint(endpoint, q), nil)
+	if err != nil {
+		return fmt.Errorf("%s: request: %w", p.Name(), err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: fetch: %w", p.Name(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status %d", p.Name(), resp.StatusCode)
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxHTMLBytes))
+	if err != nil {
+		return fmt.Errorf("%s: read: %w", p.Name(), err)
+	}
+
+	var envelope struct {
+		Response json.RawMessage `json:"subsonic-response"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil || len(envelope.Response) == 0 {
+		return fmt.Errorf("%s: bad response", p.Name())
+	}
+	var status struct {
+		Status string `json:"status"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(envelope.Response, &status); err != nil {
+		return fmt.Errorf("%s: parse: %w", p.Name(), err)
+	}
+	if status.Status != "ok" {
+		if status.Error != nil {
+			return fmt.Errorf("%s: error %d: %s", p.Name(), status.Error.Code, status.Error.Message)
+		}
+		return fmt.Errorf("%s: status %q", p.Name(), status.Status)
+	}
+	if err := json.Unmarshal(envelope.Response, out); err != nil {
+		return fmt.Errorf("%s: parse: %w", p.Name(), err)
+	}
+	return nil
+}
+
+func (p *SubsonicProvider) endpoint(name string, q url.Values) string {
+	u := *p.base
+	u.Path = strings.TrimRight(u.Path, "/") + "/rest/" + name
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func (p *SubsonicProvider) authParams() url.Values {
+	salt := subsonicSalt()
+	sum := md5.Sum([]byte(p.password + salt))
+	return url.Values{
+		"u": {p.user},
+		"t": {hex.EncodeToString(sum[:])},
+		"s": {salt},
+		"v": {subsonicAPIVersion},
+		"c": {subsonicClientName},
+	}
+}
+
+func subsonicSalt() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type subsonicSong struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Album    string `json:"album"`
+	CoverArt string `json:"coverArt"`
+	Duration int    `json:"duration"`
+	BitRate  int    `json:"bitRate"`
+	IsVideo  bool   `json:"isVideo"`
+}
@@ -0,0 +1,96 @@
+package providers
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func fakeSubsonic(t *testing.T, password string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		sum := md5.Sum([]byte(password + q.Get("s")))
+		w.Header().Set("Content-Type", "application/json")
+		if q.Get("u") != "andi" || q.Get("t") != hex.EncodeToString(sum[:]) || q.Get("s") == "" {
+			_, _ = w.Write([]byte(`{"subsonic-response":{"status":"failed","version":"1.16.1","error":{"code":40,"message":"Wrong username or password"}}}`))
+			return
+		}
+		if r.URL.Path != "/rest/search3" || q.Get("query") != "adele" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"subsonic-response":{"status":"ok","version":"1.16.1","searchResult3":{"song":[
+			{"id":"tr-1","title":"Hello","artist":"Adele","album":"25","coverArt":"al-25","duration":295,"bitRate":320},
+			{"id":"tr-2","title":"","artist":"Adele"},
+			{"id":"vid","title":"Hello (Video)","artist":"Adele","isVideo":true}
+		]}}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSubsonicSearchTokenAuth(t *testing.T) {
+	srv := fakeSubsonic(t, "s3cret")
+	p, err := NewSubsonicProvider(srv.Client(), srv.URL+"/", "andi", "s3cret", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Priority() <= NewMp3pmProvider(nil).Priority() {
+		t.Fatalf("library priority %d must beat scrapers", p.Priority())
+	}
+	got, err := p.SearchWithPage(context.Background(), "adele", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("want 1 song, got %d", len(got))
+	}
+	s := got[0].Song
+	if s.Title != "Hello" || s.Artist != "Adele" || s.Provider != "Subsonic" {
+		t.Fatalf("song: %#v", s)
+	}
+	link, err := url.Parse(s.Link)
+	if err != nil || link.Scheme != "https" || link.Path != "/rest/stream" || link.Query().Get("id") != "tr-1" {
+		t.Fatalf("stream link: %q", s.Link)
+	}
+	if len(link.Query()) != 1 || s.Image != "" {
+		t.Fatalf("link/image must carry no auth: %q %q", s.Link, s.Image)
+	}
+	if again, _ := p.SearchWithPage(context.Background(), "adele", 1); again[0].Song.Link != s.Link {
+		t.Fatalf("link not stable: %q vs %q", again[0].Song.Link, s.Link)
+	}
+
+	p.Authorize(link)
+	q := link.Query()
+	sum := md5.Sum([]byte("s3cret" + q.Get("s")))
+	if q.Get("id") != "tr-1" || q.Get("u") != "andi" || q.Get("t") != hex.EncodeToString(sum[:]) || q.Get("p") != "" {
+		t.Fatalf("authorized link: %q", link)
+	}
+	other, _ := url.Parse("https://elsewhere.example/rest/stream?id=tr-1")
+	if p.Authorize(other); other.Query().Get("t") != "" {
+		t.Fatalf("auth leaked to another host: %q", other)
+	}
+}
+
+func TestSubsonicWrongPassword(t *testing.T) {
+	srv := fakeSubsonic(t, "s3cret")
+	p, err := NewSubsonicProvider(srv.Client(), srv.URL, "andi", "nope", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.SearchWithPage(context.Background(), "adele", 1); err == nil || !strings.Contains(err.Error(), "40") {
+		t.Fatalf("want auth error, got %v", err)
+	}
+}
+
+func TestSubsonicRequiresHTTPS(t *testing.T) {
+	if _, err := NewSubsonicProvider(nil, "http://nas.local:4533", "andi", "x", 0); err == nil {
+		t.Fatal("http base must be rejected")
+	}
+}
@@ -38,12 +38,18 @@ type catalogSearcher interface {
 	AlbumSearch(ctx context.Context, query string, limit int) ([]domain.ArtistAlbum, error)
 }
 
+// trackDurations is optional on the catalog (Last.fm track.getInfo).
+type trackDurations interface {
+	TrackDuration(ctx context.Context, artist, title string) int
+}
+
 type SearchService struct {
 	providers     []ports.IMusicProvider
 	config        *domain.SearchConfig
 	searchTimeout time.Duration
 	catalog       catalogSearcher
 	covers        *CoverService
+	verifier      *LinkVerifier
 
 	cacheMu sync.Mutex
 	cache   map[string]searchCacheEntry
@@ -395,7 +401,13 @@ func (ss *SearchService) mapOne(ctx context.Context, hit CatalogHit) (domain.Son
 	if err != nil || len(songs) == 0 {
 		return domain.Song{}, false
 	}
-	song, ok := PickPlayableSong(hit.Artist, hit.Title, songs, "", searchMapPeek)
+	want := hit.Duration
+	if want == 0 && DurationsDiffer(hit.Artist, hit.Title, songs, searchMapPeek) {
+		if d, ok := ss.catalog.(trackDurations); ok {
+			want = d.TrackDuration(ctx, hit.Artist, hit.Title)
+		}
+	}
+	song, ok := PickPlayableSongNear(hit.Artist, hit.Title, want, songs, "", searchMapPeek)
 	if !ok {
 		return domain.Song{}, false
 	}
@@ -408,6 +420,14 @@ func (ss *SearchService) mapOne(ctx context.Context, hit CatalogHit) (domain.Son
 	return song, true
 }
 
+// SetVerifier enables the audio sniff stage in SearchFirst (nil disables).
+func (ss *SearchService) SetVerifier(v *LinkVerifier) {
+	if ss == nil {
+		return
+	}
+	ss.verifier = v
+}
+
 // SearchFirst fans out by priority and returns as soon as the best available
 // playable hit cannot be beaten by any still-in-flight higher-priority provider.
 func (ss *SearchService) SearchFirst(ctx context.Context, query string, limit int) ([]domain.Song, error) {
@@ -428,6 +448,7 @@ func (ss *SearchService) SearchFirst(ctx context.Context, query string, limit in
 		return providers[i].Priority() > providers[j].Priority()
 	})
 
+	parent := ctx
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -489,10 +510,10 @@ func (ss *SearchService) SearchFirst(ctx context.Context, query string, limit in
 			cancel()
 			for range ch {
 			}
-			return best, nil
+			return ss.verifier.Filter(parent, best), nil
 		}
 	}
-	return best, nil
+	return ss.verifier.Filter(parent, best), nil
 }
 
 func playableSongs(results []domain.ProviderResult, limit int) []domain.Song {
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/ports"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+)
+
+// How stale another instance's bytes may be before Allow re-reads the store.
+const streamUsageRefresh = 30 * time.Second
+
+// StreamUsage is one subject's day so far (Limit 0 = unlimited).
+type StreamUsage struct {
+	Subject   string    `json:"subject"`
+	Used      int64     `json:"usedBytes"`
+	Limit     int64     `json:"limitBytes"`
+	Remaining int64     `json:"remainingBytes"`
+	ResetsAt  time.Time `json:"resetsAt"`
+}
+
+type usageKey struct {
+	subject string
+	day     string
+}
+
+type usageSnap struct {
+	stored int64 // store total as last read (plus what we flushed since)
+	at     time.Time
+}
+
+// StreamQuota meters proxied bytes per client IP per UTC day. Reads count locally and
+// are flushed to the shared store in batches; Allow adds the store's total (other
+// instances, earlier runs) to what this instance hasn't flushed yet.
+type StreamQuota struct {
+	store ports.IStreamUsageStore // nil = this process only
+	limit int64
+	now   func() time.Time
+
+	// Flush writes hold storeMu exclusively, so a store read never sees bytes that
+	// are also still pending here.
+	storeMu sync.RWMutex
+	mu      sync.Mutex
+	pending map[usageKey]int64
+	snaps   map[usageKey]usageSnap
+}
+
+func NewStreamQuota(store ports.IStreamUsageStore, limit int64) *StreamQuota {
+	return &StreamQuota{
+		store:   store,
+		limit:   max(limit, 0),
+		now:     time.Now,
+		pending: make(map[usageKey]int64),
+		snaps:   make(map[usageKey]usageSnap),
+	}
+}
+
+// IPSubject names the client IP the bytes are charged to.
+func IPSubject(ip string) string { return "ip:" + ip }
+
+// Allow says whether subject still has quota today; when not, retryAfter is the time
+// left until the UTC day rolls over.
+func (q *StreamQuota) Allow(ctx context.Context, subject string) (retryAfter time.Duration, ok bool) {
+	u := q.Usage(ctx, subject)
+	if u.Limit == 0 || u.Used < u.Limit {
+		return 0, true
+	}
+	return u.ResetsAt.Sub(q.now()), false
+}
+
+// Usage is subject's total for today across instances.
+func (q *StreamQuota) Usage(ctx context.Context, subject string) StreamUsage {
+	now := q.now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	k := usageKey{subject, day.Format(time.DateOnly)}
+
+	q.mu.Lock()
+	snap, fresh := q.snaps[k]
+	fresh = fresh && now.Sub(snap.at) < streamUsageRefresh
+	q.mu.Unlock()
+	if !fresh && q.store != nil {
+		q.storeMu.RLock()
+		if stored, err := q.store.Usage(ctx, subject, day); err != nil {
+			utils.GetLogger().Debug("stream usage read failed", "error", err)
+		} else {
+			q.mu.Lock()
+			q.snaps[k] = usageSnap{stored: stored, at: now}
+			q.mu.Unlock()
+		}
+		q.storeMu.RUnlock()
+	}
+
+	q.mu.Lock()
+	used := q.snaps[k].stored + q.pending[k]
+	q.mu.Unlock()
+	return StreamUsage{
+		Subject:   subject,
+		Used:      used,
+		Limit:     q.limit,
+		Remaining: max(q.limit-used, 0),
+		ResetsAt:  day.Add(24 * time.Hour),
+	}
+}
+
+// Add charges n bytes to subject (safe to call per Read).
+func (q *StreamQuota) Add(subject string, n int64) {
+	if n <= 0 || subject == "" {
+		return
+	}
+	k := usageKey{subject, q.now().UTC().Format(time.DateOnly)}
+	q.mu.Lock()
+	q.pending[k] += n
+	q.mu.Unlock()
+}
+
+// Flush writes pending bytes to the store. Bytes stay pending until their write
+// commits, and move into the snapshot in the same step, so they are never counted
+// twice or dropped.
+func (q *StreamQuota) Flush(ctx context.Context) {
+	if q.store == nil {
+		return
+	}
+	q.mu.Lock()
+	batch := maps.Clone(q.pending)
+	today := q.now().UTC().Format(time.DateOnly)
+	for k := range q.snaps {
+		if k.day != today {
+			delete(q.snaps, k)
+		}
+	}
+	q.mu.Unlock()
+
+	for k, n := range batch {
+		if n <= 0 {
+			continue
+		}
+		day, _ := time.Parse(time.DateOnly, k.day)
+		q.storeMu.Lock()
+		err := q.store.AddUsage(ctx, k.subject, day, n)
+		if err == nil {
+			q.mu.Lock()
+			if q.pending[k] -= n; q.pending[k] <= 0 {
+				delete(q.pending, k)
+			}
+			if s, ok := q.snaps[k]; ok {
+				s.stored += n
+				q.snaps[k] = s
+			}
+			q.mu.Unlock()
+		}
+		q.storeMu.Unlock()
+		if err != nil {
+			utils.GetLogger().Warn("stream usage flush failed", "error", err)
+		}
+	}
+}
+
+// Run flushes every interval until ctx ends (then once more).
+func (q *StreamQuota) Run(ctx context.Context, every time.Duration) {
+	if every <= 0 {
+		every = 10 * time.Second
+	}
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			q.Flush(context.WithoutCancel(ctx))
+			return
+		case <-t.C:
+			q.Flush(ctx)
+		}
+	}
+}
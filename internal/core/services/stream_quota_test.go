@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memUsageStore struct {
+	mu    sync.Mutex
+	bytes map[string]int64
+}
+
+func (m *memUsageStore) AddUsage(_ context.Context, subject string, day time.Time, n int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bytes[subject+"|"+day.Format(time.DateOnly)] += n
+	return nil
+}
+
+func (m *memUsageStore) Usage(_ context.Context, subject string, day time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.bytes[subject+"|"+day.Format(time.DateOnly)], nil
+}
+
+func TestStreamQuotaSharedAcrossInstances(t *testing.T) {
+	ctx := context.Background()
+	store := &memUsageStore{bytes: map[string]int64{}}
+	now := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	a, b := NewStreamQuota(store, 100), NewStreamQuota(store, 100)
+	a.now, b.now = clock, clock
+	user := IPSubject("1.2.3.4")
+
+	a.Add(user, 60)
+	if _, ok := a.Allow(ctx, user); !ok {
+		t.Fatal("under the limit")
+	}
+	a.Add(user, 40)
+	retry, ok := a.Allow(ctx, user)
+	if ok || retry != 2*time.Hour {
+		t.Fatalf("local bytes count before a flush: ok=%v retry=%v", ok, retry)
+	}
+
+	if _, ok := b.Allow(ctx, user); !ok {
+		t.Fatal("b can't see a's unflushed bytes yet")
+	}
+	a.Flush(ctx)
+	now = now.Add(streamUsageRefresh) // b's snapshot goes stale
+	if _, ok := b.Allow(ctx, user); ok {
+		t.Fatal("b ignored a's flushed bytes")
+	}
+	restarted := NewStreamQuota(store, 100)
+	restarted.now = clock
+	if u := restarted.Usage(ctx, user); u.Used != 100 || u.Remaining != 0 {
+		t.Fatalf("restart lost usage: %+v", u)
+	}
+	if u := a.Usage(ctx, IPSubject("5.6.7.8")); u.Limit != 100 || u.Used != 0 {
+		t.Fatalf("other ip: %+v", u)
+	}
+
+	now = now.Add(3 * time.Hour) // next UTC day
+	if _, ok := a.Allow(ctx, user); !ok {
+		t.Fatal("quota did not reset at midnight")
+	}
+}
+
+// flakyUsageStore fails writes while down and can hold a committed write open
+// (committed is signalled, then it waits for release).
+type flakyUsageStore struct {
+	memUsageStore
+	down      bool
+	committed chan struct{}
+	release   chan struct{}
+}
+
+func (f *flakyUsageStore) AddUsage(ctx context.Context, subject string, day time.Time, n int64) error {
+	if f.down {
+		return errors.New("store down")
+	}
+	err := f.memUsageStore.AddUsage(ctx, subject, day, n)
+	if f.committed != nil {
+		f.committed <- struct{}{}
+		<-f.release
+	}
+	return err
+}
+
+func TestStreamQuotaFlushKeepsBytesWhenStoreFails(t *testing.T) {
+	ctx := context.Background()
+	store := &flakyUsageStore{memUsageStore: memUsageStore{bytes: map[string]int64{}}, down: true}
+	q := NewStreamQuota(store, 0)
+	ip := IPSubject("1.2.3.4")
+
+	q.Add(ip, 40)
+	q.Flush(ctx)
+	if u := q.Usage(ctx, ip); u.Used != 40 {
+		t.Fatalf("failed flush dropped bytes: %+v", u)
+	}
+	store.down = false
+	q.Flush(ctx)
+	q.Flush(ctx)
+	if got, _ := store.Usage(ctx, ip, q.now().UTC().Truncate(24*time.Hour)); got != 40 {
+		t.Fatalf("store has %d bytes, want 40", got)
+	}
+	if u := q.Usage(ctx, ip); u.Used != 40 {
+		t.Fatalf("after retry: %+v", u)
+	}
+}
+
+func TestStreamQuotaUsageDuringFlushCountsOnce(t *testing.T) {
+	ctx := context.Background()
+	store := &flakyUsageStore{
+		memUsageStore: memUsageStore{bytes: map[string]int64{}},
+		committed:     make(chan struct{}), release: make(chan struct{}),
+	}
+	q := NewStreamQuota(store, 0)
+	ip := IPSubject("1.2.3.4")
+	q.Add(ip, 40)
+
+	flushed := make(chan struct{})
+	go func() {
+		q.Flush(ctx)
+		close(flushed)
+	}()
+	<-store.committed // the bytes are in the store, the flush hasn't returned
+	usage := make(chan StreamUsage, 1)
+	go func() { usage <- q.Usage(ctx, ip) }() // no snapshot yet: reads the store
+	select {
+	case u := <-usage:
+		usage <- u
+	case <-time.After(50 * time.Millisecond): // the read waits for the flush
+	}
+	close(store.release)
+	<-flushed
+
+	if u := <-usage; u.Used != 40 {
+		t.Fatalf("usage racing a flush: %+v", u)
+	}
+	if u := q.Usage(ctx, ip); u.Used != 40 {
+		t.Fatalf("after flush: %+v", u)
+	}
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+// Stream URL query params added by StreamSigner.
+const (
+	StreamParamExpires = "exp"
+	StreamParamKeyID   = "kid"
+	StreamParamSig     = "sig"
+)
+
+var (
+	ErrStreamUnsigned = errors.New("stream url not signed")
+	ErrStreamExpired  = errors.New("stream url expired")
+	ErrStreamBadSig   = errors.New("stream url signature invalid")
+)
+
+type signingKey struct {
+	id     string
+	secret []byte
+}
+
+// StreamSigner mints and checks HMAC-signed /stream URLs bound to a song key (or a
+// library track id) and an expiry. The first key signs; previous
+// keys still verify until the grace window after startup ends, so URLs handed out
+// before a key rotation keep playing.
+type StreamSigner struct {
+	keys       []signingKey // [0] signs
+	graceUntil time.Time
+	ttl        time.Duration
+	base       string // "https://api.example.net" or "" for relative URLs
+	localHost  string
+	now        func() time.Time
+}
+
+// NewStreamSigner needs a current key; previous keys are accepted for grace after now.
+func NewStreamSigner(current string, previous []string, ttl, grace time.Duration, publicURL string) (*StreamSigner, error) {
+	if strings.TrimSpace(current) == "" {
+		return nil, errors.New("stream signer: empty key")
+	}
+	if ttl <= 0 {
+		ttl = 12 * time.Hour
+	}
+	s := &StreamSigner{
+		ttl:  ttl,
+		base: strings.TrimRight(strings.TrimSpace(publicURL), "/"),
+		now:  time.Now,
+	}
+	s.keys = append(s.keys, newSigningKey(current))
+	for _, p := range previous {
+		if p = strings.TrimSpace(p); p != "" && p != current {
+			s.keys = append(s.keys, newSigningKey(p))
+		}
+	}
+	s.graceUntil = s.now().Add(grace)
+	return s, nil
+}
+
+func newSigningKey(secret string) signingKey {
+	sum := sha256.Sum256([]byte(secret))
+	return signingKey{id: hex.EncodeToString(sum[:4]), secret: []byte(secret)}
+}
+
+// WithLocalHost lets SignSong re-sign library links (<host>/stream?local=<id>).
+func (s *StreamSigner) WithLocalHost(host string) *StreamSigner {
+	s.localHost = strings.ToLower(strings.TrimSpace(host))
+	return s
+}
+
+// SongSubject / LocalSubject name what a signature covers.
+func SongSubject(artist, title string) string { return "song:" + SongKey(artist, title) }
+func LocalSubject(id string) string           { return "local:" + id }
+
+// StreamURL is a signed /stream?artist=&title= link for one song.
+func (s *StreamSigner) StreamURL(artist, title string) string {
+	q := url.Values{"artist": {artist}, "title": {title}}
+	s.sign(q, SongSubject(artist, title))
+	return s.base + "/stream?" + q.Encode()
+}
+
+// LocalURL is a signed /stream?local=<id> link.
+func (s *StreamSigner) LocalURL(id string) string {
+	q := url.Values{"local": {id}}
+	s.sign(q, LocalSubject(id))
+	return s.base + "/stream?" + q.Encode()
+}
+
+func (s *StreamSigner) sign(q url.Values, subject string) {
+	exp := strconv.FormatInt(s.now().Add(s.ttl).Unix(), 10)
+	k := s.keys[0]
+	q.Set(StreamParamExpires, exp)
+	q.Set(StreamParamKeyID, k.id)
+	q.Set(StreamParamSig, mac(k.secret, subject, exp))
+}
+
+// SignSong returns a copy with StreamURL set; library links (whose Link is itself a
+// /stream URL) get a freshly signed Link too.
+func (s *StreamSigner) SignSong(song domain.Song) domain.Song {
+	if id, ok := s.localID(song.Link); ok {
+		song.Link = s.LocalURL(id)
+	}
+	if song.Artist != "" && song.Title != "" {
+		song.StreamURL = s.StreamURL(song.Artist, song.Title)
+	}
+	return song
+}
+
+// SignSongs copies songs with stream URLs (cached slices stay untouched).
+func (s *StreamSigner) SignSongs(songs []domain.Song) []domain.Song {
+	if s == nil || songs == nil {
+		return songs
+	}
+	out := make([]domain.Song, len(songs))
+	for i, song := range songs {
+		out[i] = s.SignSong(song)
+	}
+	return out
+}
+
+// SignFavorites is SignSongs for vault rows.
+func (s *StreamSigner) SignFavorites(favs []domain.FavoriteSong) []domain.FavoriteSong {
+	if s == nil || favs == nil {
+		return favs
+	}
+	out := make([]domain.FavoriteSong, len(favs))
+	for i, f := range favs {
+		if id, ok := s.localID(f.Link); ok {
+			f.Link = s.LocalURL(id)
+		}
+		if f.Artist != "" && f.Title != "" {
+			f.StreamURL = s.StreamURL(f.Artist, f.Title)
+		}
+		out[i] = f
+	}
+	return out
+}
+
+func (s *StreamSigner) localID(link string) (string, bool) {
+	if s.localHost == "" || link == "" {
+		return "", false
+	}
+	u, err := url.Parse(link)
+	if err != nil || !strings.EqualFold(u.Host, s.localHost) || !strings.HasSuffix(u.Path, "/stream") {
+		return "", false
+	}
+	id := u.Query().Get("local")
+	return id, id != ""
+}
+
+// Verify checks q's signature over subject.
+func (s *StreamSigner) Verify(subject string, q url.Values) error {
+	sig, expRaw := q.Get(StreamParamSig), q.Get(StreamParamExpires)
+	if sig == "" || expRaw == "" {
+		return ErrStreamUnsigned
+	}
+	exp, err := strconv.ParseInt(expRaw, 10, 64)
+	if err != nil {
+		return ErrStreamBadSig
+	}
+	kid := q.Get(StreamParamKeyID)
+	now := s.now()
+	for i, k := range s.keys {
+		if kid != "" && kid != k.id {
+			continue
+		}
+		if i > 0 && now.After(s.graceUntil) {
+			continue // rotated out
+		}
+		if hmac.Equal([]byte(sig), []byte(mac(k.secret, subject, expRaw))) {
+			if now.Unix() > exp {
+				return ErrStreamExpired
+			}
+			return nil
+		}
+	}
+	return ErrStreamBadSig
+}
+
+func mac(secret []byte, subject, exp string) string {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte("v1\n" + subject + "\n" + exp))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil))
+}
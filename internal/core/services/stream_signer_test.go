@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+func signedQuery(t *testing.T, raw string) url.Values {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Query()
+}
+
+func TestStreamSignerRoundTrip(t *testing.T) {
+	s, err := NewStreamSigner("k1", nil, time.Hour, time.Hour, "https://api.example.net/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := s.StreamURL("Nero", "Promises")
+	if !strings.HasPrefix(raw, "https://api.example.net/stream?") {
+		t.Fatalf("url %q", raw)
+	}
+	q := signedQuery(t, raw)
+	if err := s.Verify(SongSubject("Nero", "Promises (Original Mix)"), q); err != nil {
+		t.Fatalf("verify: %v", err) // subject is the song key — remix suffix collapses
+	}
+	if err := s.Verify(SongSubject("Nero", "Innocence"), q); !errors.Is(err, ErrStreamBadSig) {
+		t.Fatalf("other song: %v", err)
+	}
+	q.Set(StreamParamExpires, "9999999999")
+	if err := s.Verify(SongSubject("Nero", "Promises"), q); !errors.Is(err, ErrStreamBadSig) {
+		t.Fatalf("extended expiry: %v", err)
+	}
+	if err := s.Verify(SongSubject("Nero", "Promises"), url.Values{}); !errors.Is(err, ErrStreamUnsigned) {
+		t.Fatalf("unsigned: %v", err)
+	}
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if err := s.Verify(SongSubject("Nero", "Promises"), signedQuery(t, raw)); !errors.Is(err, ErrStreamExpired) {
+		t.Fatalf("expired: %v", err)
+	}
+}
+
+func TestStreamSignerRotationGrace(t *testing.T) {
+	old, _ := NewStreamSigner("old", nil, 24*time.Hour, 0, "")
+	raw := old.StreamURL("Nero", "Promises")
+
+	rotated, _ := NewStreamSigner("new", []string{"old"}, 24*time.Hour, time.Hour, "")
+	if err := rotated.Verify(SongSubject("Nero", "Promises"), signedQuery(t, raw)); err != nil {
+		t.Fatalf("previous key within grace: %v", err)
+	}
+	rotated.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if err := rotated.Verify(SongSubject("Nero", "Promises"), signedQuery(t, raw)); !errors.Is(err, ErrStreamBadSig) {
+		t.Fatalf("previous key after grace: %v", err)
+	}
+}
+
+func TestSignSongsResignsLocalLinks(t *testing.T) {
+	s, _ := NewStreamSigner("k", nil, time.Hour, 0, "https://vibe.example.net")
+	s.WithLocalHost("vibe.example.net")
+	in := []domain.Song{
+		{Artist: "Nero", Title: "Promises", Link: "https://cs1.mp3.pm/a.mp3"},
+		{Artist: "Me", Title: "Demo", Link: "https://vibe.example.net/stream?local=abc"},
+	}
+	out := s.SignSongs(in)
+	if in[0].StreamURL != "" {
+		t.Fatal("input slice mutated")
+	}
+	if out[0].Link != in[0].Link || out[0].StreamURL == "" {
+		t.Fatalf("remote song: %+v", out[0])
+	}
+	if err := s.Verify(LocalSubject("abc"), signedQuery(t, out[1].Link)); err != nil {
+		t.Fatalf("local link not re-signed: %q %v", out[1].Link, err)
+	}
+}
@@ -0,0 +1,506 @@
+// Package streamcache keeps proxied audio on disk for /stream.
+//
+// Files are filled sparsely: each entry records which byte spans are on disk, so a
+// Range request reads whatever is cached and only the holes go upstream. A hole is
+// fetched once — every reader waiting on it shares that fill — and the least recently
+// used idle entries are evicted once the cache passes its byte cap.
+package streamcache
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+)
+
+const (
+	// MaxEntryBytes matches the proxy's hard cap — bigger bodies are streamed, not cached.
+	MaxEntryBytes = 80 << 20
+	fillTimeout   = 5 * time.Minute
+	// A reader this far behind an active fill's cursor starts its own fill instead of waiting.
+	maxFillLag = 1 << 20
+	chunkSize  = 32 << 10
+)
+
+// ErrNotCacheable — upstream sent no usable length (or too large); stream it directly.
+var ErrNotCacheable = errors.New("streamcache: response not cacheable")
+
+// Opener fetches the upstream link with the given Range header ("" = whole file).
+type Opener func(ctx context.Context, rangeHeader string) (*http.Response, error)
+
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	entries map[string]*Entry
+	used    int64
+}
+
+// Entry is one cached track. Hold it between Acquire and Release.
+type Entry struct {
+	c    *Cache
+	key  string
+	open Opener
+
+	// Guarded by c.mu.
+	size    int64 // -1 until the first upstream response
+	ctype   string
+	have    spans
+	fills   []*fill
+	err     error
+	ready   chan struct{} // closed once size is known (or the first fill failed)
+	changed chan struct{} // closed and replaced on every fill step
+	refs    int
+	lastUse time.Time
+	file    *os.File
+	gone    bool
+}
+
+type fill struct {
+	pos  int64 // next byte to write
+	stop int64 // exclusive; shrinks when it runs into cached bytes
+	done bool
+	err  error
+}
+
+type meta struct {
+	Size  int64  `json:"size"`
+	Type  string `json:"type"`
+	Spans spans  `json:"spans"`
+}
+
+// Key names a cache entry: the song plus the exact link (a re-resolved link is a new file).
+func Key(songKey, link string) string {
+	sum := sha1.Sum([]byte(songKey + "\x00" + link))
+	return hex.EncodeToString(sum[:])
+}
+
+// New opens (creating if needed) dir and re-indexes entries left by a previous run.
+func New(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("streamcache: %w", err)
+	}
+	c := &Cache{dir: dir, maxBytes: maxBytes, entries: make(map[string]*Entry)}
+	c.load()
+	return c, nil
+}
+
+func (c *Cache) load() {
+	paths, _ := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	for _, p := range paths {
+		key := strings.TrimSuffix(filepath.Base(p), ".json")
+		raw, err := os.ReadFile(p)
+		st, statErr := os.Stat(c.audioPath(key))
+		var m meta
+		if err != nil || statErr != nil || json.Unmarshal(raw, &m) != nil || m.Size <= 0 {
+			_ = os.Remove(p)
+			_ = os.Remove(c.audioPath(key))
+			continue
+		}
+		e := c.newEntry(key, nil)
+		e.size, e.ctype, e.have = m.Size, m.Type, m.Spans
+		e.lastUse = st.ModTime()
+		close(e.ready)
+		c.entries[key] = e
+		c.used += e.have.total()
+	}
+	// Orphaned audio (crash before a sidecar was written) can't be trusted.
+	audio, _ := filepath.Glob(filepath.Join(c.dir, "*.audio"))
+	for _, p := range audio {
+		if _, ok := c.entries[strings.TrimSuffix(filepath.Base(p), ".audio")]; !ok {
+			_ = os.Remove(p)
+		}
+	}
+	c.mu.Lock()
+	c.evictLocked()
+	c.mu.Unlock()
+	if len(c.entries) > 0 {
+		utils.GetLogger().Info("stream cache restored", "entries", len(c.entries), "bytes", c.used)
+	}
+}
+
+func (c *Cache) newEntry(key string, open Opener) *Entry {
+	return &Entry{
+		c:       c,
+		key:     key,
+		open:    open,
+		size:    -1,
+		ready:   make(chan struct{}),
+		changed: make(chan struct{}),
+	}
+}
+
+func (c *Cache) audioPath(key string) string { return filepath.Join(c.dir, key+".audio") }
+func (c *Cache) metaPath(key string) string  { return filepath.Join(c.dir, key+".json") }
+
+// Acquire returns the entry for key once its size is known, starting an upstream fill
+// at offset start on a miss. Concurrent callers for the same key share that fill.
+func (c *Cache) Acquire(ctx context.Context, key string, start int64, open Opener) (*Entry, error) {
+	c.mu.Lock()
+	e := c.entries[key]
+	if e == nil {
+		e = c.newEntry(key, open)
+		c.entries[key] = e
+	}
+	if e.file == nil {
+		f, err := os.OpenFile(c.audioPath(key), os.O_RDWR|os.O_CREATE, 0o600)
+		if err != nil {
+			delete(c.entries, key)
+			c.mu.Unlock()
+			return nil, fmt.Errorf("streamcache: %w", err)
+		}
+		e.file = f
+	}
+	e.open = open
+	e.refs++
+	e.lastUse = time.Now()
+	if e.size < 0 && len(e.fills) == 0 {
+		e.startFillLocked(max(start, 0))
+	}
+	ready := e.ready
+	c.mu.Unlock()
+
+	select {
+	case <-ready:
+	case <-ctx.Done():
+		e.Release()
+		return nil, ctx.Err()
+	}
+	c.mu.Lock()
+	err := e.err
+	c.mu.Unlock()
+	if err != nil {
+		e.Release()
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *Entry) Size() int64 {
+	e.c.mu.Lock()
+	defer e.c.mu.Unlock()
+	return e.size
+}
+
+func (e *Entry) ContentType() string {
+	e.c.mu.Lock()
+	defer e.c.mu.Unlock()
+	return e.ctype
+}
+
+// Release drops this hold; idle entries become evictable.
+func (e *Entry) Release() {
+	c := e.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.refs--
+	e.lastUse = time.Now()
+	e.idleLocked()
+	c.evictLocked()
+}
+
+// NewReader reads bytes [start, end] (inclusive), waiting on — or starting — fills for holes.
+func (e *Entry) NewReader(start, end int64) io.Reader {
+	return &reader{e: e, pos: start, end: end}
+}
+
+type reader struct {
+	e        *Entry
+	pos, end int64
+	waiting  *fill
+	own      bool // waiting was started by this reader
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	e, c := r.e, r.e.c
+	for {
+		c.mu.Lock()
+		if r.pos > r.end || (e.size >= 0 && r.pos >= e.size) {
+			c.mu.Unlock()
+			return 0, io.EOF
+		}
+		if n := e.have.covered(r.pos); n > 0 {
+			want := min(int64(len(p)), n, r.end+1-r.pos)
+			f := e.file
+			c.mu.Unlock()
+			k, err := f.ReadAt(p[:want], r.pos)
+			r.pos += int64(k)
+			r.waiting = nil
+			if errors.Is(err, io.EOF) && k > 0 {
+				err = nil
+			}
+			return k, err
+		}
+		if r.waiting != nil && r.waiting.done && r.waiting.err != nil {
+			if r.own {
+				// Our own fill failed before reaching our offset.
+				err := r.waiting.err
+				c.mu.Unlock()
+				return 0, err
+			}
+			// A shared fill failing is someone else's problem — try our own.
+			r.waiting = nil
+		}
+		if e.gone {
+			c.mu.Unlock()
+			return 0, io.ErrUnexpectedEOF
+		}
+		if r.waiting == nil || r.waiting.done {
+			r.waiting, r.own = e.fillForLocked(r.pos), false
+			if r.waiting == nil {
+				r.waiting, r.own = e.startFillLocked(r.pos), true
+			}
+		}
+		ch := e.changed
+		c.mu.Unlock()
+		<-ch // fills always finish (fillTimeout), so this can't hang forever
+	}
+}
+
+// fillForLocked finds an active fill that will reach off soon.
+func (e *Entry) fillForLocked(off int64) *fill {
+	for _, f := range e.fills {
+		if !f.done && f.pos <= off && off < f.stop && off-f.pos <= maxFillLag {
+			return f
+		}
+	}
+	return nil
+}
+
+func (e *Entry) startFillLocked(off int64) *fill {
+	stop := int64(-1)
+	if e.size >= 0 {
+		stop = e.have.nextStart(off, e.size)
+	}
+	f := &fill{pos: off, stop: stop}
+	e.fills = append(e.fills, f)
+	go e.run(f)
+	return f
+}
+
+func (e *Entry) notifyLocked() {
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+func (e *Entry) run(f *fill) {
+	ctx, cancel := context.WithTimeout(context.Background(), fillTimeout)
+	defer cancel()
+	err := e.fetch(ctx, f)
+
+	c := e.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f.done, f.err = true, err
+	for i, cur := range e.fills {
+		if cur == f {
+			e.fills = append(e.fills[:i], e.fills[i+1:]...)
+			break
+		}
+	}
+	if e.size < 0 {
+		// Never learned the size — drop the entry (and its empty file, which the byte
+		// budget never saw) so the next request retries cleanly.
+		if err == nil {
+			err = ErrNotCacheable
+		}
+		e.err = err
+		close(e.ready)
+		if c.entries[e.key] == e {
+			delete(c.entries, e.key)
+			_ = os.Remove(c.audioPath(e.key))
+		}
+		e.gone = true
+	}
+	e.notifyLocked()
+	e.idleLocked()
+	c.evictLocked()
+}
+
+func (e *Entry) fetch(ctx context.Context, f *fill) error {
+	c := e.c
+	c.mu.Lock()
+	rng := "bytes=" + strconv.FormatInt(f.pos, 10) + "-"
+	if f.stop >= 0 {
+		rng += strconv.FormatInt(f.stop-1, 10)
+	}
+	open := e.open
+	c.mu.Unlock()
+
+	resp, err := open(ctx, rng)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var total int64
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		first, _, size, ok := ParseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || first != f.pos {
+			return ErrNotCacheable
+		}
+		total = size
+	case http.StatusOK:
+		// Range ignored — skip ahead to our offset.
+		total = resp.ContentLength
+		if f.pos > 0 {
+			if _, err := io.CopyN(io.Discard, resp.Body, f.pos); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("streamcache: upstream status %d", resp.StatusCode)
+	}
+
+	c.mu.Lock()
+	switch {
+	case e.size < 0:
+		if total <= 0 || total > MaxEntryBytes {
+			c.mu.Unlock()
+			return ErrNotCacheable
+		}
+		e.size = total
+		e.ctype = resp.Header.Get("Content-Type")
+		close(e.ready)
+	case total != e.size:
+		c.mu.Unlock()
+		return fmt.Errorf("streamcache: upstream size changed (%d → %d)", e.size, total)
+	}
+	if f.stop < 0 {
+		f.stop = e.have.nextStart(f.pos, e.size)
+	}
+	file := e.file
+	c.mu.Unlock()
+
+	buf := make([]byte, chunkSize)
+	for {
+		c.mu.Lock()
+		remaining := f.stop - f.pos
+		c.mu.Unlock()
+		if remaining <= 0 {
+			return nil
+		}
+		n, rerr := resp.Body.Read(buf[:min(int64(len(buf)), remaining)])
+		if n > 0 {
+			if _, err := file.WriteAt(buf[:n], f.pos); err != nil {
+				return err
+			}
+			c.mu.Lock()
+			var added int64
+			e.have, added = e.have.add(f.pos, f.pos+int64(n))
+			c.used += added
+			f.pos += int64(n)
+			f.stop = e.have.nextStartAfterFill(f.pos, f.stop)
+			e.notifyLocked()
+			c.mu.Unlock()
+		}
+		if rerr == io.EOF {
+			c.mu.Lock()
+			short := f.pos < f.stop
+			c.mu.Unlock()
+			if short {
+				return io.ErrUnexpectedEOF
+			}
+			return nil
+		}
+		if rerr != nil {
+			return rerr
+		}
+	}
+}
+
+// nextStartAfterFill shrinks a fill's stop when another fill already covered what lies ahead.
+func (s spans) nextStartAfterFill(pos, stop int64) int64 {
+	if s.covered(pos) > 0 {
+		return pos // ran into cached bytes
+	}
+	return s.nextStart(pos, stop)
+}
+
+// idleLocked closes the file handle and writes the sidecar once nobody uses the entry.
+func (e *Entry) idleLocked() {
+	if e.refs > 0 || len(e.fills) > 0 || e.file == nil {
+		return
+	}
+	_ = e.file.Close()
+	e.file = nil
+	if e.gone {
+		// Evicted (files already removed) or never sized (nothing written); a newer
+		// entry may own the path by now, so leave it alone.
+		return
+	}
+	raw, err := json.Marshal(meta{Size: e.size, Type: e.ctype, Spans: e.have})
+	if err == nil {
+		err = os.WriteFile(e.c.metaPath(e.key), raw, 0o600)
+	}
+	if err != nil {
+		utils.GetLogger().Warn("stream cache sidecar write failed", "error", err)
+	}
+}
+
+// evictLocked drops least recently used idle entries until the cache fits its cap.
+func (c *Cache) evictLocked() {
+	for c.used > c.maxBytes {
+		var victim *Entry
+		for _, e := range c.entries {
+			if e.refs > 0 || len(e.fills) > 0 {
+				continue
+			}
+			if victim == nil || e.lastUse.Before(victim.lastUse) {
+				victim = e
+			}
+		}
+		if victim == nil {
+			return // everything is in use — over cap until something is released
+		}
+		delete(c.entries, victim.key)
+		victim.gone = true
+		c.used -= victim.have.total()
+		_ = os.Remove(c.audioPath(victim.key))
+		_ = os.Remove(c.metaPath(victim.key))
+	}
+}
+
+// Used reports cached bytes on disk.
+func (c *Cache) Used() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.used
+}
+
+// ParseContentRange reads "bytes first-last/total" (a "*" total is not ok).
+func ParseContentRange(v string) (first, last, total int64, ok bool) {
+	v = strings.TrimSpace(v)
+	if !strings.HasPrefix(v, "bytes ") {
+		return 0, 0, 0, false
+	}
+	rng, size, found := strings.Cut(strings.TrimPrefix(v, "bytes "), "/")
+	if !found {
+		return 0, 0, 0, false
+	}
+	a, b, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, 0, false
+	}
+	first, err1 := strconv.ParseInt(a, 10, 64)
+	last, err2 := strconv.ParseInt(b, 10, 64)
+	total, err3 := strconv.ParseInt(size, 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil || last < first {
+		return 0, 0, 0, false
+	}
+	return first, last, total, true
+}
@@ -0,0 +1,75 @@
+package streamcache
+
+import "sort"
+
+// span is a half-open byte interval [Start, End) that is on disk.
+type span struct {
+	Start int64 `json:"s"`
+	End   int64 `json:"e"`
+}
+
+// spans is sorted, non-overlapping and non-adjacent (merged on add).
+type spans []span
+
+// covered is how many bytes from off onward are on disk (0 = off is a hole).
+func (s spans) covered(off int64) int64 {
+	i := sort.Search(len(s), func(i int) bool { return s[i].End > off })
+	if i < len(s) && s[i].Start <= off {
+		return s[i].End - off
+	}
+	return 0
+}
+
+// nextStart is the first cached byte after off, or limit when none comes sooner.
+func (s spans) nextStart(off, limit int64) int64 {
+	i := sort.Search(len(s), func(i int) bool { return s[i].End > off })
+	if i < len(s) && s[i].Start < limit {
+		if s[i].Start <= off {
+			return off
+		}
+		return s[i].Start
+	}
+	return limit
+}
+
+// add merges [start, end) in and returns how many bytes were new.
+func (s spans) add(start, end int64) (spans, int64) {
+	if end <= start {
+		return s, 0
+	}
+	before := s.total()
+	out := make(spans, 0, len(s)+1)
+	merged := span{start, end}
+	placed := false
+	for _, cur := range s {
+		switch {
+		case cur.End < merged.Start:
+			out = append(out, cur)
+		case cur.Start > merged.End:
+			if !placed {
+				out = append(out, merged)
+				placed = true
+			}
+			out = append(out, cur)
+		default:
+			merged.Start = min(merged.Start, cur.Start)
+			merged.End = max(merged.End, cur.End)
+		}
+	}
+	if !placed {
+		out = append(out, merged)
+	}
+	return out, out.total() - before
+}
+
+func (s spans) total() int64 {
+	var n int64
+	for _, sp := range s {
+		n += sp.End - sp.Start
+	}
+	return n
+}
+
+func (s spans) complete(size int64) bool {
+	return size > 0 && len(s) == 1 && s[0].Start == 0 && s[0].End >= size
+}
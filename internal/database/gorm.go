@@ -59,6 +59,88 @@ func migrate(db *gorm.DB) {
 		`CREATE INDEX IF NOT EXISTS idx_favorite_songs_user_order ON favorite_songs(user_uuid, "order")`,
 		`CREATE INDEX IF NOT EXISTS idx_favorite_songs_created_at ON favorite_songs(created_at)`,
 		`ALTER TABLE favorite_songs ADD COLUMN IF NOT EXISTS lyrics TEXT`,
+		`ALTER TABLE favorite_songs ADD COLUMN IF NOT EXISTS play_count INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE favorite_songs ADD COLUMN IF NOT EXISTS last_played_at TIMESTAMP WITH TIME ZONE`,
+		`CREATE TABLE IF NOT EXISTS library_tracks (
+			id VARCHAR(64) PRIMARY KEY,
+			path TEXT NOT NULL UNIQUE,
+			artist VARCHAR(500) NOT NULL,
+			title VARCHAR(500) NOT NULL,
+			album VARCHAR(500),
+			duration_sec INTEGER NOT NULL DEFAULT 0,
+			size BIGINT NOT NULL DEFAULT 0,
+			mod_time TIMESTAMP WITH TIME ZONE,
+			search_text TEXT,
+			scanned_at TIMESTAMP WITH TIME ZONE
+		)`,
+		`CREATE TABLE IF NOT EXISTS provider_rate_buckets (
+			provider VARCHAR(64) PRIMARY KEY,
+			tokens DOUBLE PRECISION NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS stream_usage (
+			subject VARCHAR(300) NOT NULL,
+			day DATE NOT NULL,
+			bytes BIGINT NOT NULL DEFAULT 0,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (subject, day)
+		)`,
+		`CREATE TABLE IF NOT EXISTS radio_feedback (
+			user_uuid VARCHAR(255) NOT NULL,
+			song_key VARCHAR(1000) NOT NULL,
+			artist VARCHAR(500) NOT NULL,
+			title VARCHAR(500) NOT NULL,
+			verdict SMALLINT NOT NULL DEFAULT 0,
+			skips INTEGER NOT NULL DEFAULT 0,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_uuid, song_key),
+			CONSTRAINT fk_radio_feedback_user FOREIGN KEY (user_uuid) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_radio_feedback_user_updated ON radio_feedback(user_uuid, updated_at DESC)`,
+		`CREATE TABLE IF NOT EXISTS song_cosaves (
+			song_key VARCHAR(1000) NOT NULL,
+			other_key VARCHAR(1000) NOT NULL,
+			artist VARCHAR(500) NOT NULL,
+			title VARCHAR(500) NOT NULL,
+			users INTEGER NOT NULL,
+			score DOUBLE PRECISION NOT NULL,
+			PRIMARY KEY (song_key, other_key)
+		)`,
+		`CREATE TABLE IF NOT EXISTS job_leases (
+			job VARCHAR(64) PRIMARY KEY,
+			until TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS daily_mixes (
+			user_uuid VARCHAR(255) NOT NULL,
+			day DATE NOT NULL,
+			idx SMALLINT NOT NULL,
+			title VARCHAR(255) NOT NULL,
+			subtitle VARCHAR(500) NOT NULL DEFAULT '',
+			songs JSONB NOT NULL,
+			refreshes INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_uuid, day, idx),
+			CONSTRAINT fk_daily_mixes_user FOREIGN KEY (user_uuid) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS explore_shelves (
+			shelf_key VARCHAR(255) PRIMARY KEY,
+			title VARCHAR(255) NOT NULL,
+			subtitle VARCHAR(500) NOT NULL DEFAULT '',
+			songs JSONB NOT NULL,
+			built_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS scrape_cookies (
+			egress VARCHAR(500) NOT NULL,
+			host VARCHAR(255) NOT NULL,
+			domain VARCHAR(255) NOT NULL DEFAULT '',
+			path VARCHAR(500) NOT NULL DEFAULT '',
+			name VARCHAR(255) NOT NULL,
+			value TEXT NOT NULL,
+			expires TIMESTAMP WITH TIME ZONE NOT NULL,
+			secure BOOLEAN NOT NULL DEFAULT FALSE,
+			http_only BOOLEAN NOT NULL DEFAULT FALSE,
+			PRIMARY KEY (egress, host, domain, path, name)
+		)`,
 		`CREATE OR REPLACE FUNCTION update_updated_at_column()
 		RETURNS TRIGGER AS $$
 		BEGIN
@@ -1,13 +1,20 @@
 package di
 
 import (
+	"context"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/andiq123/FindVibeFiber/internal/config"
 	"github.com/andiq123/FindVibeFiber/internal/core/domain"
 	"github.com/andiq123/FindVibeFiber/internal/core/ports"
 	"github.com/andiq123/FindVibeFiber/internal/core/services"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/charts"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/library"
 	"github.com/andiq123/FindVibeFiber/internal/core/services/providers"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/streamcache"
 	"github.com/andiq123/FindVibeFiber/internal/handlers"
 	"github.com/andiq123/FindVibeFiber/internal/repository"
 	"github.com/andiq123/FindVibeFiber/internal/utils"
@@ -24,6 +31,7 @@ type Handlers struct {
 	Recommend   *handlers.RecommendHandler
 	Lyrics      *handlers.LyricsHandler
 	Spotify     *handlers.SpotifyHandler
+	Admin       *handlers.AdminHandler
 }
 
 func InitializeHandlers(db *gorm.DB, cfg *config.AppConfig) Handlers {
@@ -45,10 +53,58 @@ func InitializeHandlers(db *gorm.DB, cfg *config.AppConfig) Handlers {
 		cfg.HTTP.IdleTimeout,
 		utils.ParseProxyList(cfg.HTTP.ProviderProxies),
 	)
-	mp3pm := providers.NewMp3pmProvider(scrape.Client).UseRotator(scrape)
-	mp3mn := providers.NewMp3mnProvider(scrape.Client)
-	mp3mn.WithRotator(scrape)
-	musify := providers.NewMusifyProvider(scrape.Client).UseRotator(scrape)
+	// Restore the jar before forking so every provider client shares it per egress.
+	persistScrapeCookies(db, scrape, cfg.HTTP)
+	// One pool per provider (PROVIDER_PROXIES_<NAME>, else a copy of the shared list):
+	// a proxy Musify blocks keeps serving Mp3pm, and each rotator moves only its own egress.
+	mp3pmScrape := scrape.Fork("Mp3pm", utils.ParseProxyList(cfg.HTTP.ProviderPools["mp3pm"]))
+	mp3mnScrape := scrape.Fork("Mp3mn", utils.ParseProxyList(cfg.HTTP.ProviderPools["mp3mn"]))
+	musifyScrape := scrape.Fork("Musify", utils.ParseProxyList(cfg.HTTP.ProviderPools["musify"]))
+	scrapeClients := []*utils.ScrapeClient{scrape, mp3pmScrape, mp3mnScrape, musifyScrape}
+
+	mp3pm := providers.NewMp3pmProvider(mp3pmScrape.Client).UseRotator(mp3pmScrape)
+	mp3mn := providers.NewMp3mnProvider(mp3mnScrape.Client)
+	mp3mn.WithRotator(mp3mnScrape)
+	musify := providers.NewMusifyProvider(musifyScrape.Client).UseRotator(musifyScrape)
+
+	configureProviderLimits(db, cfg.Limits)
+	if cfg.HTTP.Warmup {
+		go providers.WarmUp(context.Background(), mp3pm, mp3mn, musify)
+	}
+	for _, sc := range scrapeClients {
+		go sc.Pool.RunProber(context.Background(), cfg.HTTP.ProxyProbeURL, cfg.HTTP.ProxyProbeEvery, 10*time.Second)
+	}
+
+	musicProviders := []ports.IMusicProvider{mp3pm, mp3mn, musify}
+	var subsonic *providers.SubsonicProvider
+	if cfg.Subsonic.URL != "" {
+		// Home library: plain client — never route it through the scrape proxies.
+		sp, err := providers.NewSubsonicProvider(httpClient, cfg.Subsonic.URL, cfg.Subsonic.User, cfg.Subsonic.Password, cfg.Subsonic.Priority)
+		if err != nil {
+			utils.GetLogger().Warn("subsonic provider disabled", "error", err)
+		} else {
+			subsonic = sp
+			musicProviders = append(musicProviders, sp)
+		}
+	}
+
+	var localLib *library.Library
+	var localProvider *providers.LocalLibraryProvider
+	if cfg.Library.Dir != "" {
+		libraryRepository := repository.NewLibraryRepository(db)
+		lib, err := library.New(cfg.Library.Dir, libraryRepository)
+		if err == nil {
+			localProvider, err = providers.NewLocalLibraryProvider(libraryRepository, cfg.Library.PublicURL, cfg.Library.Priority)
+		}
+		if err != nil {
+			utils.GetLogger().Warn("local library disabled", "error", err)
+		} else {
+			localLib = lib
+			musicProviders = append(musicProviders, localProvider)
+			// Process-lifetime re-scan loop; unchanged files are skipped by size+mtime.
+			go lib.Run(context.Background(), cfg.Library.Rescan)
+		}
+	}
 
 	searchConfig := domain.DefaultSearchConfig()
 	searchConfig.MaxResults = cfg.Search.MaxResults
@@ -57,22 +113,135 @@ func InitializeHandlers(db *gorm.DB, cfg *config.AppConfig) Handlers {
 	covers := services.NewCoverService(httpClient, lastfmKey)
 	catalog := services.NewLastFMCatalog(httpClient, lastfmKey)
 	searchSvc := services.NewSearchService(
-		[]ports.IMusicProvider{mp3pm, mp3mn, musify},
+		musicProviders,
 		searchConfig,
 		cfg.Search.Timeout,
 		catalog,
 	)
 	searchSvc.SetCovers(covers)
+	if cfg.Search.VerifyLinks {
+		decorate := handlers.StreamUpstreamHeaders
+		if subsonic != nil {
+			// Library links are bare; probes need the same auth /stream adds.
+			decorate = func(req *http.Request) {
+				subsonic.Authorize(req.URL)
+				handlers.StreamUpstreamHeaders(req)
+			}
+		}
+		searchSvc.SetVerifier(services.NewLinkVerifier(scrape.Client, cfg.Search.VerifyBudget, cfg.Search.VerifyPeek, decorate))
+	}
+
+	recommend := handlers.NewRecommendHandlerUpstream(httpClient, scrape.Client, lastfmKey, searchSvc, covers)
+	if subsonic != nil {
+		recommend.AuthorizeStreamHost(subsonic.Host(), subsonic.Authorize)
+	}
+	if localLib != nil {
+		recommend.UseLibrary(localLib, localProvider.Host())
+	}
+	searchHandler := handlers.NewSearchHandler(searchSvc, covers)
+	favoritesService := services.NewFavoritesService(favoritesRepository, authRepository)
+	favoritesHandler := handlers.NewFavoritesHandler(favoritesService)
+	if cfg.Stream.SigningKey != "" {
+		signer, err := services.NewStreamSigner(cfg.Stream.SigningKey, cfg.Stream.PreviousKeys, cfg.Stream.URLTTL, cfg.Stream.SigningGrace, cfg.Stream.PublicURL)
+		if err != nil {
+			utils.GetLogger().Warn("stream signing disabled", "error", err)
+		} else {
+			if localLib != nil {
+				signer.WithLocalHost(localProvider.Host())
+			}
+			recommend.UseStreamSigner(signer)
+			searchHandler.UseStreamSigner(signer)
+			favoritesHandler.UseStreamSigner(signer)
+		}
+	}
+	if cfg.Stream.CacheDir != "" {
+		if cache, err := streamcache.New(cfg.Stream.CacheDir, cfg.Stream.CacheBytes); err != nil {
+			utils.GetLogger().Warn("stream cache disabled", "error", err)
+		} else {
+			recommend.UseStreamCache(cache)
+		}
+	}
+	// Byte counters live in Postgres so quotas hold across restarts and instances.
+	quota := services.NewStreamQuota(repository.NewStreamUsageRepository(db), cfg.Stream.QuotaIPBytes)
+	go quota.Run(context.Background(), cfg.Stream.QuotaFlush)
+	recommend.UseStreamQuota(quota)
+	lyrics := handlers.NewLyricsHandler(httpClient)
+	recommend.UseLyrics(lyrics)
+	spotify := handlers.NewSpotifyHandler(httpClient)
+	recommend.UseVault(favoritesService)
+	recommend.UseFeedback(services.NewFeedbackService(repository.NewFeedbackRepository(db), authRepository))
+	recommend.UseArchives(spotify, cfg.Stream.ZipMaxTracks)
+	recommend.UseChartSources(charts.Defaults(httpClient, lastfmKey))
+	recommend.UseExplore(cfg.Explore.Catalog, cfg.Explore.Country)
+	// Serve the last good charts right after boot; the refresher rebuilds them ahead of expiry.
+	if n, err := recommend.UseExploreStore(context.Background(), repository.NewExploreRepository(db)); err != nil {
+		utils.GetLogger().Warn("explore shelves not restored", "error", err)
+	} else {
+		utils.GetLogger().Info("explore shelves restored", "shelves", n)
+	}
+	go recommend.RunExplore(context.Background(), cfg.Explore.Refresh)
+	recommend.UseArtistRadio(cfg.Radio.ArtistOwn, cfg.Radio.ArtistSimilar, cfg.Radio.ArtistMaxRun)
+	jobLease := repository.NewJobLeaseRepository(db)
+	coSaves := services.NewCoSaveService(repository.NewCoSaveRepository(db), cfg.Radio.CoSaveMinUsers)
+	coSaves.UseLease(jobLease)
+	go coSaves.Run(context.Background(), cfg.Radio.CoSaveRebuild)
+	recommend.UseCoSaves(coSaves)
+	mixes := services.NewDailyMixService(repository.NewDailyMixRepository(db), recommend.BuildDailyMixes, cfg.Radio.DailyMixRefreshes)
+	mixes.UseLease(jobLease)
+	go mixes.Run(context.Background(), cfg.Radio.DailyMixCheck)
+	recommend.UseDailyMixes(mixes)
 
 	return Handlers{
-		Health:      handlers.NewHealthHandler(scrape.Client),
+		Health:      handlers.NewHealthHandler(scrape.Client).WithLimiters(providers.Limiters()),
 		Auth:        handlers.NewAuthHandler(services.NewAuthService(authRepository)),
-		Favorites:   handlers.NewFavoritesHandler(services.NewFavoritesService(favoritesRepository, authRepository)),
+		Favorites:   favoritesHandler,
 		Suggestions: handlers.NewSuggestionsHandler(services.NewSuggestionsService(httpClient)),
 		Cover:       handlers.NewCoverHandler(covers),
-		Search:      handlers.NewSearchHandler(searchSvc, covers),
-		Recommend:   handlers.NewRecommendHandlerUpstream(httpClient, scrape.Client, lastfmKey, searchSvc, covers),
-		Lyrics:      handlers.NewLyricsHandler(httpClient),
-		Spotify:     handlers.NewSpotifyHandler(httpClient),
+		Search:      searchHandler,
+		Recommend:   recommend,
+		Lyrics:      lyrics,
+		Spotify:     spotify,
+		Admin: handlers.NewAdminHandler(scrape.Pool, map[string]*utils.ProxyPool{
+			"mp3pm":  mp3pmScrape.Pool,
+			"mp3mn":  mp3mnScrape.Pool,
+			"musify": musifyScrape.Pool,
+		}),
+	}
+}
+
+// configureProviderLimits applies PROVIDER_RATE_LIMITS overrides and, with
+// PROVIDER_RATE_SHARED=1, the Postgres budget shared across instances.
+func configureProviderLimits(db *gorm.DB, cfg config.RateLimitConfig) {
+	var shared ports.ISharedRateBudget
+	if cfg.Shared {
+		shared = repository.NewRateBudgetRepository(db)
+	}
+	for _, l := range providers.Limiters() {
+		if o, ok := cfg.Providers[strings.ToLower(l.Name())]; ok {
+			l.Configure(o.Rate, o.Burst)
+		}
+		l.UseShared(shared)
+	}
+}
+
+// persistScrapeCookies restores the scrape jar from SCRAPE_COOKIE_STORE and flushes it every minute.
+func persistScrapeCookies(db *gorm.DB, scrape *utils.ScrapeClient, cfg config.HTTPConfig) {
+	var store utils.CookieStore
+	switch cfg.CookieStore {
+	case "":
+		return
+	case "db":
+		store = repository.NewCookieRepository(db)
+	case "file":
+		store = utils.FileCookieStore{Path: cfg.CookieFile}
+	default:
+		utils.GetLogger().Warn("unknown SCRAPE_COOKIE_STORE; cookies stay in memory", "value", cfg.CookieStore)
+		return
+	}
+	ctx := context.Background()
+	jar, err := scrape.UsePersistentJar(ctx, store)
+	if err != nil {
+		utils.GetLogger().Warn("scrape cookie restore failed", "store", cfg.CookieStore, "error", err)
 	}
+	go jar.Run(ctx, time.Minute)
 }
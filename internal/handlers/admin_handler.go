@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// AdminHandler serves operator-only views; routes sit behind middleware.RequireAdmin.
+type AdminHandler struct {
+	proxies   *utils.ProxyPool
+	providers map[string]*utils.ProxyPool
+}
+
+// NewAdminHandler takes the shared scrape pool and each provider's own pool (nil = direct).
+func NewAdminHandler(proxies *utils.ProxyPool, providers map[string]*utils.ProxyPool) *AdminHandler {
+	return &AdminHandler{proxies: proxies, providers: providers}
+}
+
+// GET /admin/proxies → per-proxy score, success rate, latency and cool-down (credentials redacted),
+// for the shared pool and every provider pool.
+func (ah *AdminHandler) GetProxies(c fiber.Ctx) error {
+	byProvider := make(map[string][]utils.ProxyHealth, len(ah.providers))
+	for name, pool := range ah.providers {
+		byProvider[name] = proxyRows(pool)
+	}
+	return c.JSON(fiber.Map{"proxies": proxyRows(ah.proxies), "providers": byProvider})
+}
+
+func proxyRows(pool *utils.ProxyPool) []utils.ProxyHealth {
+	if rows := pool.Health(); rows != nil {
+		return rows
+	}
+	return []utils.ProxyHealth{}
+}
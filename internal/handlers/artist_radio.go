@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	artistRadioSimilarArtists = 10 // similar artists mixed into one station
+	artistRadioSimilarTops    = 3  // top tracks taken from each of them
+	artistRadioMaxShare       = 10 // ratio sides are clamped to 0..this
+)
+
+// artistMix is how an artist station is built: own top tracks for every similar
+// artists' tracks, and the longest run one artist may get.
+type artistMix struct {
+	own, similar, maxRun int
+}
+
+var defaultArtistMix = artistMix{own: 1, similar: 2, maxRun: 2}
+
+// UseArtistRadio sets the default artist radio mix (RadioConfig); bad values keep
+// the built-in 1:2, run ≤ 2.
+func (h *RecommendHandler) UseArtistRadio(own, similar, maxRun int) {
+	m := artistMix{own: own, similar: similar, maxRun: maxRun}
+	if m.valid() {
+		h.artistMix = m
+	}
+}
+
+func (m artistMix) valid() bool {
+	return m.own >= 0 && m.similar >= 0 && m.own+m.similar > 0 &&
+		m.own <= artistRadioMaxShare && m.similar <= artistRadioMaxShare && m.maxRun > 0
+}
+
+func (m artistMix) key() string {
+	return strconv.Itoa(m.own) + ":" + strconv.Itoa(m.similar) + ":" + strconv.Itoa(m.maxRun)
+}
+
+// artistMixFor is the configured mix, with ?ratio=own:similar overriding it.
+func (h *RecommendHandler) artistMixFor(ratio string) (artistMix, bool) {
+	m := h.artistMix
+	if !m.valid() {
+		m = defaultArtistMix
+	}
+	if ratio == "" {
+		return m, true
+	}
+	a, b, ok := strings.Cut(ratio, ":")
+	own, err1 := strconv.Atoi(strings.TrimSpace(a))
+	similar, err2 := strconv.Atoi(strings.TrimSpace(b))
+	if !ok || err1 != nil || err2 != nil {
+		return m, false
+	}
+	m.own, m.similar = own, similar
+	return m, m.valid()
+}
+
+// GET /recommend/artist?artist=&ratio=1:2&offset=N → a station from one artist: their
+// top tracks interleaved with similar artists' (own:similar, default from config), no
+// artist more than a few times in a row. Cached 6h per artist+mix+offset;
+// session=new|<id> continues server-side like track radio.
+func (h *RecommendHandler) GetArtistRadio(c fiber.Ctx) error {
+	artist := strings.TrimSpace(c.Query("artist"))
+	session := c.Query("session") != ""
+	if artist == "" && !session {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "artist is required"})
+	}
+	mix, ok := h.artistMixFor(c.Query("ratio"))
+	if !ok {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "ratio must look like 1:2 (each side 0-10)"})
+	}
+	if h.apiKey == "" {
+		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "LASTFM_API_KEY not set"})
+	}
+	refresh := c.Query("refresh") == "1" || strings.EqualFold(c.Query("refresh"), "true")
+	if session {
+		return h.getRadioSession(c, radioStart{artist: artist, mix: mix}, refresh)
+	}
+
+	offset, _ := strconv.Atoi(c.Query("offset"))
+	offset = max(offset, 0)
+	key := "artist|" + utils.NormalizeString(artist) + "|" + mix.key() + "|" + strconv.Itoa(offset)
+	if !refresh {
+		if songs, ok := h.recommendSnap(key); ok {
+			c.Set("Cache-Control", "private, max-age=21600")
+			return c.JSON(h.signer.SignSongs(h.steerRadio(c, true, songs)))
+		}
+	}
+
+	sfKey := key
+	if refresh {
+		sfKey = key + "|refresh"
+	}
+	v, err, _ := h.recommendSF.Do(sfKey, func() (any, error) {
+		if !refresh {
+			if songs, ok := h.recommendSnap(key); ok {
+				return songs, nil
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Context()), 30*time.Second)
+		defer cancel()
+
+		pairs, err := h.artistPairs(ctx, artist, mix, refresh)
+		if err != nil {
+			return nil, err
+		}
+		if offset > 0 && len(pairs) > 0 {
+			off := offset % len(pairs)
+			pairs = append(pairs[off:], pairs[:off]...)
+		}
+		resolved := h.resolveN(ctx, pairs, lastfmPair{}, radioResolveCap, refresh, true)
+		songs := capSongRuns(resolved, mix.maxRun)
+		if len(songs) == 0 {
+			return nil, errRecommendEmpty
+		}
+		h.covers.FillSongs(ctx, songs)
+		h.recommendStore(key, songs)
+		return songs, nil
+	})
+	if err != nil {
+		if stale, ok := h.recommendStale(key); ok {
+			c.Set("Cache-Control", "private, max-age=60")
+			return c.JSON(h.signer.SignSongs(h.steerRadio(c, true, stale)))
+		}
+		if err == errRecommendEmpty {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Couldn't build a radio for this artist"})
+		}
+		return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": "Radio lookup failed. Try again."})
+	}
+	songs, _ := v.([]domain.Song)
+	c.Set("Cache-Control", "private, max-age=21600")
+	return c.JSON(h.signer.SignSongs(h.steerRadio(c, true, songs)))
+}
+
+// artistPairs is an artist station's queue, cached with the other Last.fm pairs.
+func (h *RecommendHandler) artistPairs(ctx context.Context, artist string, mix artistMix, refresh bool) ([]lastfmPair, error) {
+	pk := "artist|" + utils.NormalizeString(artist) + "|" + mix.key()
+	if refresh {
+		pairs, err := h.collectArtistPairs(ctx, artist, mix)
+		if err != nil {
+			return nil, err
+		}
+		h.pairsStore(pk, pairs)
+		return pairs, nil
+	}
+	v, err, _ := h.pairsSF.Do(pk, func() (any, error) {
+		if pairs, ok := h.pairsSnap(pk); ok {
+			return pairs, nil
+		}
+		pairs, err := h.collectArtistPairs(ctx, artist, mix)
+		if err != nil {
+			return nil, err
+		}
+		h.pairsStore(pk, pairs)
+		return pairs, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(v.([]lastfmPair)), nil
+}
+
+// collectArtistPairs: top tracks of every name in a collab ("A feat. B" → A, B)
+// interleaved, mixed mix.own:mix.similar with the best few tracks of the artist's
+// similar artists (the first collab member that has any), then runs capped.
+func (h *RecommendHandler) collectArtistPairs(ctx context.Context, artist string, mix artistMix) ([]lastfmPair, error) {
+	names := artistCandidates(artist)
+	own := make([][]lastfmPair, len(names))
+	errs := make([]error, len(names)+1)
+	var similar []string
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Go(func() {
+			own[i], errs[i] = h.lastfmArtistTop(ctx, name, "")
+		})
+	}
+	wg.Go(func() {
+		similar, errs[len(names)] = h.similarToAny(ctx, names)
+	})
+	wg.Wait()
+
+	tops := make([][]lastfmPair, len(similar))
+	for i, name := range similar {
+		wg.Go(func() {
+			if t, err := h.lastfmArtistTop(ctx, name, ""); err == nil {
+				tops[i] = t[:min(len(t), artistRadioSimilarTops)]
+			}
+		})
+	}
+	wg.Wait()
+
+	pairs := mixByRatio(interleavePairs(own...), interleavePairs(tops...), mix.own, mix.similar)
+	pairs = capPairRuns(uniquePairs(pairs, lastfmPair{}), mix.maxRun)
+	if len(pairs) == 0 {
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, errRecommendEmpty
+	}
+	return pairs, nil
+}
+
+// similarToAny is the first non-empty artist.getSimilar among names, minus the names
+// themselves — a collab string rarely has a Last.fm page of its own.
+func (h *RecommendHandler) similarToAny(ctx context.Context, names []string) ([]string, error) {
+	self := make(map[string]bool, len(names))
+	for _, n := range names {
+		self[utils.NormalizeString(n)] = true
+	}
+	var firstErr error
+	for _, n := range names {
+		similar, err := h.lastfmSimilarArtists(ctx, n)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		similar = slices.DeleteFunc(similar, func(s string) bool { return self[utils.NormalizeString(s)] })
+		if len(similar) > 0 {
+			return similar[:min(len(similar), artistRadioSimilarArtists)], nil
+		}
+	}
+	return nil, firstErr
+}
+
+// mixByRatio takes nA from a, then nB from b, and so on; when one side runs out the
+// rest of the other follows. A zero side is left out.
+func mixByRatio[T any](a, b []T, nA, nB int) []T {
+	if nA <= 0 {
+		a = nil
+	}
+	if nB <= 0 {
+		b = nil
+	}
+	out := make([]T, 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		k := min(nA, len(a))
+		out, a = append(out, a[:k]...), a[k:]
+		k = min(nB, len(b))
+		out, b = append(out, b[:k]...), b[k:]
+	}
+	return out
+}
+
+func capPairRuns(pairs []lastfmPair, maxRun int) []lastfmPair {
+	return capRuns(pairs, maxRun, func(p lastfmPair) string { return p.artist })
+}
+
+func capSongRuns(songs []domain.Song, maxRun int) []domain.Song {
+	return capRuns(songs, maxRun, func(s domain.Song) string { return s.Artist })
+}
+
+// capRuns keeps order but never lets one artist (the lead name of a collab) play more
+// than maxRun times in a row: the next other-artist item is pulled forward, and a
+// tail left to a single artist is dropped.
+func capRuns[T any](items []T, maxRun int, artistOf func(T) string) []T {
+	lead := func(it T) string {
+		if names := artistCandidates(artistOf(it)); len(names) > 0 {
+			return utils.NormalizeString(names[0])
+		}
+		return ""
+	}
+	pending := slices.Clone(items)
+	out := make([]T, 0, len(items))
+	prev, run := "", 0
+	for len(pending) > 0 {
+		pick := 0
+		if maxRun > 0 && run >= maxRun {
+			if pick = slices.IndexFunc(pending, func(it T) bool { return lead(it) != prev }); pick < 0 {
+				break
+			}
+		}
+		it := pending[pick]
+		pending = slices.Delete(pending, pick, pick+1)
+		if a := lead(it); a == prev {
+			run++
+		} else {
+			prev, run = a, 1
+		}
+		out = append(out, it)
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/gofiber/fiber/v3"
+)
+
+// fakeArtistLastfm: Main and Guest have six top tracks each; Main is similar to
+// S0…S5 (three tracks each), Guest has no similar artists.
+func fakeArtistLastfm() (*http.Client, stubSearch) {
+	f := newFakeLastfm()
+	tops := map[string]int{"Main": 6, "Guest": 6}
+	var similar []string
+	for i := range 6 {
+		name := fmt.Sprintf("S%d", i)
+		tops[name] = 3
+		similar = append(similar, name)
+	}
+	for artist, n := range tops {
+		for i := range n {
+			f.song(artist, fmt.Sprintf("T%d", i))
+		}
+	}
+	f.on("artist.getSimilar", func(q url.Values) any {
+		var names []string
+		if q.Get("artist") == "Main" {
+			names = similar
+		}
+		return map[string]any{"similarartists": map[string]any{"artist": lastfmNames(names)}}
+	})
+	f.on("artist.getTopTracks", func(q url.Values) any {
+		var tracks []lastfmPair
+		for i := range tops[q.Get("artist")] {
+			tracks = append(tracks, lastfmPair{artist: q.Get("artist"), title: fmt.Sprintf("T%d", i)})
+		}
+		return map[string]any{"toptracks": map[string]any{"track": lastfmTracks(tracks)}}
+	})
+	return f.client()
+}
+
+func TestMixByRatio(t *testing.T) {
+	pairs := func(artist string, n int) []lastfmPair {
+		var out []lastfmPair
+		for i := range n {
+			out = append(out, lastfmPair{artist: artist, title: fmt.Sprint(i)})
+		}
+		return out
+	}
+	artists := func(ps []lastfmPair) string {
+		var b strings.Builder
+		for _, p := range ps {
+			b.WriteString(p.artist)
+		}
+		return b.String()
+	}
+	for _, c := range []struct {
+		own, similar int
+		want         string
+	}{
+		{1, 2, "abbabbaaa"},
+		{2, 1, "aabaababb"},
+		{0, 1, "bbbb"},
+	} {
+		if got := artists(mixByRatio(pairs("a", 5), pairs("b", 4), c.own, c.similar)); got != c.want {
+			t.Errorf("%d:%d = %s, want %s", c.own, c.similar, got, c.want)
+		}
+	}
+}
+
+func TestCapRuns(t *testing.T) {
+	var in []lastfmPair
+	for _, a := range []string{"A", "A feat. B", "A", "A", "C", "A", "A", "A"} {
+		in = append(in, lastfmPair{artist: a, title: fmt.Sprint(len(in))})
+	}
+	var got []string
+	for _, p := range capPairRuns(in, 2) {
+		got = append(got, p.title)
+	}
+	// "A feat. B" counts as A; the trailing A run has nothing left to break it up.
+	if want := []string{"0", "1", "4", "2", "3"}; !slices.Equal(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+func TestArtistRadio(t *testing.T) {
+	client, search := fakeArtistLastfm()
+	h := &RecommendHandler{client: client, apiKey: "k", search: search}
+	h.UseArtistRadio(1, 2, 2)
+	app := fiber.New()
+	app.Get("/recommend/artist", h.GetArtistRadio)
+
+	get := func(q url.Values) (*http.Response, []domain.Song) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/recommend/artist?"+q.Encode(), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var songs []domain.Song
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&songs); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return resp, songs
+	}
+	checkRuns := func(songs []domain.Song) {
+		t.Helper()
+		run := 0
+		for i, s := range songs {
+			if i > 0 && s.Artist == songs[i-1].Artist {
+				run++
+			} else {
+				run = 1
+			}
+			if run > 2 {
+				t.Fatalf("%s three in a row: %+v", s.Artist, songs)
+			}
+		}
+	}
+
+	if resp, _ := get(url.Values{"artist": {"Main"}, "ratio": {"2"}}); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("bad ratio: status %d", resp.StatusCode)
+	}
+
+	// A collab name plays both members and Main's similar artists, 1 own : 2 similar.
+	resp, songs := get(url.Values{"artist": {"Main feat. Guest"}})
+	if resp.StatusCode != http.StatusOK || len(songs) == 0 {
+		t.Fatalf("status %d, %d songs", resp.StatusCode, len(songs))
+	}
+	byArtist := map[string]int{}
+	for _, s := range songs {
+		byArtist[s.Artist[:1]]++
+	}
+	if byArtist["M"] == 0 || byArtist["G"] == 0 || byArtist["S"] < byArtist["M"]+byArtist["G"] {
+		t.Fatalf("mix %v: %+v", byArtist, songs)
+	}
+	checkRuns(songs)
+
+	// Own-heavy ratio still never plays one artist three times running.
+	_, songs = get(url.Values{"artist": {"Main"}, "ratio": {"5:1"}})
+	checkRuns(songs)
+
+	// Sessions continue without repeats until the walk runs out.
+	resp, songs = get(url.Values{"artist": {"Main"}, "session": {"new"}})
+	id := resp.Header.Get("X-Radio-Session")
+	seen := map[string]bool{}
+	for batch := 0; resp.StatusCode == http.StatusOK; batch++ {
+		if batch > 20 {
+			t.Fatal("session never ended")
+		}
+		checkRuns(songs)
+		for _, s := range songs {
+			if seen[s.Id] {
+				t.Fatalf("repeated %s", s.Id)
+			}
+			seen[s.Id] = true
+		}
+		resp, songs = get(url.Values{"session": {id}})
+	}
+	if resp.StatusCode != http.StatusGone || len(seen) < 12 {
+		t.Fatalf("session: status %d after %d songs", resp.StatusCode, len(seen))
+	}
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	coSavePerSeed = 20 // co-saves read per saved track
+	// Fewer co-saved tracks than this and Last.fm tops the list up.
+	coSaveMinPairs = 8
+	// Newest vault tracks looked up when no single track is given.
+	becauseVaultKeys = 200
+)
+
+// coSaveSource reads "people who saved X also saved Y" (services.CoSaveService).
+type coSaveSource interface {
+	Related(ctx context.Context, keys []string, perKey int) (map[string][]domain.CoSave, error)
+}
+
+// UseCoSaves feeds everyone's favorites into /recommend/because and GetRecommend.
+func (h *RecommendHandler) UseCoSaves(cs coSaveSource) {
+	h.coSaves = cs
+}
+
+// GET /recommend/because?userId=&artist=&title= → what people who saved this track
+// also saved (no track: what the user's vault has in common with others'), minus the
+// vault. Thin or missing co-save data is topped up from Last.fm. Cached 6h per
+// user + track.
+func (h *RecommendHandler) GetBecauseSaved(c fiber.Ctx) error {
+	userId := strings.TrimSpace(c.Query("userId"))
+	if err := utils.ValidateUserID(userId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	seed := lastfmPair{artist: strings.TrimSpace(c.Query("artist")), title: strings.TrimSpace(c.Query("title"))}
+	seedKey := songKey(seed.artist, seed.title)
+	if seedKey == "" && (seed.artist != "" || seed.title != "") {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "artist and title go together"})
+	}
+	if seedKey == "" && h.favorites == nil {
+		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "vault not available"})
+	}
+	if h.coSaves == nil && h.apiKey == "" {
+		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "LASTFM_API_KEY not set"})
+	}
+
+	refresh := c.Query("refresh") == "1" || strings.EqualFold(c.Query("refresh"), "true")
+	key := "because|" + userId + "|" + seedKey
+	if !refresh {
+		if songs, ok := h.recommendSnap(key); ok {
+			c.Set("Cache-Control", "private, max-age=21600")
+			return c.JSON(h.signer.SignSongs(h.steerVault(c, userId, songs)))
+		}
+	}
+
+	sfKey := key
+	if refresh {
+		sfKey = key + "|refresh"
+	}
+	v, err, _ := h.recommendSF.Do(sfKey, func() (any, error) {
+		if !refresh {
+			if songs, ok := h.recommendSnap(key); ok {
+				return songs, nil
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Context()), 30*time.Second)
+		defer cancel()
+
+		var favs []domain.FavoriteSong
+		if h.favorites != nil {
+			var err error
+			if favs, err = h.favorites.GetFavorites(ctx, userId); err != nil {
+				return nil, err
+			}
+		}
+		inVault := make(map[string]bool, len(favs))
+		for _, f := range favs {
+			inVault[songKey(f.Artist, f.Title)] = true
+		}
+		keys := []string{seedKey}
+		if seedKey == "" {
+			if len(favs) == 0 {
+				return nil, errRecommendEmpty
+			}
+			keys = vaultKeys(favs, becauseVaultKeys)
+		}
+
+		pairs := h.coSavePairs(ctx, keys, inVault)
+		if len(pairs) < coSaveMinPairs && h.apiKey != "" {
+			more, err := h.becauseFallback(ctx, userId, seed, favs, refresh)
+			if err != nil && len(pairs) == 0 {
+				return nil, err
+			}
+			for _, p := range more {
+				if !inVault[songKey(p.artist, p.title)] {
+					pairs = append(pairs, p)
+				}
+			}
+			pairs = uniquePairs(pairs, seed)
+		}
+
+		resolved := h.resolveN(ctx, pairs, seed, recommendResolveCap, refresh, false)
+		songs := make([]domain.Song, 0, len(resolved))
+		for _, s := range resolved {
+			if !inVault[songKey(s.Artist, s.Title)] {
+				songs = append(songs, s)
+			}
+		}
+		if len(songs) == 0 {
+			return nil, errRecommendEmpty
+		}
+		h.covers.FillSongs(ctx, songs)
+		h.recommendStore(key, songs)
+		return songs, nil
+	})
+	if err != nil {
+		if stale, ok := h.recommendStale(key); ok {
+			c.Set("Cache-Control", "private, max-age=60")
+			return c.JSON(h.signer.SignSongs(h.steerVault(c, userId, stale)))
+		}
+		if err == errRecommendEmpty {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Save a few songs to get picks from other listeners"})
+		}
+		return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": "Recommendation lookup failed. Try again."})
+	}
+	songs, _ := v.([]domain.Song)
+	c.Set("Cache-Control", "private, max-age=21600")
+	return c.JSON(h.signer.SignSongs(h.steerVault(c, userId, songs)))
+}
+
+// becauseFallback is Last.fm's view: the track's neighborhood, or a vault radio draw.
+func (h *RecommendHandler) becauseFallback(ctx context.Context, userId string, seed lastfmPair, favs []domain.FavoriteSong, refresh bool) ([]lastfmPair, error) {
+	if songKey(seed.artist, seed.title) != "" {
+		return h.pairsFor(ctx, seed, artistCandidates(seed.artist), false, refresh)
+	}
+	now := time.Now().UTC()
+	return h.vaultPairs(ctx, sampleVaultSeeds(favs, vaultRadioSeeds, now, vaultRadioRand(userId, now)), refresh)
+}
+
+// vaultKeys is the SongKey of up to n of the newest favorites.
+func vaultKeys(favs []domain.FavoriteSong, n int) []string {
+	newest := slices.Clone(favs)
+	slices.SortStableFunc(newest, func(a, b domain.FavoriteSong) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	keys := make([]string, 0, min(len(newest), n))
+	seen := map[string]bool{}
+	for _, f := range newest {
+		if k := songKey(f.Artist, f.Title); k != "" && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+			if len(keys) == n {
+				break
+			}
+		}
+	}
+	return keys
+}
+
+// coSavePairs ranks tracks co-saved with any of keys by summed score, skipping keys
+// themselves and anything in exclude. Nil without a co-save store or on error.
+func (h *RecommendHandler) coSavePairs(ctx context.Context, keys []string, exclude map[string]bool) []lastfmPair {
+	if h.coSaves == nil || len(keys) == 0 {
+		return nil
+	}
+	related, err := h.coSaves.Related(ctx, keys, coSavePerSeed)
+	if err != nil {
+		utils.GetLogger().Debug("co-save read failed", "error", err)
+		return nil
+	}
+	self := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		self[k] = true
+	}
+	score := map[string]float64{}
+	pairs := map[string]lastfmPair{}
+	for _, list := range related {
+		for _, r := range list {
+			if exclude[r.OtherKey] || self[r.OtherKey] {
+				continue
+			}
+			score[r.OtherKey] += r.Score
+			pairs[r.OtherKey] = lastfmPair{artist: r.Artist, title: r.Title}
+		}
+	}
+	ranked := make([]string, 0, len(score))
+	for k := range score {
+		ranked = append(ranked, k)
+	}
+	slices.SortFunc(ranked, func(a, b string) int { return cmp.Or(cmp.Compare(score[b], score[a]), cmp.Compare(a, b)) })
+	out := make([]lastfmPair, len(ranked))
+	for i, k := range ranked {
+		out[i] = pairs[k]
+	}
+	return out
+}
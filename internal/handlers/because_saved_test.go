@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/gofiber/fiber/v3"
+)
+
+type fakeCoSaves map[string][]domain.CoSave
+
+func (f fakeCoSaves) Related(_ context.Context, keys []string, perKey int) (map[string][]domain.CoSave, error) {
+	out := map[string][]domain.CoSave{}
+	for _, k := range keys {
+		if rows := f[k]; len(rows) > 0 {
+			out[k] = rows[:min(len(rows), perKey)]
+		}
+	}
+	return out, nil
+}
+
+func TestBecauseSaved(t *testing.T) {
+	client, search := fakeLastfm(4)
+	addHit := func(artist, title string) {
+		search.hits[strings.ToLower(artist+" "+title)] = []domain.Song{{
+			Id: artist + "-" + title, Artist: artist, Title: title, Link: "https://x/" + artist + ".mp3",
+		}}
+	}
+	coSaved := func(seedKey string, tracks ...[2]string) []domain.CoSave {
+		var rows []domain.CoSave
+		for i, tr := range tracks {
+			addHit(tr[0], tr[1])
+			rows = append(rows, domain.CoSave{
+				SongKey: seedKey, OtherKey: songKey(tr[0], tr[1]), Artist: tr[0], Title: tr[1], Users: 3, Score: 1 - float64(i)/100,
+			})
+		}
+		return rows
+	}
+
+	nero := songKey("Nero", "Promises")
+	var crowd [][2]string
+	for i := range 9 {
+		crowd = append(crowd, [2]string{fmt.Sprintf("C%d", i), "Hit"})
+	}
+	crowd = append(crowd, [2]string{"Burial", "Archangel"}) // already in the vault
+	h := &RecommendHandler{client: client, apiKey: "k", search: search}
+	h.UseVault(fakeVault{{Artist: "Nero", Title: "Promises"}, {Artist: "Burial", Title: "Archangel"}})
+	h.UseCoSaves(fakeCoSaves{
+		nero:                    coSaved(nero, crowd...),
+		songKey("A0", "Song 0"): coSaved(songKey("A0", "Song 0"), [2]string{"Zed", "Hit"}),
+	})
+	app := fiber.New()
+	app.Get("/recommend/because", h.GetBecauseSaved)
+	app.Get("/recommend", h.GetRecommend)
+
+	get := func(path string, q url.Values) (int, []domain.Song) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path+"?"+q.Encode(), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var songs []domain.Song
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&songs); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return resp.StatusCode, songs
+	}
+
+	// Enough co-saves: served straight from them, vault tracks left out.
+	status, songs := get("/recommend/because", url.Values{"userId": {"u1"}})
+	if status != http.StatusOK || len(songs) < 8 {
+		t.Fatalf("vault: status %d, %d songs", status, len(songs))
+	}
+	for _, s := range songs {
+		if !strings.HasPrefix(s.Artist, "C") {
+			t.Fatalf("vault: unexpected %+v", s)
+		}
+	}
+
+	// One co-save for this track: Last.fm tops it up.
+	status, songs = get("/recommend/because", url.Values{"userId": {"u1"}, "artist": {"A0"}, "title": {"Song 0"}})
+	artists := map[string]bool{}
+	for _, s := range songs {
+		artists[s.Artist] = true
+	}
+	if status != http.StatusOK || !artists["Zed"] || !artists["A1"] {
+		t.Fatalf("thin: status %d %+v", status, songs)
+	}
+
+	// GetRecommend blends the same co-saves in with Last.fm's neighbours.
+	status, songs = get("/recommend", url.Values{"artist": {"A0"}, "title": {"Song 0"}})
+	if status != http.StatusOK || songs[0].Artist != "Zed" {
+		t.Fatalf("recommend: status %d %+v", status, songs)
+	}
+
+	if status, _ := get("/recommend/because", url.Values{"userId": {"u1"}, "artist": {"Nero"}}); status != http.StatusBadRequest {
+		t.Fatalf("half a track: status %d", status)
+	}
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	dailyMixCount        = 4
+	dailyMixArtists      = 16 // most-saved artists whose tags shape the themes
+	dailyMixThemeArtists = 6  // a broad tag can't swallow the whole vault
+	dailyMixArtistTags   = 5
+	dailyMixSaved        = 6  // saved tracks per mix
+	dailyMixFresh        = 12 // recommended tracks per mix
+	dailyMixSeeds        = 3
+)
+
+// Tags that say how someone listens rather than what the music is.
+var dailyMixTagStoplist = map[string]bool{
+	"seen live": true, "favorites": true, "favourites": true, "favorite": true, "favourite": true,
+	"love": true, "awesome": true, "beautiful": true, "cool": true,
+}
+
+// dailyMixSource serves and refreshes stored mixes (services.DailyMixService).
+type dailyMixSource interface {
+	Today(ctx context.Context, userId string) ([]domain.DailyMix, error)
+	Refresh(ctx context.Context, userId string) ([]domain.DailyMix, time.Duration, error)
+}
+
+// UseDailyMixes serves /recommend/mixes from m.
+func (h *RecommendHandler) UseDailyMixes(m dailyMixSource) {
+	h.dailyMixes = m
+}
+
+// GET /recommend/mixes?userId=&refresh=1 → today's daily mixes, built by the nightly
+// job (or now, if it hasn't reached this user). refresh rebuilds them a few times a
+// day; past the limit → 429 with Retry-After.
+func (h *RecommendHandler) GetDailyMixes(c fiber.Ctx) error {
+	userId := strings.TrimSpace(c.Query("userId"))
+	if err := utils.ValidateUserID(userId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if h.dailyMixes == nil {
+		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "daily mixes not available"})
+	}
+	var mixes []domain.DailyMix
+	var err error
+	if c.Query("refresh") == "1" || strings.EqualFold(c.Query("refresh"), "true") {
+		var retry time.Duration
+		mixes, retry, err = h.dailyMixes.Refresh(c.Context(), userId)
+		if errors.Is(err, domain.ErrLimitReached) {
+			c.Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+			return c.Status(http.StatusTooManyRequests).JSON(fiber.Map{"error": "No more mix refreshes today"})
+		}
+	} else {
+		mixes, err = h.dailyMixes.Today(c.Context(), userId)
+	}
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Save a few songs to get daily mixes"})
+	case errors.Is(err, domain.ErrUnavailable):
+		return HandleError(c, err)
+	case err != nil:
+		return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": "Couldn't build your mixes. Try again."})
+	}
+	out := make([]domain.DailyMix, len(mixes))
+	for i, m := range mixes {
+		m.Songs = h.signer.SignSongs(m.Songs)
+		out[i] = m
+	}
+	c.Set("Cache-Control", "private, no-cache")
+	return c.JSON(out)
+}
+
+// mixTheme is one daily mix's slice of the vault: artists under a shared tag (or a
+// single untagged artist).
+type mixTheme struct {
+	tag     string
+	artists []string
+	favs    []domain.FavoriteSong
+}
+
+func (t mixTheme) subtitle() string {
+	names := strings.Join(t.artists[:min(len(t.artists), 3)], ", ")
+	if t.tag == "" {
+		return names
+	}
+	return t.tag + " · " + names
+}
+
+// themeArtist is one vault artist with their saves and Last.fm top tags.
+type themeArtist struct {
+	name  string
+	favs  []domain.FavoriteSong
+	tags  []string
+	saves int
+}
+
+// BuildDailyMixes groups the user's vault into themes — artists clustered under the
+// Last.fm tag they share most — and fills each mix with a few saved tracks and fresh
+// picks from that theme's neighborhoods (1 saved : 2 fresh), all resolved up front.
+func (h *RecommendHandler) BuildDailyMixes(ctx context.Context, userId string) ([]domain.DailyMix, error) {
+	if h.favorites == nil || h.apiKey == "" {
+		return nil, domain.ErrUnavailable
+	}
+	favs, err := h.favorites.GetFavorites(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	themes := clusterThemes(h.tagVaultArtists(ctx, favs), dailyMixCount)
+	if len(themes) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	inVault := make(map[string]bool, len(favs))
+	for _, f := range favs {
+		inVault[songKey(f.Artist, f.Title)] = true
+	}
+	profile := h.feedbackProfile(ctx, userId)
+	now := time.Now().UTC()
+	rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())) // a refresh should differ
+
+	var mixes []domain.DailyMix
+	for _, th := range themes {
+		saved := make([]domain.Song, 0, dailyMixSaved)
+		for _, i := range rng.Perm(len(th.favs)) {
+			if f := th.favs[i]; strings.TrimSpace(f.Link) != "" && len(saved) < dailyMixSaved {
+				saved = append(saved, domain.Song{Id: f.ID, Title: f.Title, Artist: f.Artist, Image: f.Image, Link: f.Link})
+			}
+		}
+
+		var fresh []domain.Song
+		pairs, err := h.vaultPairs(ctx, sampleVaultSeeds(th.favs, dailyMixSeeds, now, rng), false)
+		if err == nil {
+			pairs = slices.DeleteFunc(pairs, func(p lastfmPair) bool { return inVault[songKey(p.artist, p.title)] })
+			pairs = balanceArtists(steerPairs(pairs, profile), vaultRadioArtistCap)
+			fresh = h.resolveN(ctx, pairs, lastfmPair{}, dailyMixFresh, false, false)
+			fresh = slices.DeleteFunc(fresh, func(s domain.Song) bool { return inVault[songKey(s.Artist, s.Title)] })
+		} else {
+			utils.GetLogger().Debug("daily mix neighborhood failed", "tag", th.tag, "error", err)
+		}
+
+		if len(fresh) == 0 {
+			continue // a mix of only saved tracks is just the vault
+		}
+		songs := mixByRatio(saved, fresh, 1, 2)
+		h.covers.FillSongs(ctx, songs)
+		mixes = append(mixes, domain.DailyMix{
+			Title:    fmt.Sprintf("Daily Mix %d", len(mixes)+1),
+			Subtitle: th.subtitle(),
+			Songs:    songs,
+		})
+	}
+	if len(mixes) == 0 {
+		return nil, domain.ErrNotFound // nothing fresh to add to any theme
+	}
+	return mixes, nil
+}
+
+// tagVaultArtists is the user's most-saved artists (lead name of collabs) with their
+// Last.fm top tags; an artist whose tags can't be read just has none.
+func (h *RecommendHandler) tagVaultArtists(ctx context.Context, favs []domain.FavoriteSong) []themeArtist {
+	index := map[string]int{}
+	var artists []themeArtist
+	for _, f := range favs {
+		names := artistCandidates(f.Artist)
+		if len(names) == 0 || strings.TrimSpace(f.Title) == "" {
+			continue
+		}
+		k := utils.NormalizeString(names[0])
+		i, ok := index[k]
+		if !ok {
+			i = len(artists)
+			index[k] = i
+			artists = append(artists, themeArtist{name: names[0]})
+		}
+		artists[i].favs = append(artists[i].favs, f)
+		artists[i].saves++
+	}
+	slices.SortStableFunc(artists, func(a, b themeArtist) int { return cmp.Compare(b.saves, a.saves) })
+	artists = artists[:min(len(artists), dailyMixArtists)]
+
+	var wg sync.WaitGroup
+	for i := range artists {
+		wg.Go(func() {
+			tags, err := h.lastfmArtistTags(ctx, artists[i].name)
+			if err != nil {
+				utils.GetLogger().Debug("artist tags failed", "artist", artists[i].name, "error", err)
+			}
+			artists[i].tags = tags
+		})
+	}
+	wg.Wait()
+	return artists
+}
+
+func (h *RecommendHandler) lastfmArtistTags(ctx context.Context, artist string) ([]string, error) {
+	names, err := h.lastfmNames(ctx, url.Values{
+		"method": {"artist.getTopTags"}, "artist": {artist},
+	}, "toptags", "tag")
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, n := range names {
+		n = strings.ToLower(strings.Join(strings.Fields(n), " "))
+		if n != "" && !dailyMixTagStoplist[n] && !slices.Contains(out, n) {
+			out = append(out, n)
+			if len(out) == dailyMixArtistTags {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// clusterThemes picks up to n themes greedily: each round takes the tag carried by the
+// most unassigned saves and gives it up to dailyMixThemeArtists of those artists
+// (most saved first). Artists no tag claims get a mix of their own while room is left.
+func clusterThemes(artists []themeArtist, n int) []mixTheme {
+	assigned := make([]bool, len(artists))
+	var themes []mixTheme
+	for len(themes) < n {
+		score := map[string]int{}
+		for i, a := range artists {
+			if !assigned[i] {
+				for _, t := range a.tags {
+					score[t] += a.saves
+				}
+			}
+		}
+		best := ""
+		for t, s := range score {
+			if best == "" || s > score[best] || (s == score[best] && t < best) {
+				best = t
+			}
+		}
+		if best == "" {
+			break
+		}
+		th := mixTheme{tag: best}
+		for i, a := range artists {
+			if !assigned[i] && slices.Contains(a.tags, best) && len(th.artists) < dailyMixThemeArtists {
+				assigned[i] = true
+				th.artists = append(th.artists, a.name)
+				th.favs = append(th.favs, a.favs...)
+			}
+		}
+		themes = append(themes, th)
+	}
+	for i, a := range artists {
+		if len(themes) >= n {
+			break
+		}
+		if !assigned[i] {
+			themes = append(themes, mixTheme{artists: []string{a.name}, favs: a.favs})
+		}
+	}
+	return themes
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+func TestClusterThemes(t *testing.T) {
+	artists := []themeArtist{
+		{name: "Nero", saves: 5, tags: []string{"dubstep", "electronic"}},
+		{name: "Burial", saves: 4, tags: []string{"electronic", "ambient"}},
+		{name: "Skrillex", saves: 3, tags: []string{"dubstep", "edm"}},
+		{name: "Moby", saves: 2, tags: []string{"electronic"}},
+		{name: "Solo", saves: 1},
+	}
+	themes := clusterThemes(artists, 3)
+	var got []string
+	for _, th := range themes {
+		got = append(got, th.subtitle())
+	}
+	want := []string{"electronic · Nero, Burial, Moby", "dubstep · Skrillex", "Solo"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %q want %q", got, want)
+	}
+	if themes := clusterThemes(artists, 1); len(themes) != 1 || themes[0].tag != "electronic" {
+		t.Fatalf("n=1: %+v", themes)
+	}
+}
+
+func TestBuildDailyMixes(t *testing.T) {
+	client, search := fakeLastfm(8)
+	h := &RecommendHandler{client: client, apiKey: "k", search: search}
+	var vault fakeVault
+	for _, a := range []string{"A0", "A5"} {
+		for _, title := range []string{"Song 0", "Song 1"} {
+			vault = append(vault, domain.FavoriteSong{ID: a + title, Artist: a, Title: title, Link: "https://x/" + a + title + ".mp3"})
+		}
+	}
+	h.UseVault(vault)
+
+	mixes, err := h.BuildDailyMixes(context.Background(), "u1")
+	if err != nil || len(mixes) != 2 {
+		t.Fatalf("%d mixes, %v", len(mixes), err)
+	}
+	for _, m := range mixes {
+		saved, fresh := 0, 0
+		for _, s := range m.Songs {
+			if strings.HasSuffix(s.Title, "0") || strings.HasSuffix(s.Title, "1") {
+				if s.Artist == m.Subtitle {
+					saved++
+					continue
+				}
+			}
+			fresh++
+		}
+		// Untagged artists each get their own mix: their saves plus new neighbours.
+		if saved != 2 || fresh == 0 {
+			t.Fatalf("%s (%s): %d saved, %d fresh: %+v", m.Title, m.Subtitle, saved, fresh, m.Songs)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/services"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/id3"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/streamcache"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	downloadCoverMax   = 2 << 20
+	downloadExtrasWait = 8 * time.Second
+)
+
+// lyricsSource is the /lyrics lookup (LyricsHandler), reused for USLT frames.
+type lyricsSource interface {
+	Lyrics(ctx context.Context, artist, title string) string
+}
+
+// UseLyrics lets /download embed lyrics.
+func (h *RecommendHandler) UseLyrics(l lyricsSource) {
+	h.lyrics = l
+}
+
+// GET /download?artist=&title= → the /stream bytes as an attachment, retagged in-stream:
+// catalog title/artist, cover art and unsynced lyrics replace the scraped tags.
+// Takes the same signed params as /stream; no Range (the tag changes the length).
+func (h *RecommendHandler) GetDownload(c fiber.Ctx) error {
+	artist := strings.TrimSpace(c.Query("artist"))
+	title := strings.TrimSpace(c.Query("title"))
+	if artist == "" || title == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "artist and title required"})
+	}
+	msg := h.streamSignatureError(c, services.SongSubject(artist, title))
+	if msg != "" {
+		return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": msg})
+	}
+	if retry, ok := h.allowStream(c); !ok {
+		return quotaExceeded(c, retry)
+	}
+	if h.upstream == nil {
+		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "stream proxy unavailable"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Context()), 45*time.Second)
+	defer cancel()
+
+	want := lastfmPair{artist: artist, title: title}
+	song, ok := h.resolveStream(ctx, want)
+	if !ok {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "no match"})
+	}
+	src, ct, err := h.openDownload(ctx, want, song)
+	if errors.Is(err, domain.ErrNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "track not found"})
+	}
+	if err != nil {
+		return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var body io.Reader = src
+	if ct == "audio/mpeg" {
+		body = id3.NewRewriter(src, h.downloadTags(ctx, artist, title, "", ""))
+	}
+	c.Set("Content-Type", ct)
+	c.Set("Cache-Control", "private, no-store")
+	c.Set("Content-Disposition", downloadDisposition(artist, title, ct))
+	return c.SendStream(h.metered(c, struct {
+		io.Reader
+		io.Closer
+	}{body, src}), -1) // chunked: a failed source aborts instead of ending clean
+}
+
+// openDownload reads the whole file the way /stream would serve it: library file,
+// disk cache, or the proxied upstream (with mid-body resume).
+func (h *RecommendHandler) openDownload(ctx context.Context, want lastfmPair, song domain.Song) (io.ReadCloser, string, error) {
+	if id, local := h.localTrackID(song.Link); local {
+		path, err := h.library.FilePath(ctx, id)
+		if err != nil {
+			return nil, "", err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, "", domain.ErrNotFound
+		}
+		return f, localContentType(path), nil
+	}
+	if h.streamCache != nil {
+		open := func(ctx context.Context, rangeHeader string) (*http.Response, error) {
+			return h.openStreamUpstream(ctx, song.Link, rangeHeader)
+		}
+		entry, err := h.streamCache.Acquire(ctx, streamcache.Key(songKey(want.artist, want.title), song.Link), 0, open)
+		if err == nil {
+			return struct {
+				io.Reader
+				io.Closer
+			}{entry.NewReader(0, entry.Size()-1), releaser{entry}}, audioContentType(entry.ContentType()), nil
+		}
+		utils.GetLogger().Debug("stream cache miss", "error", err)
+	}
+	resp, body, err := h.openProxied(ctx, want, song, "")
+	if err != nil {
+		return nil, "", err
+	}
+	return body, audioContentType(resp.Header.Get("Content-Type")), nil
+}
+
+// audioContentType drops parameters and falls back to MP3 for mirrors that send text/*.
+func audioContentType(ct string) string {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil || mt == "" || strings.HasPrefix(mt, "text/") || mt == "application/octet-stream" {
+		return "audio/mpeg"
+	}
+	return mt
+}
+
+// downloadTags fetches cover art and lyrics side by side; whatever misses the budget
+// is left out rather than holding the download. image/lyrics are known values (vault
+// rows) that skip their lookup — image only when it is on a cover CDN, since users can
+// store any URL there.
+func (h *RecommendHandler) downloadTags(ctx context.Context, artist, title, image, lyrics string) id3.Tags {
+	tags := id3.Tags{Title: cleanTagText(title), Artist: cleanTagText(artist), Lyrics: strings.TrimSpace(lyrics)}
+	ctx, cancel := context.WithTimeout(ctx, downloadExtrasWait)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	if h.covers != nil || services.IsCoverCDN(image) {
+		wg.Go(func() {
+			if !services.IsCoverCDN(image) {
+				image = h.covers.Lookup(ctx, artist+" "+title)
+			}
+			tags.Cover, tags.CoverMIME = h.fetchCover(ctx, image)
+		})
+	}
+	if h.lyrics != nil && tags.Lyrics == "" {
+		wg.Go(func() {
+			tags.Lyrics = h.lyrics.Lyrics(ctx, artist, title)
+		})
+	}
+	wg.Wait()
+	return tags
+}
+
+// fetchCover downloads a JPEG/PNG cover from a known art CDN (services.IsCoverCDN).
+func (h *RecommendHandler) fetchCover(ctx context.Context, image string) ([]byte, string) {
+	if !services.IsCoverCDN(image) || h.client == nil {
+		return nil, ""
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, image, nil)
+	if err != nil {
+		return nil, ""
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, ""
+	}
+	defer resp.Body.Close()
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if resp.StatusCode != http.StatusOK || (mt != "image/jpeg" && mt != "image/png") {
+		return nil, ""
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, downloadCoverMax+1))
+	if err != nil || len(b) == 0 || len(b) > downloadCoverMax {
+		return nil, ""
+	}
+	return b, mt
+}
+
+// cleanTagText strips "[mp3-you.net]"-style junk; the raw value wins if nothing is left.
+func cleanTagText(s string) string {
+	if c := cleanLyricsQuery(s); c != "" {
+		return c
+	}
+	return strings.TrimSpace(s)
+}
+
+// downloadDisposition is `attachment; filename="Artist - Title.mp3"` (RFC 2231 for non-ASCII).
+func downloadDisposition(artist, title, ct string) string {
+	return attachmentDisposition(downloadFileName(artist, title, ct))
+}
+
+func attachmentDisposition(name string) string {
+	if v := mime.FormatMediaType("attachment", map[string]string{"filename": name}); v != "" {
+		return v
+	}
+	return "attachment"
+}
+
+// downloadFileName is "Artist - Title.ext" with path and control characters replaced.
+func downloadFileName(artist, title, ct string) string {
+	return safeFileName(cleanTagText(artist)+" - "+cleanTagText(title)) + audioExt(ct)
+}
+
+func safeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20 || r == 0x7F:
+			return -1
+		case strings.ContainsRune(`/\:*?"<>|`, r):
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if len(name) > 180 {
+		name = strings.ToValidUTF8(name[:180], "")
+	}
+	if name == "" {
+		name = "track"
+	}
+	return name
+}
+
+func audioExt(ct string) string {
+	switch ct {
+	case "audio/flac":
+		return ".flac"
+	case "audio/mp4":
+		return ".m4a"
+	}
+	return ".mp3"
+}
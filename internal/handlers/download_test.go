@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/gofiber/fiber/v3"
+)
+
+type fakeLyrics string
+
+func (f fakeLyrics) Lyrics(context.Context, string, string) string { return string(f) }
+
+func TestGetDownloadRetags(t *testing.T) {
+	fixture := filepath.Join("..", "core", "services", "id3", "testdata")
+	src, err := os.ReadFile(filepath.Join(fixture, "junk_v23.mp3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	audio, err := os.ReadFile(filepath.Join(fixture, "audio.raw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain") // mirrors lie; must still be treated as MP3
+		_, _ = w.Write(src)
+	}))
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+
+	h := &RecommendHandler{client: srv.Client(), upstream: srv.Client()}
+	h.AllowStreamHost(u.Host)
+	h.UseLyrics(fakeLyrics("la la la"))
+	h.resolveStore(songKey("Nero", "Promises"), domain.Song{Artist: "Nero", Title: "Promises [mp3-you.net]", Link: srv.URL + "/a.mp3"})
+	app := fiber.New()
+	app.Get("/download", h.GetDownload)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/download?artist=Nero&title=Promises+(www.mp3-you.net)", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "audio/mpeg" {
+		t.Fatalf("status %d type %q: %s", resp.StatusCode, resp.Header.Get("Content-Type"), body)
+	}
+	if cd := resp.Header.Get("Content-Disposition"); cd != `attachment; filename="Nero - Promises.mp3"` {
+		t.Fatalf("disposition %q", cd)
+	}
+	if !bytes.HasPrefix(body, []byte("ID3\x03")) || !bytes.HasSuffix(body, audio) {
+		t.Fatalf("not retagged: % x", body[:16])
+	}
+	if bytes.Contains(body, []byte("mp3-you")) {
+		t.Fatal("junk tag survived")
+	}
+	if !bytes.Contains(body, []byte("USLT")) {
+		t.Fatal("lyrics not embedded")
+	}
+}
+
+func TestDownloadDisposition(t *testing.T) {
+	if got := downloadDisposition("AC/DC", `Back "In" Black`, "audio/flac"); got != `attachment; filename="AC_DC - Back _In_ Black.flac"` {
+		t.Fatalf("ascii: %s", got)
+	}
+	if got := downloadDisposition("Sigur Rós", "Hoppípolla", "audio/mpeg"); got != "attachment; filename*=utf-8''Sigur%20R%C3%B3s%20-%20Hopp%C3%ADpolla.mp3" {
+		t.Fatalf("utf-8: %s", got)
+	}
+}
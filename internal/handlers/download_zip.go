@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/id3"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	zipConcurrency  = 3 // tracks fetched ahead of the archive writer
+	zipTrackTimeout = 2 * time.Minute
+	zipMaxDuration  = 2 * time.Hour
+)
+
+// playlistSource loads one public playlist (SpotifyHandler).
+type playlistSource interface {
+	Playlist(ctx context.Context, rawURL string) (string, []SpotifyTrackMeta, error)
+}
+
+// UseArchives enables playlist ZIP downloads (and, with UseVault, vault ones) of at
+// most maxTracks tracks.
+func (h *RecommendHandler) UseArchives(playlists playlistSource, maxTracks int) {
+	h.playlists = playlists
+	h.zipMaxTracks = max(maxTracks, 1)
+}
+
+type zipItem struct {
+	artist, title string
+	// Known for vault rows; resolved / looked up otherwise.
+	link, image, lyrics string
+}
+
+type zipResult struct {
+	item zipItem
+	name string   // entry name inside the archive
+	file *os.File // retagged audio, removed once stored
+	err  error
+}
+
+// GET /favorites/:userId/zip → the vault as a ZIP of tagged tracks + playlist.m3u.
+func (h *RecommendHandler) GetFavoritesZip(c fiber.Ctx) error {
+	userId := c.Params("userId")
+	if err := utils.ValidateUserID(userId); err != nil {
+		return HandleError(c, err)
+	}
+	if h.favorites == nil || h.upstream == nil {
+		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "downloads unavailable"})
+	}
+	if retry, ok := h.allowStream(c); !ok {
+		return quotaExceeded(c, retry)
+	}
+	favs, err := h.favorites.GetFavorites(c.Context(), userId)
+	if err != nil {
+		return HandleError(c, err)
+	}
+	if len(favs) == 0 {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "vault is empty"})
+	}
+	items := make([]zipItem, 0, len(favs))
+	for _, f := range favs {
+		items = append(items, zipItem{artist: f.Artist, title: f.Title, link: f.Link, image: f.Image, lyrics: f.Lyrics})
+	}
+	return h.sendZip(c, "Favorites", items)
+}
+
+// GET /spotify/playlist/zip?url= → one public playlist as a ZIP, each track resolved like /stream.
+func (h *RecommendHandler) GetPlaylistZip(c fiber.Ctx) error {
+	if h.playlists == nil || h.upstream == nil {
+		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "downloads unavailable"})
+	}
+	if retry, ok := h.allowStream(c); !ok {
+		return quotaExceeded(c, retry)
+	}
+	name, tracks, err := h.playlists.Playlist(c.Context(), c.Query("url"))
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Paste a public Spotify playlist link"})
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Playlist not found or not public"})
+	case err != nil:
+		return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": "Couldn't load Spotify playlist"})
+	case len(tracks) == 0:
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Playlist has no tracks"})
+	}
+	items := make([]zipItem, 0, len(tracks))
+	for _, t := range tracks {
+		items = append(items, zipItem{artist: t.Artist, title: t.Title})
+	}
+	if strings.TrimSpace(name) == "" {
+		name = "Playlist"
+	}
+	return h.sendZip(c, name, items)
+}
+
+// sendZip streams items as "<name>.zip" without holding the archive in memory: up to
+// zipConcurrency tracks are fetched and retagged into temp files ahead of the writer,
+// which stores them in order. Failed tracks (and those over the cap) are listed in
+// failed.txt instead of aborting the archive.
+func (h *RecommendHandler) sendZip(c fiber.Ctx, name string, items []zipItem) error {
+	var skipped []zipItem
+	if limit := max(h.zipMaxTracks, 1); len(items) > limit {
+		items, skipped = items[:limit], items[limit:]
+	}
+	c.Set("Content-Type", "application/zip")
+	c.Set("Cache-Control", "private, no-store")
+	c.Set("Content-Disposition", attachmentDisposition(safeFileName(name)+".zip"))
+	subject, _ := c.Locals(quotaSubjectLocal).(string) // c is recycled once the writer runs
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(context.Background(), zipMaxDuration)
+		defer cancel()
+		if err := h.writeZip(ctx, w, h.meteredWriter(subject, w), items, skipped); err != nil {
+			utils.GetLogger().Debug("zip download aborted", "error", err)
+		}
+	})
+}
+
+// writeZip writes the archive to out (w, metered) and flushes w after every track.
+func (h *RecommendHandler) writeZip(ctx context.Context, w *bufio.Writer, out io.Writer, items, skipped []zipItem) error {
+	ctx, cancel := context.WithCancel(ctx)
+	results, slots := h.prefetchZip(ctx, items)
+	next := 0
+	defer func() {
+		cancel()
+		go discardZipResults(results[next:]) // in-flight fetches clean up after themselves
+	}()
+
+	zw := zip.NewWriter(out)
+	var m3u, failed strings.Builder
+	m3u.WriteString("#EXTM3U\n")
+	for next < len(results) {
+		r := <-results[next]
+		next++
+		select {
+		case <-slots:
+		default: // cancelled before it was started
+		}
+		if r.err != nil {
+			fmt.Fprintf(&failed, "%s - %s: %v\n", r.item.artist, r.item.title, r.err)
+			continue
+		}
+		if err := storeZipEntry(zw, r); err != nil {
+			return err // client went away
+		}
+		fmt.Fprintf(&m3u, "#EXTINF:-1,%s - %s\n%s\n", r.item.artist, r.item.title, r.name)
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	for _, it := range skipped {
+		fmt.Fprintf(&failed, "%s - %s: over the %d-track limit\n", it.artist, it.title, h.zipMaxTracks)
+	}
+	if err := writeZipText(zw, "playlist.m3u", m3u.String()); err != nil {
+		return err
+	}
+	if failed.Len() > 0 {
+		if err := writeZipText(zw, "failed.txt", "Tracks that could not be downloaded:\n\n"+failed.String()); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// prefetchZip starts fetches in order, never more than zipConcurrency ahead of the
+// writer (it takes one slot back per result consumed).
+func (h *RecommendHandler) prefetchZip(ctx context.Context, items []zipItem) ([]chan zipResult, chan struct{}) {
+	results := make([]chan zipResult, len(items))
+	for i := range results {
+		results[i] = make(chan zipResult, 1)
+	}
+	slots := make(chan struct{}, zipConcurrency)
+	go func() {
+		for i, it := range items {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				for j := i; j < len(items); j++ {
+					results[j] <- zipResult{item: items[j], err: ctx.Err()}
+				}
+				return
+			}
+			go func() { results[i] <- h.fetchZipTrack(ctx, i, it) }()
+		}
+	}()
+	return results, slots
+}
+
+func discardZipResults(results []chan zipResult) {
+	for _, ch := range results {
+		if r := <-ch; r.file != nil {
+			r.file.Close()
+			os.Remove(r.file.Name())
+		}
+	}
+}
+
+// fetchZipTrack downloads one track the way /download does into a temp file.
+func (h *RecommendHandler) fetchZipTrack(ctx context.Context, i int, it zipItem) zipResult {
+	res := zipResult{item: it}
+	ctx, cancel := context.WithTimeout(ctx, zipTrackTimeout)
+	defer cancel()
+
+	want := lastfmPair{artist: it.artist, title: it.title}
+	song := domain.Song{Artist: it.artist, Title: it.title, Link: strings.TrimSpace(it.link)}
+	if song.Link == "" {
+		var ok bool
+		if song, ok = h.resolveStream(ctx, want); !ok {
+			res.err = errors.New("no match")
+			return res
+		}
+	}
+	src, ct, err := h.openDownload(ctx, want, song)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	defer src.Close()
+	var body io.Reader = src
+	if ct == "audio/mpeg" {
+		body = id3.NewRewriter(src, h.downloadTags(ctx, it.artist, it.title, it.image, it.lyrics))
+	}
+
+	f, err := os.CreateTemp("", "findvibe-zip-*")
+	if err != nil {
+		res.err = err
+		return res
+	}
+	n, err := io.Copy(f, ctxReader{ctx, body})
+	if err == nil && n == 0 {
+		err = errors.New("empty file")
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		res.err = err
+		return res
+	}
+	res.file = f
+	res.name = fmt.Sprintf("%03d %s", i+1, downloadFileName(it.artist, it.title, ct))
+	return res
+}
+
+// ctxReader stops a copy once ctx is done (proxied bodies run on their own context).
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// storeZipEntry copies a fetched track in uncompressed (MP3 doesn't deflate) and
+// removes its temp file.
+func storeZipEntry(zw *zip.Writer, r zipResult) error {
+	defer func() {
+		r.file.Close()
+		os.Remove(r.file.Name())
+	}()
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: r.name, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, r.file)
+	return err
+}
+
+func writeZipText(zw *zip.Writer, name, text string) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(fw, text)
+	return err
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/gofiber/fiber/v3"
+)
+
+type fakeVault []domain.FavoriteSong
+
+func (f fakeVault) GetFavorites(context.Context, string) ([]domain.FavoriteSong, error) {
+	return f, nil
+}
+
+// noSearch finds nothing, so dead links stay dead.
+type noSearch struct{}
+
+func (noSearch) Search(context.Context, string, int) (*domain.SearchResponse, error) {
+	return nil, errors.New("offline")
+}
+
+func (noSearch) SearchWithProgress(context.Context, string, int, func(domain.SearchProgress) error, func(domain.Song) error) (*domain.SearchResponse, error) {
+	return nil, errors.New("offline")
+}
+
+func (noSearch) SearchFirst(context.Context, string, int) ([]domain.Song, error) {
+	return nil, errors.New("offline")
+}
+
+func TestGetFavoritesZip(t *testing.T) {
+	fixture := filepath.Join("..", "core", "services", "id3", "testdata")
+	src, err := os.ReadFile(filepath.Join(fixture, "junk_v23.mp3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	audio, err := os.ReadFile(filepath.Join(fixture, "audio.raw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dead.mp3" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write(src)
+	}))
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+
+	vault := fakeVault{
+		{Artist: "Nero", Title: "Promises", Link: srv.URL + "/a.mp3", Lyrics: "stored lyrics"},
+		{Artist: "Nero", Title: "Gone", Link: srv.URL + "/dead.mp3"},
+		{Artist: "Sigur Rós", Title: "Hoppípolla", Link: srv.URL + "/b.mp3"},
+		{Artist: "Nero", Title: "Over The Cap", Link: srv.URL + "/c.mp3"},
+	}
+	h := &RecommendHandler{client: srv.Client(), upstream: srv.Client(), search: noSearch{}}
+	h.AllowStreamHost(u.Host)
+	h.UseVault(vault)
+	h.UseArchives(nil, 3)
+	app := fiber.New()
+	app.Get("/favorites/:userId/zip", h.GetFavoritesZip)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/favorites/9b2f3c1e-7a0d-4c5e-8f1a-2b3c4d5e6f70/zip", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/zip" {
+		t.Fatalf("status %d: %s", resp.StatusCode, body)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	var names []string
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+		names = append(names, f.Name)
+	}
+	want := []string{"001 Nero - Promises.mp3", "003 Sigur Rós - Hoppípolla.mp3", "playlist.m3u", "failed.txt"}
+	if strings.Join(names, "|") != strings.Join(want, "|") {
+		t.Fatalf("entries %q", names)
+	}
+	track := files["001 Nero - Promises.mp3"]
+	if !bytes.HasPrefix(track, []byte("ID3\x03")) || !bytes.HasSuffix(track, audio) || !bytes.Contains(track, []byte("USLT")) {
+		t.Fatal("track not retagged")
+	}
+	if m3u := string(files["playlist.m3u"]); !strings.Contains(m3u, "#EXTINF:-1,Nero - Promises\n001 Nero - Promises.mp3\n") || strings.Contains(m3u, "Gone") {
+		t.Fatalf("m3u %q", m3u)
+	}
+	failed := string(files["failed.txt"])
+	if !strings.Contains(failed, "Nero - Gone:") || !strings.Contains(failed, "Nero - Over The Cap: over the 3-track limit") {
+		t.Fatalf("failed.txt %q", failed)
+	}
+}
+
+func TestZipNeverFetchesStoredCoverURL(t *testing.T) {
+	var fetched []string
+	h := &RecommendHandler{client: &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		fetched = append(fetched, r.URL.String())
+		return nil, errors.New("offline")
+	})}}
+	tags := h.downloadTags(t.Context(), "Nero", "Promises", "https://169.254.169.254/latest/meta-data/", "")
+	if len(fetched) != 0 || tags.Cover != nil {
+		t.Fatalf("fetched %v", fetched)
+	}
+}
@@ -31,6 +31,9 @@ func HandleError(c fiber.Ctx, err error) error {
 	case errors.Is(err, domain.ErrUnavailable):
 		status = http.StatusServiceUnavailable
 		msg = domain.ErrUnavailable.Error()
+	case errors.Is(err, domain.ErrLimitReached):
+		status = http.StatusTooManyRequests
+		msg = domain.ErrLimitReached.Error()
 	}
 
 	return c.Status(status).JSON(fiber.Map{"error": msg})
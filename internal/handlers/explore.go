@@ -2,53 +2,55 @@ package handlers
 
 import (
 	"bufio"
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/ports"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/charts"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/providers"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
 	"github.com/gofiber/fiber/v3"
 )
 
+// GET /explore/cache?country= → that country's chart cache (age, TTL, shelves).
 func (h *RecommendHandler) GetExploreCache(c fiber.Ctx) error {
 	c.Set("Cache-Control", "no-store")
-	return c.JSON(h.exploreCacheStatus())
+	return c.JSON(h.exploreCacheStatus(h.exploreRegionFor(c.Query("country"))))
 }
 
-// GET /explore?refresh=1 → chart shelves (Romania / Worldwide / vibes), server-cached 24h.
+// GET /explore?country=&refresh=1 → chart shelves for the country (code or name; empty =
+// server default, unknown = worldwide only). Shelves are cached 24h each, kept across
+// restarts and rebuilt ahead of expiry by RunExplore.
 // GET /explore?stream=1 → NDJSON: meta, then one section line as each shelf is ready, then done.
 func (h *RecommendHandler) GetExplore(c fiber.Ctx) error {
-	if h.apiKey == "" {
-		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "LASTFM_API_KEY not set"})
-	}
+	region := h.exploreRegionFor(c.Query("country"))
 	refresh := c.Query("refresh") == "1" || strings.EqualFold(c.Query("refresh"), "true")
 	stream := c.Query("stream") == "1" || strings.EqualFold(c.Query("stream"), "true")
 	if stream {
-		return h.streamExplore(c, refresh)
+		return h.streamExplore(c, region, refresh)
 	}
 
 	if !refresh {
-		if sections, ok := h.exploreSnap(true); ok {
+		if sections, ok := h.exploreSnap(region, true); ok {
 			c.Set("Cache-Control", "public, max-age="+exploreCacheAge)
-			return c.JSON(fiber.Map{"country": exploreCountry, "sections": sections, "cached": true})
+			return c.JSON(fiber.Map{"country": region.name, "sections": h.signSections(sections), "cached": true})
 		}
 	}
 
 	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Context()), exploreStreamBudget)
 	defer cancel()
-	sections, err := h.coldExplore(ctx, refresh, nil)
+	sections, err := h.coldExplore(ctx, region, refresh, nil)
 	if err != nil || len(sections) == 0 {
 		// Serve last good payload even past TTL — better than empty Explore.
-		if stale, ok := h.exploreSnap(false); ok {
+		if stale, ok := h.exploreSnap(region, false); ok {
 			c.Set("Cache-Control", "public, max-age=60")
-			return c.JSON(fiber.Map{"country": exploreCountry, "sections": stale, "cached": true})
+			return c.JSON(fiber.Map{"country": region.name, "sections": h.signSections(stale), "cached": true})
 		}
 		if err != nil {
 			return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": "Couldn't load charts"})
@@ -56,7 +58,7 @@ func (h *RecommendHandler) GetExplore(c fiber.Ctx) error {
 		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "No playable charts right now"})
 	}
 	c.Set("Cache-Control", "public, max-age="+exploreCacheAge)
-	return c.JSON(fiber.Map{"country": exploreCountry, "sections": sections, "cached": false})
+	return c.JSON(fiber.Map{"country": region.name, "sections": h.signSections(sections), "cached": false})
 }
 
 type exploreStreamEvent struct {
@@ -67,7 +69,7 @@ type exploreStreamEvent struct {
 	Error   string          `json:"error,omitempty"`
 }
 
-func (h *RecommendHandler) streamExplore(c fiber.Ctx, refresh bool) error {
+func (h *RecommendHandler) streamExplore(c fiber.Ctx, region exploreRegion, refresh bool) error {
 	c.Set("Content-Type", "application/x-ndjson")
 	c.Set("Cache-Control", "no-cache")
 	c.Set("Connection", "keep-alive")
@@ -75,6 +77,10 @@ func (h *RecommendHandler) streamExplore(c fiber.Ctx, refresh bool) error {
 	return c.SendStreamWriter(func(w *bufio.Writer) {
 		enc := json.NewEncoder(w)
 		write := func(ev exploreStreamEvent) bool {
+			if ev.Section != nil && h.signer != nil {
+				signed := h.signSections([]ExploreSection{*ev.Section})[0]
+				ev.Section = &signed
+			}
 			if err := enc.Encode(ev); err != nil {
 				return false
 			}
@@ -83,8 +89,8 @@ func (h *RecommendHandler) streamExplore(c fiber.Ctx, refresh bool) error {
 		boolPtr := func(v bool) *bool { return &v }
 
 		if !refresh {
-			if sections, ok := h.exploreSnap(true); ok {
-				if !write(exploreStreamEvent{Type: "meta", Country: exploreCountry, Cached: boolPtr(true)}) {
+			if sections, ok := h.exploreSnap(region, true); ok {
+				if !write(exploreStreamEvent{Type: "meta", Country: region.name, Cached: boolPtr(true)}) {
 					return
 				}
 				for i := range sections {
@@ -98,7 +104,7 @@ func (h *RecommendHandler) streamExplore(c fiber.Ctx, refresh bool) error {
 			}
 		}
 
-		if !write(exploreStreamEvent{Type: "meta", Country: exploreCountry, Cached: boolPtr(false)}) {
+		if !write(exploreStreamEvent{Type: "meta", Country: region.name, Cached: boolPtr(false)}) {
 			return
 		}
 
@@ -109,7 +115,7 @@ func (h *RecommendHandler) streamExplore(c fiber.Ctx, refresh bool) error {
 
 		// Leader streams shelves as they resolve; followers wait on singleflight then flush.
 		var streamed int
-		sections, err, shared := h.coldExploreShared(ctx, refresh, func(sec ExploreSection) error {
+		sections, err, shared := h.coldExploreShared(ctx, region, refresh, func(sec ExploreSection) error {
 			streamed++
 			if !write(exploreStreamEvent{Type: "section", Section: &sec}) {
 				return context.Canceled
@@ -125,7 +131,7 @@ func (h *RecommendHandler) streamExplore(c fiber.Ctx, refresh bool) error {
 			}
 		}
 		if err != nil && len(sections) == 0 && streamed == 0 {
-			if stale, ok := h.exploreSnap(false); ok {
+			if stale, ok := h.exploreSnap(region, false); ok {
 				for i := range stale {
 					sec := stale[i]
 					if !write(exploreStreamEvent{Type: "section", Section: &sec}) {
@@ -139,7 +145,7 @@ func (h *RecommendHandler) streamExplore(c fiber.Ctx, refresh bool) error {
 			return
 		}
 		if len(sections) == 0 && streamed == 0 {
-			if stale, ok := h.exploreSnap(false); ok {
+			if stale, ok := h.exploreSnap(region, false); ok {
 				for i := range stale {
 					sec := stale[i]
 					if !write(exploreStreamEvent{Type: "section", Section: &sec}) {
@@ -156,86 +162,171 @@ func (h *RecommendHandler) streamExplore(c fiber.Ctx, refresh bool) error {
 	})
 }
 
-// coldExplore builds chart shelves once per cold window (singleflight).
-func (h *RecommendHandler) coldExplore(ctx context.Context, refresh bool, onSection func(ExploreSection) error) ([]ExploreSection, error) {
-	sections, err, _ := h.coldExploreShared(ctx, refresh, onSection)
+// coldExplore builds a region's chart shelves once per cold window (singleflight).
+func (h *RecommendHandler) coldExplore(ctx context.Context, region exploreRegion, refresh bool, onSection func(ExploreSection) error) ([]ExploreSection, error) {
+	sections, err, _ := h.coldExploreShared(ctx, region, refresh, onSection)
 	return sections, err
 }
 
 func (h *RecommendHandler) coldExploreShared(
 	ctx context.Context,
+	region exploreRegion,
 	refresh bool,
 	onSection func(ExploreSection) error,
 ) ([]ExploreSection, error, bool) {
-	key := "explore"
+	key := "explore|" + region.key
 	if refresh {
-		key = "explore-refresh"
+		key += "|refresh"
 	}
 	v, err, shared := h.exploreSF.Do(key, func() (any, error) {
-		sections, err := h.buildExplore(ctx, onSection)
-		// Keep a useful day cache even if one shelf fails — empty builds never overwrite.
-		if len(sections) >= 1 {
-			h.exploreStore(sections)
-		}
-		return sections, err
+		return h.buildExplore(ctx, region.shelves, onSection)
 	})
 	sections, _ := v.([]ExploreSection)
 	return sections, err, shared
 }
 
-type exploreJob struct {
-	id, title, subtitle string
-	q                   url.Values
+// exploreRegion is what one /explore?country= resolves to: cache key, display name
+// and the shelves to build (the country's own first, then worldwide).
+type exploreRegion struct {
+	key, name string
+	shelves   []exploreShelfRef
+}
+
+// exploreShelfRef is a shelf with its cache key: worldwide shelves are cached once,
+// not per country.
+type exploreShelfRef struct {
+	key   string
+	shelf domain.ExploreShelf
+}
+
+func shelfRefs(scope string, shelves []domain.ExploreShelf) []exploreShelfRef {
+	out := make([]exploreShelfRef, len(shelves))
+	for i, s := range shelves {
+		out[i] = exploreShelfRef{key: scope + "|" + s.ID, shelf: s}
+	}
+	return out
+}
+
+// defaultExploreCatalog is served unless EXPLORE_SHELVES_FILE says otherwise.
+var defaultExploreCatalog = domain.ExploreCatalog{
+	Countries: []domain.ExploreCountry{{
+		Code: "ro", Name: "Romania",
+		Shelves: []domain.ExploreShelf{
+			{ID: "romania", Title: "Romania", Subtitle: "Charts at home",
+				Source: charts.SourceLastFMGeo, Params: map[string]string{"country": "Romania"}},
+			// Closest free viral proxy for RO — YouTube Music trending via kworb.
+			{ID: "viral-ro", Title: "Viral Romania", Subtitle: "Rising right now",
+				Source: charts.SourceKworbYouTube, Params: map[string]string{"country": "ro"}},
+		},
+	}},
+	Worldwide: []domain.ExploreShelf{
+		{ID: "worldwide", Title: "Worldwide", Subtitle: "Global chart", Source: charts.SourceLastFMGlobal},
+		{ID: "pop", Title: "Pop", Subtitle: "Everywhere right now",
+			Source: charts.SourceLastFMTag, Params: map[string]string{"tag": "pop"}},
+		{ID: "hip-hop", Title: "Hip-Hop", Subtitle: "Worldwide vibe",
+			Source: charts.SourceLastFMTag, Params: map[string]string{"tag": "hip-hop"}},
+		{ID: "rock", Title: "Rock", Subtitle: "Worldwide vibe",
+			Source: charts.SourceLastFMTag, Params: map[string]string{"tag": "rock"}},
+		{ID: "electronic", Title: "Electronic", Subtitle: "Worldwide vibe",
+			Source: charts.SourceLastFMTag, Params: map[string]string{"tag": "electronic"}},
+	},
+}
+
+// UseExplore replaces the built-in shelves (an empty catalog keeps them) and sets the
+// country served when the client doesn't ask for one.
+func (h *RecommendHandler) UseExplore(catalog domain.ExploreCatalog, country string) {
+	if len(catalog.Countries) > 0 || len(catalog.Worldwide) > 0 {
+		h.exploreCatalog = &catalog
+	}
+	h.exploreCountry = strings.TrimSpace(country)
 }
 
-// buildExplore resolves shelves one at a time. onSection is called as each shelf is ready (stream path).
-func (h *RecommendHandler) buildExplore(ctx context.Context, onSection func(ExploreSection) error) ([]ExploreSection, error) {
-	limit := strconv.Itoa(exploreFetch)
-	jobs := []exploreJob{
-		{"romania", "Romania", "Charts at home", url.Values{
-			"method": {"geo.getTopTracks"}, "country": {exploreCountry}, "limit": {limit},
-		}},
-		// Closest free viral proxy for RO — YouTube Music trending via kworb.
-		{"viral-ro", "Viral Romania", "Rising right now", nil},
-		{"worldwide", "Worldwide", "Global chart", url.Values{
-			"method": {"chart.getTopTracks"}, "limit": {limit},
-		}},
-		{"pop", "Pop", "Everywhere right now", url.Values{
-			"method": {"tag.getTopTracks"}, "tag": {"pop"}, "limit": {limit},
-		}},
-		{"hip-hop", "Hip-Hop", "Worldwide vibe", url.Values{
-			"method": {"tag.getTopTracks"}, "tag": {"hip-hop"}, "limit": {limit},
-		}},
-		{"rock", "Rock", "Worldwide vibe", url.Values{
-			"method": {"tag.getTopTracks"}, "tag": {"rock"}, "limit": {limit},
-		}},
-		{"electronic", "Electronic", "Worldwide vibe", url.Values{
-			"method": {"tag.getTopTracks"}, "tag": {"electronic"}, "limit": {limit},
-		}},
+func (h *RecommendHandler) catalog() *domain.ExploreCatalog {
+	if h.exploreCatalog != nil {
+		return h.exploreCatalog
 	}
+	return &defaultExploreCatalog
+}
+
+func countryKey(c domain.ExploreCountry) string {
+	return strings.ToLower(cmp.Or(c.Code, c.Name))
+}
 
+// exploreRegionFor matches a country code or name (empty = default country); anything
+// else gets the worldwide shelves under their own cache key.
+func (h *RecommendHandler) exploreRegionFor(country string) exploreRegion {
+	catalog := h.catalog()
+	country = strings.TrimSpace(country)
+	if country == "" {
+		country = cmp.Or(h.exploreCountry, exploreDefaultCountry)
+	}
+	worldwide := shelfRefs(exploreWorldwide, catalog.Worldwide)
+	for _, c := range catalog.Countries {
+		if (c.Code != "" && strings.EqualFold(c.Code, country)) || (c.Name != "" && strings.EqualFold(c.Name, country)) {
+			return exploreRegion{
+				key:     countryKey(c),
+				name:    cmp.Or(c.Name, strings.ToUpper(c.Code)),
+				shelves: append(shelfRefs(countryKey(c), c.Shelves), worldwide...),
+			}
+		}
+	}
+	return exploreRegion{key: exploreWorldwide, name: "Worldwide", shelves: worldwide}
+}
+
+// exploreShelfRefs is every shelf in the catalog, each once.
+func (h *RecommendHandler) exploreShelfRefs() []exploreShelfRef {
+	catalog := h.catalog()
+	var out []exploreShelfRef
+	for _, c := range catalog.Countries {
+		out = append(out, shelfRefs(countryKey(c), c.Shelves)...)
+	}
+	return append(out, shelfRefs(exploreWorldwide, catalog.Worldwide)...)
+}
+
+// buildExplore resolves shelves one at a time; a shelf that fails keeps its last good
+// build. onSection is called as each shelf is ready (stream path).
+func (h *RecommendHandler) buildExplore(ctx context.Context, shelves []exploreShelfRef, onSection func(ExploreSection) error) ([]ExploreSection, error) {
 	// ponytail: one shelf at a time — parallel resolve floods providers and returns 1 song.
-	out := make([]ExploreSection, 0, len(jobs))
-	for _, job := range jobs {
+	out := make([]ExploreSection, 0, len(shelves))
+	for _, ref := range shelves {
 		if err := ctx.Err(); err != nil {
 			return out, err
 		}
-		sectionCtx, cancel := context.WithTimeout(ctx, exploreSectionBudget)
-		var pairs []lastfmPair
-		var err error
-		if job.id == "viral-ro" {
-			pairs, err = h.kworbROViral(sectionCtx)
-		} else {
-			pairs, err = h.lastfmTracks(sectionCtx, job.q, "tracks")
+		sec, ok := h.buildShelf(ctx, ref)
+		if !ok {
+			if sec, ok = h.exploreShelf(ref); !ok {
+				continue
+			}
+		}
+		out = append(out, sec)
+		if onSection != nil {
+			// Best-effort stream — a dropped client must not abort the shared cold build.
+			_ = onSection(sec)
 		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// buildShelf fetches and resolves one shelf and caches it; concurrent builds of the
+// same shelf (two countries, or a request and the scheduler) share one run. Empty
+// builds are not stored, so the previous shelf stays.
+func (h *RecommendHandler) buildShelf(ctx context.Context, ref exploreShelfRef) (ExploreSection, bool) {
+	v, _, _ := h.exploreSF.Do("shelf|"+ref.key, func() (any, error) {
+		// Shelf builds yield provider tokens to interactive search.
+		ctx := providers.WithLane(ctx, providers.LaneBackground)
+		sectionCtx, cancel := context.WithTimeout(ctx, exploreSectionBudget)
+		defer cancel()
+		pairs, err := h.shelfPairs(sectionCtx, ref.shelf)
 		if err != nil || len(pairs) == 0 {
-			cancel()
-			continue
+			utils.GetLogger().Debug("explore shelf fetch failed", "shelf", ref.key, "error", err)
+			return nil, nil
 		}
 		songs := h.resolveN(sectionCtx, pairs, lastfmPair{}, exploreCap, false, false)
-		cancel()
 		if len(songs) == 0 {
-			continue
+			return nil, nil
 		}
 		// Short cover budget after resolve — don't let iTunes eat the section timeout.
 		coverCtx, coverCancel := context.WithTimeout(context.WithoutCancel(ctx), exploreCoverBudget)
@@ -243,164 +334,131 @@ func (h *RecommendHandler) buildExplore(ctx context.Context, onSection func(Expl
 		coverCancel()
 
 		sec := ExploreSection{
-			ID: job.id, Title: job.title, Subtitle: job.subtitle, Songs: songs,
-		}
-		out = append(out, sec)
-		if onSection != nil {
-			// Best-effort stream — a dropped client must not abort the shared cold build.
-			_ = onSection(sec)
+			ID: ref.shelf.ID, Title: ref.shelf.Title, Subtitle: ref.shelf.Subtitle, Songs: songs,
 		}
-	}
-	if len(out) == 0 {
-		return nil, nil
-	}
-	return out, nil
+		h.exploreStore(ref, sec)
+		return sec, nil
+	})
+	sec, ok := v.(ExploreSection)
+	return sec, ok
 }
 
-// YouTube Music trending Romania via kworb (public HTML). Closest free stand-in for TikTok RO.
-const kworbROViralURL = "https://kworb.net/youtube/trending/ro.html"
+// UseChartSources replaces the built-in chart sources (charts.Defaults) shelves pick
+// from by name.
+func (h *RecommendHandler) UseChartSources(sources map[string]ports.IChartSource) {
+	h.charts = sources
+}
 
-func (h *RecommendHandler) kworbROViral(ctx context.Context) ([]lastfmPair, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, kworbROViralURL, nil)
-	if err != nil {
-		return nil, err
+// shelfPairs reads one shelf's chart from the source it names.
+func (h *RecommendHandler) shelfPairs(ctx context.Context, shelf domain.ExploreShelf) ([]lastfmPair, error) {
+	sources := h.charts
+	if sources == nil {
+		sources = charts.Defaults(h.client, h.apiKey)
 	}
-	req.Header.Set("User-Agent", "FindVibe/1.0")
-	req.Header.Set("Accept", "text/html")
-	res, err := h.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("kworb viral: HTTP %d", res.StatusCode)
+	src, ok := sources[shelf.Source]
+	if !ok {
+		return nil, fmt.Errorf("explore: unknown chart source %q", shelf.Source)
 	}
-	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	tracks, err := src.Chart(ctx, shelf.Params, exploreFetch)
 	if err != nil {
 		return nil, err
 	}
-	return parseKworbMusicPairs(string(body)), nil
+	out := make([]lastfmPair, len(tracks))
+	for i, t := range tracks {
+		out[i] = lastfmPair{artist: t.Artist, title: t.Title, duration: t.Duration}
+	}
+	return out, nil
 }
 
-var kworbAnchorRe = regexp.MustCompile(`(?i)<a[^>]*>([^<]+)</a>`)
-
-// parseKworbMusicPairs reads the music-only table (not overall trending with gaming/vlogs).
-func parseKworbMusicPairs(html string) []lastfmPair {
-	start := strings.Index(html, `class="music"`)
-	if start < 0 {
-		return nil
-	}
-	chunk := html[start:]
-	if i := strings.Index(chunk, "</table>"); i >= 0 {
-		chunk = chunk[:i]
-	}
-	seen := map[string]bool{}
-	out := make([]lastfmPair, 0, exploreFetch)
-	for _, m := range kworbAnchorRe.FindAllStringSubmatch(chunk, -1) {
-		if len(out) >= exploreFetch {
-			break
-		}
-		artist, title, ok := parseChartTitle(m[1])
-		if !ok {
+// exploreSnap assembles a region's cached shelves in catalog order. freshOnly misses
+// when any of them is past exploreTTL.
+func (h *RecommendHandler) exploreSnap(region exploreRegion, freshOnly bool) ([]ExploreSection, bool) {
+	h.exploreMu.Lock()
+	defer h.exploreMu.Unlock()
+	var out []ExploreSection
+	for _, ref := range region.shelves {
+		e, ok := h.exploreCache[ref.key]
+		if !ok || len(e.sec.Songs) == 0 {
 			continue
 		}
-		k := songKey(artist, title)
-		if k == "" || seen[k] {
-			continue
+		if freshOnly && time.Since(e.at) > exploreTTL {
+			return nil, false
 		}
-		seen[k] = true
-		out = append(out, lastfmPair{artist: artist, title: title})
+		out = append(out, ref.titled(e.sec))
 	}
-	return out
+	return out, len(out) > 0
 }
 
-// "VANILLA x ALEX VELEA - 7 din 7 | Official Video" → ("VANILLA", "7 din 7")
-func parseChartTitle(raw string) (artist, title string, ok bool) {
-	raw = strings.TrimSpace(htmlUnescape(raw))
-	if raw == "" {
-		return "", "", false
-	}
-	if i := strings.Index(raw, "|"); i >= 0 {
-		raw = strings.TrimSpace(raw[:i])
-	}
-	sep := " - "
-	i := strings.Index(raw, sep)
-	if i < 0 {
-		sep = " – "
-		i = strings.Index(raw, sep)
-	}
-	if i <= 0 {
-		return "", "", false
-	}
-	artist = chartPrimaryArtist(strings.TrimSpace(raw[:i]))
-	title = strings.TrimSpace(raw[i+len(sep):])
-	title = parenRe.ReplaceAllString(title, " ")
-	title = spaceRe.ReplaceAllString(strings.TrimSpace(title), " ")
-	if artist == "" || title == "" {
-		return "", "", false
-	}
-	return artist, title, true
-}
-
-func chartPrimaryArtist(a string) string {
-	for _, sep := range []string{" x ", " ❌", " ✘", " × ", " & ", " feat.", " ft.", " featuring "} {
-		if i := strings.Index(strings.ToLower(a), strings.ToLower(sep)); i > 0 {
-			return strings.TrimSpace(a[:i])
-		}
+// exploreShelf is one cached shelf, even past exploreTTL.
+func (h *RecommendHandler) exploreShelf(ref exploreShelfRef) (ExploreSection, bool) {
+	h.exploreMu.Lock()
+	defer h.exploreMu.Unlock()
+	e, ok := h.exploreCache[ref.key]
+	if !ok || len(e.sec.Songs) == 0 {
+		return ExploreSection{}, false
 	}
-	return strings.TrimSpace(a)
+	return ref.titled(e.sec), true
 }
 
-func htmlUnescape(s string) string {
-	s = strings.ReplaceAll(s, "&amp;", "&")
-	s = strings.ReplaceAll(s, "&quot;", `"`)
-	s = strings.ReplaceAll(s, "&#39;", "'")
-	s = strings.ReplaceAll(s, "&lt;", "<")
-	s = strings.ReplaceAll(s, "&gt;", ">")
-	return s
+// titled names a cached shelf from the current catalog (stored titles may be older).
+func (ref exploreShelfRef) titled(sec ExploreSection) ExploreSection {
+	sec.ID, sec.Title, sec.Subtitle = ref.shelf.ID, ref.shelf.Title, ref.shelf.Subtitle
+	return sec
 }
 
-func (h *RecommendHandler) exploreSnap(freshOnly bool) ([]ExploreSection, bool) {
+// exploreStore caches one shelf (keys come from the catalog, so the map stays small)
+// and writes it through to the store when there is one.
+func (h *RecommendHandler) exploreStore(ref exploreShelfRef, sec ExploreSection) {
+	now := time.Now()
 	h.exploreMu.Lock()
-	defer h.exploreMu.Unlock()
-	if len(h.exploreSections) == 0 {
-		return nil, false
+	if h.exploreCache == nil {
+		h.exploreCache = make(map[string]exploreEntry)
 	}
-	if freshOnly && time.Since(h.exploreAt) > exploreTTL {
-		return nil, false
-	}
-	out := make([]ExploreSection, len(h.exploreSections))
-	copy(out, h.exploreSections)
-	return out, true
-}
+	h.exploreCache[ref.key] = exploreEntry{sec: sec, at: now, due: exploreDue(now)}
+	h.exploreMu.Unlock()
 
-func (h *RecommendHandler) exploreStore(sections []ExploreSection) {
-	h.exploreMu.Lock()
-	defer h.exploreMu.Unlock()
-	h.exploreSections = append([]ExploreSection(nil), sections...)
-	h.exploreAt = time.Now()
+	if h.exploreRepo == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), exploreSaveBudget)
+	defer cancel()
+	if err := h.exploreRepo.SaveSnapshot(ctx, domain.ExploreSnapshot{
+		Key: ref.key, Title: sec.Title, Subtitle: sec.Subtitle, Songs: sec.Songs, BuiltAt: now,
+	}); err != nil {
+		utils.GetLogger().Warn("explore shelf save failed", "shelf", ref.key, "error", err)
+	}
 }
 
-// exploreCacheStatus is the authoritative Explore chart cache view (in-memory on the API).
-func (h *RecommendHandler) exploreCacheStatus() fiber.Map {
+// exploreCacheStatus is the authoritative Explore chart cache view for one region
+// (in-memory on the API); ages are the oldest shelf's.
+func (h *RecommendHandler) exploreCacheStatus(region exploreRegion) fiber.Map {
 	h.exploreMu.Lock()
 	defer h.exploreMu.Unlock()
 
 	ttlSec := int64(exploreTTL / time.Second)
-	shelves := make([]string, 0, len(h.exploreSections))
-	songs := 0
-	for _, sec := range h.exploreSections {
-		if t := strings.TrimSpace(sec.Title); t != "" {
+	shelves := make([]string, 0, len(region.shelves))
+	sections, songs := 0, 0
+	var oldest time.Time
+	for _, ref := range region.shelves {
+		e, ok := h.exploreCache[ref.key]
+		if !ok || len(e.sec.Songs) == 0 {
+			continue
+		}
+		if t := strings.TrimSpace(ref.shelf.Title); t != "" {
 			shelves = append(shelves, t)
-		} else if id := strings.TrimSpace(sec.ID); id != "" {
+		} else if id := strings.TrimSpace(ref.shelf.ID); id != "" {
 			shelves = append(shelves, id)
 		}
-		songs += len(sec.Songs)
+		sections++
+		songs += len(e.sec.Songs)
+		if oldest.IsZero() || e.at.Before(oldest) {
+			oldest = e.at
+		}
 	}
 
-	if len(h.exploreSections) == 0 || h.exploreAt.IsZero() {
+	if sections == 0 || oldest.IsZero() {
 		return fiber.Map{
-			"country":          exploreCountry,
+			"country":          region.name,
 			"cached":           false,
 			"fresh":            false,
 			"createdAt":        nil,
@@ -414,15 +472,15 @@ func (h *RecommendHandler) exploreCacheStatus() fiber.Map {
 		}
 	}
 
-	age := time.Since(h.exploreAt)
+	age := time.Since(oldest)
 	remaining := exploreTTL - age
 	if remaining < 0 {
 		remaining = 0
 	}
-	created := h.exploreAt.UTC().Format(time.RFC3339)
-	expires := h.exploreAt.Add(exploreTTL).UTC().Format(time.RFC3339)
+	created := oldest.UTC().Format(time.RFC3339)
+	expires := oldest.Add(exploreTTL).UTC().Format(time.RFC3339)
 	return fiber.Map{
-		"country":          exploreCountry,
+		"country":          region.name,
 		"cached":           true,
 		"fresh":            remaining > 0,
 		"createdAt":        created,
@@ -430,7 +488,7 @@ func (h *RecommendHandler) exploreCacheStatus() fiber.Map {
 		"ttlSeconds":       ttlSec,
 		"remainingSeconds": int64(remaining / time.Second),
 		"ageSeconds":       int64(age / time.Second),
-		"sections":         len(h.exploreSections),
+		"sections":         sections,
 		"songs":            songs,
 		"shelves":          shelves,
 	}
@@ -492,3 +550,16 @@ func (h *RecommendHandler) recommendStore(key string, songs []domain.Song) {
 }
 
 // GET /similar-artists?artist= → {artists: string[]} from Last.fm artist.getSimilar.
+
+// signSections copies shelves with signed stream URLs (the cached snapshot stays unsigned).
+func (h *RecommendHandler) signSections(sections []ExploreSection) []ExploreSection {
+	if h.signer == nil {
+		return sections
+	}
+	out := make([]ExploreSection, len(sections))
+	for i, sec := range sections {
+		sec.Songs = h.signer.SignSongs(sec.Songs)
+		out[i] = sec
+	}
+	return out
+}
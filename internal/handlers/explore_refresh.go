@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/ports"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+)
+
+// UseExploreStore restores the last good shelves from repo (so Explore serves right
+// after a restart) and writes every new shelf build back to it. Stored shelves the
+// catalog no longer has are ignored. It returns how many shelves were restored.
+func (h *RecommendHandler) UseExploreStore(ctx context.Context, repo ports.IExploreRepository) (int, error) {
+	h.exploreRepo = repo
+	snaps, err := repo.GetSnapshots(ctx)
+	if err != nil {
+		return 0, err
+	}
+	known := map[string]bool{}
+	for _, ref := range h.exploreShelfRefs() {
+		known[ref.key] = true
+	}
+	h.exploreMu.Lock()
+	defer h.exploreMu.Unlock()
+	if h.exploreCache == nil {
+		h.exploreCache = make(map[string]exploreEntry)
+	}
+	restored := 0
+	for _, snap := range snaps {
+		if !known[snap.Key] || len(snap.Songs) == 0 {
+			continue
+		}
+		_, id, _ := strings.Cut(snap.Key, "|")
+		h.exploreCache[snap.Key] = exploreEntry{
+			sec: ExploreSection{ID: id, Title: snap.Title, Subtitle: snap.Subtitle, Songs: snap.Songs},
+			at:  snap.BuiltAt, due: exploreDue(snap.BuiltAt),
+		}
+		restored++
+	}
+	return restored, nil
+}
+
+// RunExplore keeps every shelf warm: each check rebuilds the shelves that are missing
+// or due, so requests rarely wait on a cold build. every ≤ 0 = never (shelves are
+// then only built on demand). Without LASTFM_API_KEY only the Last.fm shelves fail;
+// the other sources still build.
+func (h *RecommendHandler) RunExplore(ctx context.Context, every time.Duration) {
+	if every <= 0 {
+		return
+	}
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		if built, failed := h.refreshExplore(ctx); built+failed > 0 {
+			utils.GetLogger().Info("explore shelves refreshed", "built", built, "failed", failed)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// refreshExplore rebuilds due shelves one at a time. A failed rebuild keeps the
+// previous shelf and is retried after exploreRetry; a shelf that never built gets an
+// empty placeholder carrying that retry (readers skip shelves without songs).
+func (h *RecommendHandler) refreshExplore(ctx context.Context) (built, failed int) {
+	for _, ref := range h.exploreShelfRefs() {
+		if ctx.Err() != nil {
+			break
+		}
+		if !h.exploreShelfDue(ref.key, time.Now()) {
+			continue
+		}
+		if _, ok := h.buildShelf(ctx, ref); ok {
+			built++
+			continue
+		}
+		failed++
+		h.exploreMu.Lock()
+		if h.exploreCache == nil {
+			h.exploreCache = make(map[string]exploreEntry)
+		}
+		e := h.exploreCache[ref.key]
+		e.due = time.Now().Add(exploreRetry)
+		h.exploreCache[ref.key] = e
+		h.exploreMu.Unlock()
+	}
+	return built, failed
+}
+
+func (h *RecommendHandler) exploreShelfDue(key string, now time.Time) bool {
+	h.exploreMu.Lock()
+	defer h.exploreMu.Unlock()
+	e, ok := h.exploreCache[key]
+	return !ok || !now.Before(e.due)
+}
+
+// exploreDue is when a shelf built at `at` gets rebuilt: a jittered lead before exploreTTL.
+func exploreDue(at time.Time) time.Time {
+	return at.Add(exploreTTL - exploreRefreshLead - rand.N(exploreRefreshJitter))
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/charts"
+)
+
+type memExploreRepo struct {
+	mu    sync.Mutex
+	snaps map[string]domain.ExploreSnapshot
+}
+
+func (r *memExploreRepo) GetSnapshots(context.Context) ([]domain.ExploreSnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.ExploreSnapshot
+	for _, s := range r.snaps {
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (r *memExploreRepo) SaveSnapshot(_ context.Context, snap domain.ExploreSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.snaps[snap.Key] = snap
+	return nil
+}
+
+func TestExploreRestoreAndRefresh(t *testing.T) {
+	client, search, _ := fakeCharts()
+	catalog := domain.ExploreCatalog{Worldwide: []domain.ExploreShelf{
+		{ID: "worldwide", Title: "Worldwide", Source: charts.SourceLastFMGlobal},
+		{ID: "viral", Title: "Viral", Source: charts.SourceKworbYouTube, Params: map[string]string{"country": "us"}},
+	}}
+	old := []domain.Song{{Id: "old", Artist: "Old", Title: "Hit", Link: "https://x/old.mp3"}}
+	repo := &memExploreRepo{snaps: map[string]domain.ExploreSnapshot{
+		"worldwide|worldwide": {Key: "worldwide|worldwide", Title: "Renamed", Songs: old, BuiltAt: time.Now().Add(-time.Hour)},
+		"worldwide|viral":     {Key: "worldwide|viral", Title: "Viral", Songs: old, BuiltAt: time.Now().Add(-exploreTTL)},
+		"worldwide|dropped":   {Key: "worldwide|dropped", Title: "Gone", Songs: old, BuiltAt: time.Now()},
+	}}
+
+	h := &RecommendHandler{client: client, apiKey: "k", search: search}
+	h.UseExplore(catalog, "")
+	n, err := h.UseExploreStore(t.Context(), repo)
+	if err != nil || n != 2 {
+		t.Fatalf("restored %d, %v", n, err)
+	}
+	region := h.exploreRegionFor("")
+	got, ok := h.exploreSnap(region, false)
+	if !ok || len(got) != 2 || got[0].Title != "Worldwide" || got[0].Songs[0].Id != "old" {
+		t.Fatalf("restored %+v", got)
+	}
+
+	// Only the shelf near its TTL is due; the rebuild lands in the cache and the store.
+	if built, failed := h.refreshExplore(t.Context()); built != 1 || failed != 0 {
+		t.Fatalf("built %d failed %d", built, failed)
+	}
+	got, _ = h.exploreSnap(region, true)
+	if len(got) != 2 || got[0].Songs[0].Id != "old" || got[1].Songs[0].Id != "K0-Hit" {
+		t.Fatalf("after refresh %+v", got)
+	}
+	if snap := repo.snaps["worldwide|viral"]; snap.Songs[0].Id != "K0-Hit" || time.Since(snap.BuiltAt) > time.Minute {
+		t.Fatalf("stored %+v", snap)
+	}
+	if due := h.exploreCache["worldwide|viral"].due; due.Before(time.Now().Add(exploreTTL-exploreRefreshLead-exploreRefreshJitter-time.Minute)) || due.After(time.Now().Add(exploreTTL-exploreRefreshLead)) {
+		t.Fatalf("due %v", due)
+	}
+
+	// A failing rebuild keeps the previous shelf and backs off.
+	h.client = &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("down")
+	})}
+	e := h.exploreCache["worldwide|worldwide"]
+	e.due = time.Now().Add(-time.Minute)
+	h.exploreCache["worldwide|worldwide"] = e
+	if built, failed := h.refreshExplore(t.Context()); built != 0 || failed != 1 {
+		t.Fatalf("built %d failed %d", built, failed)
+	}
+	got, _ = h.exploreSnap(region, false)
+	if ids := []string{got[0].Songs[0].Id, got[1].Songs[0].Id}; !slices.Equal(ids, []string{"old", "K0-Hit"}) {
+		t.Fatalf("after failure %v", ids)
+	}
+	if h.exploreShelfDue("worldwide|worldwide", time.Now()) {
+		t.Fatal("failed shelf should wait exploreRetry")
+	}
+}
+
+func TestExploreRefreshBacksOffNeverBuiltShelf(t *testing.T) {
+	down := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("down")
+	})}
+	h := &RecommendHandler{client: down, apiKey: "k", search: stubSearch{}}
+	h.UseExplore(domain.ExploreCatalog{Worldwide: []domain.ExploreShelf{
+		{ID: "worldwide", Title: "Worldwide", Source: charts.SourceLastFMGlobal},
+	}}, "")
+
+	if built, failed := h.refreshExplore(t.Context()); built != 0 || failed != 1 {
+		t.Fatalf("built %d failed %d", built, failed)
+	}
+	// The placeholder holds the retry, so the next tick leaves the shelf alone.
+	if built, failed := h.refreshExplore(t.Context()); built+failed != 0 {
+		t.Fatalf("retried at once: built %d failed %d", built, failed)
+	}
+	region := h.exploreRegionFor("")
+	if got, ok := h.exploreSnap(region, false); ok {
+		t.Fatalf("placeholder served %+v", got)
+	}
+	if st := h.exploreCacheStatus(region); st["cached"] != false {
+		t.Fatalf("placeholder counted in status %v", st)
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/charts"
+	"github.com/gofiber/fiber/v3"
+)
+
+// fakeCharts answers every Last.fm chart with three tracks by <prefix>0…2 (G for
+// geo, W for the global chart) and kworb with K0…2; kworb paths are recorded.
+func fakeCharts() (*http.Client, stubSearch, func() []string) {
+	f := newFakeLastfm()
+	for _, prefix := range []string{"G", "W", "K"} {
+		for i := range 3 {
+			f.song(fmt.Sprintf("%s%d", prefix, i), "Hit")
+		}
+	}
+	var mu sync.Mutex
+	var kworb []string
+	f.onHost("kworb.net", func(r *http.Request) string {
+		mu.Lock()
+		kworb = append(kworb, r.URL.Path)
+		mu.Unlock()
+		var b strings.Builder
+		b.WriteString(`<table class="music">`)
+		for i := range 3 {
+			fmt.Fprintf(&b, `<tr><td><a href="#">K%d - Hit | Official Video</a></td></tr>`, i)
+		}
+		return b.String() + "</table>"
+	})
+	f.on("*", func(q url.Values) any {
+		prefix := "W"
+		if q.Get("method") == "geo.getTopTracks" && q.Get("country") == "Germany" {
+			prefix = "G"
+		}
+		var tracks []lastfmPair
+		for i := range 3 {
+			tracks = append(tracks, lastfmPair{artist: fmt.Sprintf("%s%d", prefix, i), title: "Hit"})
+		}
+		return map[string]any{"tracks": map[string]any{"track": lastfmTracks(tracks)}}
+	})
+	paths := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(kworb)
+	}
+	client, search := f.client()
+	return client, search, paths
+}
+
+func TestExploreCountries(t *testing.T) {
+	client, search, kworbPaths := fakeCharts()
+	h := &RecommendHandler{client: client, apiKey: "k", search: search}
+	h.UseExplore(domain.ExploreCatalog{
+		Countries: []domain.ExploreCountry{{Code: "de", Name: "Germany", Shelves: []domain.ExploreShelf{
+			{ID: "germany", Title: "Germany", Source: charts.SourceLastFMGeo, Params: map[string]string{"country": "Germany"}},
+			{ID: "viral-de", Title: "Viral Germany", Source: charts.SourceKworbYouTube, Params: map[string]string{"country": "de"}},
+		}}},
+		Worldwide: []domain.ExploreShelf{{ID: "worldwide", Title: "Worldwide", Source: charts.SourceLastFMGlobal}},
+	}, "de")
+	app := fiber.New()
+	app.Get("/explore", h.GetExplore)
+
+	type payload struct {
+		Country  string           `json:"country"`
+		Cached   bool             `json:"cached"`
+		Sections []ExploreSection `json:"sections"`
+	}
+	get := func(query string) payload {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/explore"+query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status %d", query, resp.StatusCode)
+		}
+		var p payload
+		if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	ids := func(p payload) []string {
+		var out []string
+		for _, s := range p.Sections {
+			out = append(out, s.ID)
+		}
+		return out
+	}
+
+	de := get("?country=Germany")
+	if de.Country != "Germany" || de.Cached || !slices.Equal(ids(de), []string{"germany", "viral-de", "worldwide"}) {
+		t.Fatalf("germany %+v", de)
+	}
+	if got := kworbPaths(); !slices.Equal(got, []string{"/youtube/trending/de.html"}) {
+		t.Fatalf("kworb fetched %v", got)
+	}
+	if de.Sections[0].Songs[0].Artist[:1] != "G" || de.Sections[1].Songs[0].Artist[:1] != "K" {
+		t.Fatalf("shelf songs %+v", de.Sections)
+	}
+
+	// No country = the configured default, already cached.
+	if def := get(""); def.Country != "Germany" || !def.Cached {
+		t.Fatalf("default %+v", def)
+	}
+
+	// Unknown countries get only the worldwide shelves — built once, shared with Germany.
+	ww := get("?country=zz")
+	if ww.Country != "Worldwide" || !ww.Cached || !slices.Equal(ids(ww), []string{"worldwide"}) {
+		t.Fatalf("worldwide %+v", ww)
+	}
+	if got := kworbPaths(); len(got) != 1 {
+		t.Fatalf("kworb refetched: %v", got)
+	}
+}
+
+func TestExploreWithoutLastfmKey(t *testing.T) {
+	client, search, _ := fakeCharts()
+	h := &RecommendHandler{client: client, search: search}
+	h.UseExplore(domain.ExploreCatalog{
+		Worldwide: []domain.ExploreShelf{
+			{ID: "worldwide", Title: "Worldwide", Source: charts.SourceLastFMGlobal},
+			{ID: "viral", Title: "Viral", Source: charts.SourceKworbYouTube, Params: map[string]string{"country": "us"}},
+		},
+	}, "")
+	app := fiber.New()
+	app.Get("/explore", h.GetExplore)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/explore", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var p struct {
+		Sections []ExploreSection `json:"sections"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	// Only the Last.fm shelf needs the key; kworb still builds.
+	if resp.StatusCode != http.StatusOK || len(p.Sections) != 1 || p.Sections[0].ID != "viral" {
+		t.Fatalf("status %d, sections %+v", resp.StatusCode, p.Sections)
+	}
+}
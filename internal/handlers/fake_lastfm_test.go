@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// fakeLastfmAPI is a scripted Last.fm for handler tests. Each method answers with
+// the JSON its script returns ("*" catches the rest; unscripted methods get {}),
+// other hosts answer raw bodies, and every track passed to song resolves through
+// the stubSearch client() hands out.
+type fakeLastfmAPI struct {
+	hits    map[string][]domain.Song
+	methods map[string]func(q url.Values) any
+	hosts   map[string]func(r *http.Request) string
+}
+
+func newFakeLastfm() *fakeLastfmAPI {
+	return &fakeLastfmAPI{
+		hits:    map[string][]domain.Song{},
+		methods: map[string]func(url.Values) any{},
+		hosts:   map[string]func(*http.Request) string{},
+	}
+}
+
+// song makes artist – title playable as id "<artist>-<title>".
+func (f *fakeLastfmAPI) song(artist, title string) {
+	f.hits[strings.ToLower(artist+" "+title)] = []domain.Song{{
+		Id: artist + "-" + title, Artist: artist, Title: title, Link: "https://x/" + artist + title + ".mp3",
+	}}
+}
+
+func (f *fakeLastfmAPI) on(method string, answer func(q url.Values) any) {
+	f.methods[method] = answer
+}
+
+func (f *fakeLastfmAPI) onHost(host string, answer func(r *http.Request) string) {
+	f.hosts[host] = answer
+}
+
+// client is the scripted transport plus a search that resolves every registered song.
+// Script before calling it: the maps are read concurrently afterwards.
+func (f *fakeLastfmAPI) client() (*http.Client, stubSearch) {
+	rt := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		var body string
+		if answer, ok := f.hosts[r.URL.Host]; ok {
+			body = answer(r)
+		} else {
+			q := r.URL.Query()
+			answer, ok := f.methods[q.Get("method")]
+			if !ok {
+				answer = f.methods["*"]
+			}
+			var v any = map[string]any{}
+			if answer != nil {
+				v = answer(q)
+			}
+			b, _ := json.Marshal(v)
+			body = string(b)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}}, nil
+	})
+	return &http.Client{Transport: rt}, stubSearch{hits: f.hits}
+}
+
+// lastfmTracks is the track row list every Last.fm chart and top-tracks call shares.
+func lastfmTracks(pairs []lastfmPair) []any {
+	rows := make([]any, 0, len(pairs))
+	for _, p := range pairs {
+		rows = append(rows, map[string]any{"name": p.title, "artist": map[string]string{"name": p.artist}})
+	}
+	return rows
+}
+
+// lastfmNames is an artist or tag row list.
+func lastfmNames(names []string) []map[string]string {
+	rows := make([]map[string]string, 0, len(names))
+	for _, n := range names {
+		rows = append(rows, map[string]string{"name": n})
+	}
+	return rows
+}
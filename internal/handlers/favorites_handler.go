@@ -5,12 +5,14 @@ import (
 
 	"github.com/andiq123/FindVibeFiber/internal/core/domain"
 	"github.com/andiq123/FindVibeFiber/internal/core/ports"
+	"github.com/andiq123/FindVibeFiber/internal/core/services"
 	"github.com/andiq123/FindVibeFiber/internal/utils"
 	"github.com/gofiber/fiber/v3"
 )
 
 type FavoritesHandler struct {
 	favoritesService ports.IFavoritesService
+	signer           *services.StreamSigner
 }
 
 func NewFavoritesHandler(favoritesService ports.IFavoritesService) *FavoritesHandler {
@@ -64,7 +66,7 @@ func (fh *FavoritesHandler) GetFavorites(c fiber.Ctx) error {
 		return HandleError(c, err)
 	}
 
-	return c.JSON(favorites)
+	return c.JSON(fh.signer.SignFavorites(favorites))
 }
 
 func (fh *FavoritesHandler) ReorderFavorites(c fiber.Ctx) error {
@@ -137,3 +139,15 @@ func (fh *FavoritesHandler) UpdateFavoriteLink(c fiber.Ctx) error {
 	}
 	return c.SendStatus(http.StatusNoContent)
 }
+
+// POST /favorites/:songId/play → 204; counts a vault play (weights vault radio).
+func (fh *FavoritesHandler) RecordFavoritePlay(c fiber.Ctx) error {
+	songId := c.Params("songId")
+	if err := utils.ValidateSongID(songId); err != nil {
+		return HandleError(c, err)
+	}
+	if err := fh.favoritesService.RecordFavoritePlay(c.Context(), songId); err != nil {
+		return HandleError(c, err)
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/andiq123/FindVibeFiber/internal/core/services/providers"
 	"github.com/gofiber/fiber/v3"
 )
 
@@ -69,13 +70,33 @@ var musicSources = []sourceSpec{
 }
 
 type HealthHandler struct {
-	client *http.Client
+	client   *http.Client
+	limiters []*providers.TokenBucket
 }
 
 func NewHealthHandler(client *http.Client) *HealthHandler {
 	return &HealthHandler{client: client}
 }
 
+// WithLimiters exposes provider token buckets in health output.
+func (hh *HealthHandler) WithLimiters(limiters []*providers.TokenBucket) *HealthHandler {
+	hh.limiters = limiters
+	return hh
+}
+
+// GET /health/limits → per-provider bucket saturation (no upstream probes).
+func (hh *HealthHandler) GetLimits(c fiber.Ctx) error {
+	return c.JSON(fiber.Map{"limits": hh.limitStats()})
+}
+
+func (hh *HealthHandler) limitStats() []providers.LimiterStats {
+	out := make([]providers.LimiterStats, 0, len(hh.limiters))
+	for _, l := range hh.limiters {
+		out = append(out, l.Stats())
+	}
+	return out
+}
+
 func (hh *HealthHandler) GetSources(c fiber.Ctx) error {
 	out := make([]sourceStatus, len(musicSources))
 	var wg sync.WaitGroup
@@ -87,7 +108,7 @@ func (hh *HealthHandler) GetSources(c fiber.Ctx) error {
 		}(i, s)
 	}
 	wg.Wait()
-	return c.JSON(fiber.Map{"sources": out})
+	return c.JSON(fiber.Map{"sources": out, "limits": hh.limitStats()})
 }
 
 func (hh *HealthHandler) probe(s sourceSpec) sourceStatus {
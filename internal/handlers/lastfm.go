@@ -93,13 +93,33 @@ func (h *RecommendHandler) pairsFromSimilarArtists(ctx context.Context, artist,
 }
 
 func (h *RecommendHandler) lastfmSimilarArtists(ctx context.Context, artist string) ([]string, error) {
-	q := url.Values{
-		"method":  {"artist.getSimilar"},
-		"artist":  {artist},
-		"api_key": {h.apiKey},
-		"format":  {"json"},
-		"limit":   {"10"},
+	names, err := h.lastfmArtistNames(ctx, url.Values{
+		"method": {"artist.getSimilar"},
+		"artist": {artist},
+		"limit":  {"10"},
+	}, "similarartists")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(names))
+	seed := utils.NormalizeString(artist)
+	for _, n := range names {
+		if utils.NormalizeString(n) != seed {
+			out = append(out, n)
+		}
 	}
+	return out, nil
+}
+
+// lastfmArtistNames reads an artist list under root (similarartists, topartists, …).
+func (h *RecommendHandler) lastfmArtistNames(ctx context.Context, q url.Values, root string) ([]string, error) {
+	return h.lastfmNames(ctx, q, root, "artist")
+}
+
+// lastfmNames reads the names of root.item rows ({name} objects, or a single one).
+func (h *RecommendHandler) lastfmNames(ctx context.Context, q url.Values, root, item string) ([]string, error) {
+	q.Set("api_key", h.apiKey)
+	q.Set("format", "json")
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://ws.audioscrobbler.com/2.0/?"+q.Encode(), nil)
 	if err != nil {
 		return nil, err
@@ -113,26 +133,23 @@ func (h *RecommendHandler) lastfmSimilarArtists(ctx context.Context, artist stri
 		return nil, nil
 	}
 
-	var data struct {
-		SimilarArtists struct {
-			Artist json.RawMessage `json:"artist"`
-		} `json:"similarartists"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
 		return nil, err
 	}
-	artists, err := decodeLastfmArtistList(data.SimilarArtists.Artist)
+	var wrap map[string]json.RawMessage
+	if block, ok := raw[root]; !ok || json.Unmarshal(block, &wrap) != nil {
+		return nil, nil
+	}
+	artists, err := decodeLastfmArtistList(wrap[item])
 	if err != nil {
 		return nil, err
 	}
 	out := make([]string, 0, len(artists))
-	seed := utils.NormalizeString(artist)
 	for _, a := range artists {
-		n := strings.TrimSpace(a.Name)
-		if n == "" || utils.NormalizeString(n) == seed {
-			continue
+		if n := strings.TrimSpace(a.Name); n != "" {
+			out = append(out, n)
 		}
-		out = append(out, n)
 	}
 	return out, nil
 }
@@ -314,14 +331,15 @@ func (h *RecommendHandler) lastfmTracks(ctx context.Context, q url.Values, root
 		if a == "" || n == "" {
 			continue
 		}
-		out = append(out, lastfmPair{a, n})
+		out = append(out, lastfmPair{artist: a, title: n, duration: int(t.Duration)})
 	}
 	return out, nil
 }
 
 type lastfmTrack struct {
-	Name   string `json:"name"`
-	Artist struct {
+	Name     string        `json:"name"`
+	Duration utils.FlexInt `json:"duration"`
+	Artist   struct {
 		Name string `json:"name"`
 	} `json:"artist"`
 }
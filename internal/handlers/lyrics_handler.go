@@ -68,6 +68,20 @@ func (h *LyricsHandler) GetLyrics(c fiber.Ctx) error {
 	return h.writeLyrics(c, text, code)
 }
 
+// Lyrics is the cached plain-text lookup behind /lyrics ("" when none or unreachable).
+func (h *LyricsHandler) Lyrics(ctx context.Context, artist, title string) string {
+	key := lyricsCacheKey(artist, title)
+	if text, _, ok := h.cacheGet(key); ok {
+		return text
+	}
+	text, code, err := h.lookup(ctx, artist, title)
+	if err != nil {
+		return ""
+	}
+	h.cachePut(key, text, code)
+	return text
+}
+
 func (h *LyricsHandler) writeLyrics(c fiber.Ctx, text, code string) error {
 	switch code {
 	case "instrumental":
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/services"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// UseFeedback stores likes / dislikes / skips per user and steers radio with them.
+func (h *RecommendHandler) UseFeedback(fs *services.FeedbackService) {
+	h.feedback = fs
+}
+
+type feedbackRequest struct {
+	UserID  string `json:"userId"`
+	Session string `json:"session"`
+	SongID  string `json:"songId"`
+	Artist  string `json:"artist"`
+	Title   string `json:"title"`
+	Kind    string `json:"kind"` // like | dislike | skip | clear
+}
+
+// POST /recommend/feedback {userId?, session?, songId?, artist?, title?, kind}
+// Against a session the rest of that station reacts at once (songId is enough for a
+// track it served); with a userId it's stored and steers that user's later radios.
+func (h *RecommendHandler) PostFeedback(c fiber.Ctx) error {
+	var req feedbackRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	switch req.Kind {
+	case domain.FeedbackLike, domain.FeedbackDislike, domain.FeedbackSkip, domain.FeedbackClear:
+	default:
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "kind must be like, dislike, skip or clear"})
+	}
+	if req.UserID != "" {
+		if err := utils.ValidateUserID(req.UserID); err != nil {
+			return HandleError(c, err)
+		}
+	}
+	if req.Session == "" && req.UserID == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "session or userId is required"})
+	}
+
+	var s *radioSession
+	if req.Session != "" {
+		if s = h.radioSessionFor(req.Session, req.UserID); s == nil && req.UserID == "" {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Radio session not found"})
+		}
+	}
+	p := lastfmPair{artist: strings.TrimSpace(req.Artist), title: strings.TrimSpace(req.Title)}
+	if (p.artist == "" || p.title == "") && s != nil {
+		if served, ok := s.servedSong(req.SongID); ok {
+			p = served
+		}
+	}
+	if songKey(p.artist, p.title) == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "artist and title (or a songId from the session) are required"})
+	}
+
+	if s != nil {
+		if req.Kind == domain.FeedbackSkip && req.SongID != "" {
+			s.skip(req.SongID) // counts once per served song
+		} else {
+			s.note(req.Kind, p)
+		}
+	}
+	if req.UserID != "" && h.feedback != nil {
+		if err := h.feedback.Record(c.Context(), req.UserID, req.Kind, p.artist, p.title); err != nil {
+			return HandleError(c, err)
+		}
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// feedbackProfile is user's stored feedback (nil without a user, store or on error —
+// radio then just isn't steered).
+func (h *RecommendHandler) feedbackProfile(ctx context.Context, user string) *services.FeedbackProfile {
+	if h.feedback == nil || user == "" {
+		return nil
+	}
+	p, err := h.feedback.Profile(ctx, user)
+	if err != nil {
+		utils.GetLogger().Debug("feedback profile read failed", "error", err)
+		return nil
+	}
+	return p
+}
+
+// recordFeedback stores implicit feedback (session ?skip=); failures are only logged.
+func (h *RecommendHandler) recordFeedback(ctx context.Context, user, kind string, p lastfmPair) {
+	if h.feedback == nil || user == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := h.feedback.Record(ctx, user, kind, p.artist, p.title); err != nil {
+		utils.GetLogger().Debug("feedback record failed", "kind", kind, "error", err)
+	}
+}
+
+// steerRadio applies the ?userId= listener's feedback to a shared, cached radio batch.
+func (h *RecommendHandler) steerRadio(c fiber.Ctx, radio bool, songs []domain.Song) []domain.Song {
+	if !radio || h.feedback == nil {
+		return songs
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 3*time.Second)
+	defer cancel()
+	if p := h.feedbackProfile(ctx, streamUser(c)); p != nil {
+		return steerSongs(songs, p)
+	}
+	return songs
+}
+
+// steerPairs / steerSongs drop tracks the listener disliked or skipped early and move
+// liked artists forward, disliked ones back; order is otherwise kept. Always a copy.
+func steerPairs(pairs []lastfmPair, p *services.FeedbackProfile) []lastfmPair {
+	return steer(pairs, p, func(x lastfmPair) (string, string) { return x.artist, x.title })
+}
+
+func steerSongs(songs []domain.Song, p *services.FeedbackProfile) []domain.Song {
+	return steer(songs, p, func(s domain.Song) (string, string) { return s.Artist, s.Title })
+}
+
+func steer[T any](items []T, p *services.FeedbackProfile, track func(T) (artist, title string)) []T {
+	out := make([]T, 0, len(items))
+	for _, it := range items {
+		if a, t := track(it); !p.Dropped(a, t) {
+			out = append(out, it)
+		}
+	}
+	if p == nil {
+		return out
+	}
+	rank := func(it T) int {
+		a, _ := track(it)
+		return cmp.Compare(p.ArtistScore(a), 0)
+	}
+	slices.SortStableFunc(out, func(a, b T) int { return rank(b) - rank(a) })
+	return out
+}
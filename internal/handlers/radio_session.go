@@ -0,0 +1,460 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/services"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+const (
+	radioSessionIdle   = 2 * time.Hour  // expiry slides forward on every batch
+	radioSessionMaxAge = 12 * time.Hour // hard stop however active
+	radioSessionCap    = 512
+	// Artists walked outward from the seed before the station is considered done.
+	radioSessionMaxHops = 60
+	// Artists expanded per batch once it has something to send — each hop is two
+	// Last.fm calls.
+	radioSessionHopsPerBatch = 6
+	// An artist skipped this often is dropped for the rest of the session.
+	radioSkipArtistLimit = 2
+	// Tag stations page this deep into tag.getTopTracks / getTopArtists at most.
+	radioTagMaxPages = 20
+)
+
+var errRadioSessionEnded = errors.New("radio session ended")
+
+// radioStart is what a new session station grows from: a seed track, tags or an artist.
+type radioStart struct {
+	seed   lastfmPair
+	tags   []string
+	artist string
+	mix    artistMix
+}
+
+func (st radioStart) ok() bool {
+	return (st.seed.artist != "" && st.seed.title != "") || len(st.tags) > 0 || st.artist != ""
+}
+
+// radioSession is one listener's station: every track it has sent is remembered so
+// a long session never repeats itself.
+type radioSession struct {
+	mu sync.Mutex // one batch at a time
+
+	id     string
+	user   string
+	seed   lastfmPair
+	tags   []string  // tag station instead of a seed track
+	artist string    // artist station instead of a seed track
+	mix    artistMix // artist stations only
+
+	served   map[string]bool // songKey of every track sent
+	tried    map[string]bool // songKey of every candidate pulled off the queue
+	queue    []lastfmPair    // candidates not tried yet
+	ready    []domain.Song   // resolved but not sent yet
+	frontier []string        // artists still to walk, nearest first
+	walked   map[string]bool
+	hops     int
+	tagPage  int // last tag page queued
+	tagsDone bool
+	started  bool
+	// Likes stored before this session; each joins the boosts once the walk reaches
+	// its artist.
+	pastLikes []lastfmPair
+
+	// Feedback can land mid-batch, so it has its own lock.
+	fbMu      sync.Mutex
+	servedIDs map[string]lastfmPair // song id → track, so feedback can name songs by id
+	skips     map[string]int        // normalized artist → early skips this session
+	boosts    []lastfmPair          // liked tracks whose neighborhoods jump the queue
+	profile   *services.FeedbackProfile
+
+	createdAt time.Time
+	expiresAt time.Time // guarded by h.radioMu, not mu — lookups read it mid-batch
+}
+
+// newRadioSession starts a station; profile is the user's stored feedback (nil = none).
+func newRadioSession(user string, seed lastfmPair, profile *services.FeedbackProfile, now time.Time) *radioSession {
+	if profile == nil {
+		profile = services.NewFeedbackProfile()
+	}
+	stored := make([]lastfmPair, 0, len(profile.Liked))
+	for _, l := range profile.Liked {
+		stored = append(stored, lastfmPair{artist: l.Artist, title: l.Title})
+	}
+	return &radioSession{
+		id:        uuid.NewString(),
+		user:      user,
+		seed:      seed,
+		served:    map[string]bool{songKey(seed.artist, seed.title): true},
+		tried:     map[string]bool{},
+		servedIDs: map[string]lastfmPair{},
+		skips:     map[string]int{},
+		walked:    map[string]bool{},
+		profile:   profile,
+		pastLikes: stored,
+		createdAt: now,
+		expiresAt: now.Add(radioSessionIdle),
+	}
+}
+
+func (s *radioSession) expired(now time.Time) bool {
+	return now.After(s.expiresAt) || now.Sub(s.createdAt) > radioSessionMaxAge
+}
+
+// servedSong is the track this session sent under songID.
+func (s *radioSession) servedSong(songID string) (lastfmPair, bool) {
+	s.fbMu.Lock()
+	defer s.fbMu.Unlock()
+	p, ok := s.servedIDs[songID]
+	return p, ok
+}
+
+// skip records an early skip of a song this session served (once per song).
+func (s *radioSession) skip(songID string) (lastfmPair, bool) {
+	s.fbMu.Lock()
+	p, ok := s.servedIDs[songID]
+	delete(s.servedIDs, songID)
+	s.fbMu.Unlock()
+	if ok {
+		s.note(domain.FeedbackSkip, p)
+	}
+	return p, ok
+}
+
+// note steers the rest of the session: likes queue the track's neighborhood next,
+// dislikes and skips drop the track and push its artist down (or out).
+func (s *radioSession) note(kind string, p lastfmPair) {
+	s.fbMu.Lock()
+	defer s.fbMu.Unlock()
+	switch kind {
+	case domain.FeedbackSkip:
+		s.skips[utils.NormalizeString(p.artist)]++
+	case domain.FeedbackLike:
+		s.boosts = append(s.boosts, p)
+	}
+	s.profile.Note(kind, p.artist, p.title)
+}
+
+// blocked: skipped too often this session, or the user's feedback rules it out.
+// Callers hold fbMu.
+func (s *radioSession) blocked(artist, title string) bool {
+	return s.skips[utils.NormalizeString(artist)] >= radioSkipArtistLimit || s.profile.Dropped(artist, title)
+}
+
+// take pops up to n untried candidates, dropping served tracks and skipped artists.
+// A candidate that fails to resolve isn't tried again this session.
+func (s *radioSession) take(n int) []lastfmPair {
+	s.fbMu.Lock()
+	defer s.fbMu.Unlock()
+	var out []lastfmPair
+	for len(s.queue) > 0 && len(out) < n {
+		p := s.queue[0]
+		s.queue = s.queue[1:]
+		k := songKey(p.artist, p.title)
+		if k == "" || s.served[k] || s.tried[k] || s.blocked(p.artist, p.title) {
+			continue
+		}
+		s.tried[k] = true
+		out = append(out, p)
+	}
+	return out
+}
+
+// exhausted: nothing queued and nowhere left to walk (or no tag pages left).
+func (s *radioSession) exhausted() bool {
+	return s.started && len(s.queue) == 0 && !s.canExtend()
+}
+
+// canExtend: extendRadioSession still has somewhere to go.
+func (s *radioSession) canExtend() bool {
+	if !s.started {
+		return true
+	}
+	if len(s.tags) > 0 {
+		return !s.tagsDone && s.tagPage < radioTagMaxPages
+	}
+	return len(s.frontier) > 0 && s.hops < radioSessionMaxHops
+}
+
+// radioSessionFor returns a live session (nil if unknown, expired or someone else's).
+func (h *RecommendHandler) radioSessionFor(id, user string) *radioSession {
+	if id == "" {
+		return nil
+	}
+	h.radioMu.Lock()
+	defer h.radioMu.Unlock()
+	s, ok := h.radioSessions[id]
+	if !ok {
+		return nil
+	}
+	if s.expired(time.Now()) {
+		delete(h.radioSessions, id)
+		return nil
+	}
+	if s.user != user {
+		return nil
+	}
+	return s
+}
+
+func (h *RecommendHandler) radioSessionStore(s *radioSession) {
+	h.radioMu.Lock()
+	defer h.radioMu.Unlock()
+	if h.radioSessions == nil {
+		h.radioSessions = make(map[string]*radioSession)
+	}
+	if len(h.radioSessions) >= radioSessionCap {
+		now := time.Now()
+		var oldest *radioSession
+		for id, e := range h.radioSessions {
+			if e.expired(now) {
+				delete(h.radioSessions, id)
+			} else if oldest == nil || e.expiresAt.Before(oldest.expiresAt) {
+				oldest = e
+			}
+		}
+		if len(h.radioSessions) >= radioSessionCap && oldest != nil {
+			delete(h.radioSessions, oldest.id)
+		}
+	}
+	h.radioSessions[s.id] = s
+}
+
+// touchRadioSession slides s's idle expiry after a batch.
+func (h *RecommendHandler) touchRadioSession(s *radioSession) {
+	h.radioMu.Lock()
+	s.expiresAt = time.Now().Add(radioSessionIdle)
+	h.radioMu.Unlock()
+}
+
+func (h *RecommendHandler) endRadioSession(id string) {
+	h.radioMu.Lock()
+	delete(h.radioSessions, id)
+	h.radioMu.Unlock()
+}
+
+// getRadioSession serves the next batch of a session station (?session=).
+// session=new (or an unknown/expired id, when a seed track or tags are given) starts
+// one; the id comes back in X-Radio-Session. ?skip=id1,id2 reports early skips from
+// the previous batch.
+func (h *RecommendHandler) getRadioSession(c fiber.Ctx, start radioStart, refresh bool) error {
+	user := streamUser(c)
+	s := h.radioSessionFor(c.Query("session"), user)
+	if s == nil {
+		if !start.ok() {
+			return c.Status(http.StatusGone).JSON(fiber.Map{"error": "Radio session ended"})
+		}
+		ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+		profile := h.feedbackProfile(ctx, user)
+		cancel()
+		s = newRadioSession(user, start.seed, profile, time.Now())
+		s.tags, s.artist, s.mix = start.tags, start.artist, start.mix
+		h.radioSessionStore(s)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ctx, cancel := context.WithTimeout(c.Context(), 30*time.Second)
+	defer cancel()
+	for id := range strings.SplitSeq(c.Query("skip"), ",") {
+		if p, ok := s.skip(strings.TrimSpace(id)); ok {
+			h.recordFeedback(ctx, user, domain.FeedbackSkip, p)
+		}
+	}
+	songs, err := h.nextRadioBatch(ctx, s, radioResolveCap, refresh)
+	c.Set("X-Radio-Session", s.id)
+	c.Set("Cache-Control", "no-store")
+	if errors.Is(err, errRadioSessionEnded) {
+		h.endRadioSession(s.id)
+		return c.Status(http.StatusGone).JSON(fiber.Map{"error": "Radio session ended"})
+	}
+	if err == errRecommendEmpty {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Couldn't build a radio for this track"})
+	}
+	if err != nil {
+		return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": "Radio lookup failed. Try again."})
+	}
+	h.touchRadioSession(s)
+	return c.JSON(h.signer.SignSongs(songs))
+}
+
+// DELETE /recommend/session/:id → ends a radio session early.
+func (h *RecommendHandler) DeleteRadioSession(c fiber.Ctx) error {
+	if s := h.radioSessionFor(c.Params("id"), streamUser(c)); s != nil {
+		h.endRadioSession(s.id)
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// nextRadioBatch resolves up to n tracks the session hasn't served, walking the
+// similar-artist graph further out whenever the queue runs low.
+func (h *RecommendHandler) nextRadioBatch(ctx context.Context, s *radioSession, n int, refresh bool) ([]domain.Song, error) {
+	ready := s.ready
+	s.ready = nil
+	out := s.admit(nil, ready, n)
+	hops := 0
+	for len(out) < n && ctx.Err() == nil {
+		h.boostRadioSession(ctx, s, refresh)
+		for len(s.queue) < n*2 && (hops < radioSessionHopsPerBatch || len(out) == 0) && s.canExtend() {
+			if err := h.extendRadioSession(ctx, s, refresh); err != nil {
+				if len(out) > 0 {
+					return out, nil
+				}
+				return nil, err
+			}
+			hops++
+		}
+		batch := s.take(n * 2)
+		if len(batch) == 0 {
+			if (hops < radioSessionHopsPerBatch || len(out) == 0) && s.canExtend() {
+				continue // everything queued was tried already; walk on
+			}
+			break
+		}
+		// Candidates are tried once, so resolve them all and keep the surplus.
+		resolved := h.resolveN(ctx, batch, s.seed, len(batch), refresh, s.artist != "")
+		if s.artist != "" {
+			resolved = capSongRuns(resolved, s.mix.maxRun)
+		}
+		out = s.admit(out, resolved, n)
+	}
+	if len(out) == 0 {
+		if s.exhausted() {
+			return nil, errRadioSessionEnded
+		}
+		return nil, errRecommendEmpty
+	}
+	h.covers.FillSongs(ctx, out)
+	return out, nil
+}
+
+// admit appends songs not served yet (nor blocked) to out, up to n; the rest wait
+// in s.ready for the next batch.
+func (s *radioSession) admit(out, songs []domain.Song, n int) []domain.Song {
+	s.fbMu.Lock()
+	defer s.fbMu.Unlock()
+	for _, song := range songs {
+		k := songKey(song.Artist, song.Title)
+		if k == "" || s.served[k] || s.blocked(song.Artist, song.Title) {
+			continue
+		}
+		if len(out) >= n {
+			s.ready = append(s.ready, song)
+			continue
+		}
+		s.served[k] = true
+		s.servedIDs[song.Id] = lastfmPair{artist: song.Artist, title: song.Title}
+		out = append(out, song)
+	}
+	return out
+}
+
+// extendRadioSession: the first call queues the seed's own radio neighborhood; later
+// calls walk one artist off the frontier (breadth-first, so nearest artists first),
+// queue its top tracks and push its similar artists further back. Tag stations queue
+// the next tag page instead; artist stations start from their own/similar mix.
+func (h *RecommendHandler) extendRadioSession(ctx context.Context, s *radioSession, refresh bool) error {
+	if len(s.tags) > 0 {
+		pairs, err := h.tagPairs(ctx, s.tags, s.tagPage+1)
+		if err != nil {
+			return err
+		}
+		s.started = true
+		s.tagPage++
+		s.tagsDone = len(pairs) == 0
+		s.queue = append(s.queue, s.steer(pairs)...)
+		return nil
+	}
+	if !s.started && s.artist != "" {
+		pairs, err := h.artistPairs(ctx, s.artist, s.mix, refresh)
+		if err != nil {
+			return err
+		}
+		s.started = true
+		s.queue = append(s.queue, s.steer(pairs)...)
+		s.frontier = append(s.frontier, artistCandidates(s.artist)...)
+		return nil
+	}
+	if !s.started {
+		artists := artistCandidates(s.seed.artist)
+		pairs, err := h.pairsFor(ctx, s.seed, artists, true, refresh)
+		if err != nil {
+			return err
+		}
+		s.started = true
+		s.queue = append(s.queue, s.steer(pairs)...)
+		s.frontier = append(s.frontier, artists...)
+		return nil
+	}
+	for len(s.frontier) > 0 && s.hops < radioSessionMaxHops {
+		a := s.frontier[0]
+		s.frontier = s.frontier[1:]
+		na := utils.NormalizeString(a)
+		s.fbMu.Lock()
+		blocked := s.blocked(a, "")
+		s.fbMu.Unlock()
+		if s.walked[na] || blocked {
+			continue
+		}
+		s.walked[na] = true
+		s.hops++
+
+		similar, err := h.lastfmSimilarArtists(ctx, a)
+		if err != nil {
+			return err
+		}
+		for _, name := range similar {
+			if !s.walked[utils.NormalizeString(name)] {
+				s.frontier = append(s.frontier, name)
+			}
+		}
+		tops, err := h.lastfmArtistTop(ctx, a, "")
+		if err != nil {
+			return err
+		}
+		s.queue = append(s.queue, s.steer(tops)...)
+		return nil
+	}
+	return nil
+}
+
+// boostRadioSession puts the neighborhoods of tracks liked since the last batch — and
+// of stored likes whose artist the walk has reached — at the front of the queue.
+func (h *RecommendHandler) boostRadioSession(ctx context.Context, s *radioSession, refresh bool) {
+	s.fbMu.Lock()
+	liked := s.boosts
+	s.boosts = nil
+	s.fbMu.Unlock()
+	waiting := s.pastLikes[:0]
+	for _, p := range s.pastLikes {
+		if s.walked[utils.NormalizeString(p.artist)] {
+			liked = append(liked, p)
+		} else {
+			waiting = append(waiting, p)
+		}
+	}
+	s.pastLikes = waiting
+	for _, p := range liked {
+		pairs, err := h.pairsFor(ctx, p, artistCandidates(p.artist), true, refresh)
+		if err != nil {
+			utils.GetLogger().Debug("radio boost failed", "artist", p.artist, "error", err)
+			continue
+		}
+		s.queue = append(s.steer(pairs[:min(len(pairs), radioResolveCap)]), s.queue...)
+	}
+}
+
+func (s *radioSession) steer(pairs []lastfmPair) []lastfmPair {
+	s.fbMu.Lock()
+	defer s.fbMu.Unlock()
+	return steerPairs(pairs, s.profile)
+}
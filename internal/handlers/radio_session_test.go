@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/services"
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestRadioSessionNeverRepeats(t *testing.T) {
+	client, search := fakeLastfm(12)
+	h := &RecommendHandler{client: client, apiKey: "k", search: search}
+	app := fiber.New()
+	app.Get("/recommend", h.GetRecommend)
+
+	get := func(q url.Values) (*http.Response, []domain.Song) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/recommend?"+q.Encode(), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var songs []domain.Song
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&songs); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return resp, songs
+	}
+
+	resp, songs := get(url.Values{"mode": {"radio"}, "session": {"new"}, "artist": {"A0"}, "title": {"Song 0"}})
+	id := resp.Header.Get("X-Radio-Session")
+	if resp.StatusCode != http.StatusOK || id == "" || len(songs) == 0 {
+		t.Fatalf("start: status %d id %q songs %d", resp.StatusCode, id, len(songs))
+	}
+
+	seen := map[string]bool{"a0|song 0": true}
+	farthest, batches := 0, 0
+	for {
+		batches++
+		for _, s := range songs {
+			k := strings.ToLower(s.Artist + "|" + s.Title)
+			if seen[k] {
+				t.Fatalf("batch %d repeated %s", batches, k)
+			}
+			seen[k] = true
+			i, _ := strconv.Atoi(strings.TrimPrefix(s.Artist, "A"))
+			farthest = max(farthest, i)
+		}
+		if batches > 20 {
+			t.Fatal("session never ended")
+		}
+		resp, songs = get(url.Values{"mode": {"radio"}, "session": {id}})
+		if resp.StatusCode == http.StatusGone {
+			break
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("batch %d: status %d", batches+1, resp.StatusCode)
+		}
+	}
+	if batches < 2 || farthest < 6 {
+		t.Fatalf("walked %d batches, farthest artist A%d", batches, farthest)
+	}
+
+	if resp, _ := get(url.Values{"mode": {"radio"}, "session": {id}}); resp.StatusCode != http.StatusGone {
+		t.Fatalf("ended session: status %d", resp.StatusCode)
+	}
+}
+
+// Two batches of one session at once: the second waits on the session lock while
+// its lookup reads the expiry the first one slides (run with -race).
+func TestRadioSessionConcurrentBatches(t *testing.T) {
+	client, search := fakeLastfm(80)
+	h := &RecommendHandler{client: client, apiKey: "k", search: search}
+	app := fiber.New()
+	app.Get("/recommend", h.GetRecommend)
+
+	get := func(q string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/recommend?mode=radio&"+q, nil))
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		resp.Body.Close()
+		return resp
+	}
+	resp := get("session=new&artist=A0&title=Song+0")
+	id := resp.Header.Get("X-Radio-Session")
+	if resp.StatusCode != http.StatusOK || id == "" {
+		t.Fatalf("start: status %d id %q", resp.StatusCode, id)
+	}
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Go(func() {
+			for range 2 {
+				if resp := get("session=" + id); resp != nil && resp.StatusCode != http.StatusOK {
+					t.Errorf("batch: status %d", resp.StatusCode)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestRadioSessionSkipsDropArtist(t *testing.T) {
+	s := newRadioSession("", lastfmPair{artist: "Seed", title: "One"}, nil, time.Now())
+	s.servedIDs["a"] = lastfmPair{artist: "Nero", title: "Promises"}
+	s.servedIDs["b"] = lastfmPair{artist: "Nero", title: "Guilt"}
+	s.queue = []lastfmPair{{artist: "Nero", title: "Doomsday"}, {artist: "Seed", title: "One"}, {artist: "Burial", title: "Archangel"}}
+
+	s.skip("a")
+	s.skip("a") // the same song twice counts once
+	s.skip("unknown")
+	if s.blocked("Nero", "Doomsday") {
+		t.Fatal("one skip must not block an artist")
+	}
+	s.skip("b")
+	got := s.take(5)
+	if len(got) != 1 || got[0].artist != "Burial" {
+		t.Fatalf("take %+v", got)
+	}
+}
+
+func TestRadioSessionBoostsStoredLikes(t *testing.T) {
+	client, search := fakeLastfm(12)
+	h := &RecommendHandler{client: client, apiKey: "k", search: search}
+	profile := services.NewFeedbackProfile()
+	profile.Note(domain.FeedbackLike, "A6", "Song 1")
+	s := newRadioSession("u1", lastfmPair{artist: "A0", title: "Song 0"}, profile, time.Now())
+	s.queue = []lastfmPair{{artist: "A0", title: "Song 2"}}
+
+	// A stored like waits until the walk reaches its artist…
+	h.boostRadioSession(context.Background(), s, false)
+	if len(s.queue) != 1 || len(s.pastLikes) != 1 {
+		t.Fatalf("boosted too early: queue %+v", s.queue)
+	}
+	// …then its neighborhood jumps the queue, once.
+	s.walked["a6"] = true
+	h.boostRadioSession(context.Background(), s, false)
+	if len(s.queue) < 2 || s.queue[len(s.queue)-1].artist != "A0" || len(s.pastLikes) != 0 {
+		t.Fatalf("queue %+v, pending %+v", s.queue, s.pastLikes)
+	}
+	for _, p := range s.queue[:len(s.queue)-1] {
+		if a := p.artist; a != "A5" && a != "A6" && a != "A7" {
+			t.Fatalf("boost outside A6's neighborhood: %+v", p)
+		}
+	}
+}
+
+func TestRadioSessionFeedbackSteers(t *testing.T) {
+	client, search := fakeLastfm(12)
+	h := &RecommendHandler{client: client, apiKey: "k", search: search}
+	app := fiber.New()
+	app.Get("/recommend", h.GetRecommend)
+	app.Post("/recommend/feedback", h.PostFeedback)
+
+	next := func(q string) (string, []domain.Song) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/recommend?mode=radio&"+q, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var songs []domain.Song
+		if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&songs) != nil {
+			t.Fatalf("%s: status %d", q, resp.StatusCode)
+		}
+		return resp.Header.Get("X-Radio-Session"), songs
+	}
+	feedback := func(body string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/recommend/feedback", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("%s: status %d", body, resp.StatusCode)
+		}
+	}
+
+	id, songs := next("session=new&artist=A0&title=Song+0")
+	var skipped string
+	for _, s := range songs {
+		if s.Artist == "A1" {
+			skipped = s.Id
+			feedback(`{"session":"` + id + `","songId":"` + s.Id + `","kind":"skip"}`)
+		}
+	}
+	if skipped == "" {
+		t.Fatalf("no A1 track in %+v", songs)
+	}
+	// A second early skip (named, not served) takes A1 off the station.
+	feedback(`{"session":"` + id + `","artist":"A1","title":"Song 2","kind":"skip"}`)
+	feedback(`{"session":"` + id + `","artist":"A9","title":"Song 1","kind":"like"}`)
+
+	_, songs = next("session=" + id)
+	if a := songs[0].Artist; a != "A8" && a != "A9" && a != "A10" {
+		t.Fatalf("liked neighborhood not first: %+v", songs[0])
+	}
+	for _, s := range songs {
+		if s.Artist == "A1" {
+			t.Fatalf("artist skipped twice came back: %+v", s)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/recommend/feedback", strings.NewReader(`{"session":"nope","kind":"like","artist":"A1","title":"Song 1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if resp, _ := app.Test(req); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown session: status %d", resp.StatusCode)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"github.com/andiq123/FindVibeFiber/internal/core/domain"
 	"github.com/andiq123/FindVibeFiber/internal/core/ports"
 	"github.com/andiq123/FindVibeFiber/internal/core/services"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/streamcache"
 	"github.com/andiq123/FindVibeFiber/internal/utils"
 	"github.com/gofiber/fiber/v3"
 	"golang.org/x/sync/singleflight"
@@ -23,17 +25,17 @@ const (
 	/** Radio wants a longer same-vibe batch so the queue doesn't pivot every extend. */
 	radioResolveCap = 12
 	/** Album play only needs a short queue to start — full resolve burns the gateway budget. */
-	albumResolveCap = 4
+	albumResolveCap     = 4
 	recommendSearchPeek = 8
 	recommendTTL        = 6 * time.Hour
 	recommendCacheCap   = 64
 	pairsTTL            = 6 * time.Hour
 	pairsCacheCap       = 64
 
-	// ponytail: region hard-coded until settings grow a country picker
-	exploreCountry  = "Romania"
-	exploreTTL      = 24 * time.Hour
-	exploreCacheAge = "86400" // Cache-Control max-age for fresh chart payloads
+	exploreDefaultCountry = "ro"        // UseExplore overrides
+	exploreWorldwide      = "worldwide" // cache key for countries without shelves of their own
+	exploreTTL            = 24 * time.Hour
+	exploreCacheAge       = "86400" // Cache-Control max-age for fresh chart payloads
 	// Smaller shelves → faster first paint when streaming section-by-section.
 	exploreCap   = 8
 	exploreFetch = 12
@@ -41,6 +43,12 @@ const (
 	exploreSectionBudget = 12 * time.Second
 	exploreCoverBudget   = 2500 * time.Millisecond
 	exploreStreamBudget  = 75 * time.Second
+	exploreSaveBudget    = 5 * time.Second
+	// RunExplore rebuilds a shelf 2–4h before it goes stale (jittered so shelves
+	// built together don't all refetch together) and retries failures after 30m.
+	exploreRefreshLead   = 2 * time.Hour
+	exploreRefreshJitter = 2 * time.Hour
+	exploreRetry         = 30 * time.Minute
 
 	resolveTTL      = 15 * time.Minute
 	resolveCacheCap = 256
@@ -53,6 +61,11 @@ type recommendEntry struct {
 	at    time.Time
 }
 
+type exploreEntry struct {
+	sec     ExploreSection
+	at, due time.Time // due: when RunExplore rebuilds it
+}
+
 type pairsEntry struct {
 	pairs []lastfmPair
 	at    time.Time
@@ -81,11 +94,30 @@ type RecommendHandler struct {
 	apiKey   string
 	search   ports.ISearchService
 	covers   *services.CoverService
-
-	exploreMu       sync.Mutex
-	exploreSections []ExploreSection
-	exploreAt       time.Time
-	exploreSF       singleflight.Group
+	// Extra /stream upstream hosts (self-hosted libraries); exact match only.
+	streamHosts  map[string]bool
+	streamAuth   map[string]func(*url.URL) // per-host upstream auth (AuthorizeStreamHost)
+	library      localLibrary
+	libraryHost  string
+	streamCache  *streamcache.Cache
+	signer       *services.StreamSigner
+	lyrics       lyricsSource
+	favorites    favoritesLister // UseVault
+	playlists    playlistSource  // UseArchives
+	zipMaxTracks int
+	quota        *services.StreamQuota
+	feedback     *services.FeedbackService
+	artistMix    artistMix // UseArtistRadio
+	coSaves      coSaveSource
+	dailyMixes   dailyMixSource
+
+	exploreCatalog *domain.ExploreCatalog // UseExplore; nil = defaultExploreCatalog
+	exploreCountry string
+	exploreRepo    ports.IExploreRepository      // UseExploreStore
+	charts         map[string]ports.IChartSource // UseChartSources; nil = charts.Defaults
+	exploreMu      sync.Mutex
+	exploreCache   map[string]exploreEntry
+	exploreSF      singleflight.Group
 
 	recommendMu    sync.Mutex
 	recommendCache map[string]recommendEntry
@@ -97,6 +129,14 @@ type RecommendHandler struct {
 
 	resolveMu    sync.Mutex
 	resolveCache map[string]resolveEntry
+
+	radioMu       sync.Mutex
+	radioSessions map[string]*radioSession
+
+	tagsMu        sync.Mutex
+	popularTags   []PopularTag
+	popularTagsAt time.Time
+	tagsSF        singleflight.Group
 }
 
 func NewRecommendHandler(client *http.Client, apiKey string, search ports.ISearchService, covers *services.CoverService) *RecommendHandler {
@@ -193,7 +233,7 @@ func (h *RecommendHandler) GetAlbumTracks(c fiber.Ctx) error {
 		if len(songs) == 0 {
 			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "No tracks for this album"})
 		}
-		return c.JSON(songs)
+		return c.JSON(h.signer.SignSongs(songs))
 	}
 	// Short queue is enough to start playback; client CoverResolver fills art.
 	songs := h.resolveN(ctx, pairs, lastfmPair{}, albumResolveCap, false, true)
@@ -203,7 +243,7 @@ func (h *RecommendHandler) GetAlbumTracks(c fiber.Ctx) error {
 	if len(songs) == 0 {
 		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Couldn't resolve album tracks"})
 	}
-	return c.JSON(songs)
+	return c.JSON(h.signer.SignSongs(songs))
 }
 
 // GET /resolve?artist=&title=&refresh=1 → one playable Song whose artist+title match the request.
@@ -224,23 +264,29 @@ func (h *RecommendHandler) GetResolve(c fiber.Ctx) error {
 	// Spotify import + play-from-resolve — fill art before the client saves to vault.
 	songs := []domain.Song{song}
 	h.covers.FillSongs(c.Context(), songs)
-	return c.JSON(songs[0])
+	return c.JSON(h.signer.SignSongs(songs)[0])
 }
 
 // GET /recommend?artist=&title=&mode=radio&offset=N
 // Cached 6h per normalized seed (+ mode/offset). Explore "because" stays diverse;
 // mode=radio keeps same-artist + similar so the station doesn't pivot genres.
+// mode=radio&session= continues a server-side session instead of rotating offsets.
 func (h *RecommendHandler) GetRecommend(c fiber.Ctx) error {
 	artist := strings.TrimSpace(c.Query("artist"))
 	title := strings.TrimSpace(c.Query("title"))
-	if artist == "" || title == "" {
+	radio := strings.EqualFold(c.Query("mode"), "radio")
+	session := radio && c.Query("session") != ""
+	if (artist == "" || title == "") && !session {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "artist and title are required"})
 	}
 	if h.apiKey == "" {
 		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "LASTFM_API_KEY not set"})
 	}
+	refresh := c.Query("refresh") == "1" || strings.EqualFold(c.Query("refresh"), "true")
+	if session {
+		return h.getRadioSession(c, radioStart{seed: lastfmPair{artist: artist, title: title}}, refresh)
+	}
 
-	radio := strings.EqualFold(c.Query("mode"), "radio")
 	offset, _ := strconv.Atoi(c.Query("offset"))
 	if offset < 0 {
 		offset = 0
@@ -250,7 +296,6 @@ func (h *RecommendHandler) GetRecommend(c fiber.Ctx) error {
 		capN = radioResolveCap
 	}
 
-	refresh := c.Query("refresh") == "1" || strings.EqualFold(c.Query("refresh"), "true")
 	key := songKey(artist, title)
 	if radio {
 		key = key + "|radio|" + strconv.Itoa(offset)
@@ -258,7 +303,7 @@ func (h *RecommendHandler) GetRecommend(c fiber.Ctx) error {
 	if !refresh {
 		if songs, ok := h.recommendSnap(key); ok {
 			c.Set("Cache-Control", "private, max-age=21600")
-			return c.JSON(songs)
+			return c.JSON(h.signer.SignSongs(h.steerRadio(c, radio, songs)))
 		}
 	}
 
@@ -280,9 +325,14 @@ func (h *RecommendHandler) GetRecommend(c fiber.Ctx) error {
 		seed := lastfmPair{artist: artist, title: title}
 		artists := artistCandidates(artist)
 		pairs, err := h.pairsFor(ctx, seed, artists, radio, refresh)
-		if err != nil {
+		// What listeners who saved the seed also saved goes alongside Last.fm's picks.
+		coSaved := h.coSavePairs(ctx, []string{songKey(artist, title)}, nil)
+		if err != nil && len(coSaved) == 0 {
 			return nil, err
 		}
+		if len(coSaved) > 0 {
+			pairs = uniquePairs(interleavePairs(coSaved, pairs), seed)
+		}
 		if radio && offset > 0 && len(pairs) > 0 {
 			off := offset % len(pairs)
 			pairs = append(pairs[off:], pairs[:off]...)
@@ -306,7 +356,7 @@ func (h *RecommendHandler) GetRecommend(c fiber.Ctx) error {
 	if err != nil {
 		if stale, ok := h.recommendStale(key); ok {
 			c.Set("Cache-Control", "private, max-age=60")
-			return c.JSON(stale)
+			return c.JSON(h.signer.SignSongs(h.steerRadio(c, radio, stale)))
 		}
 		if err == errRecommendEmpty {
 			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Couldn't build a radio for this track"})
@@ -315,7 +365,7 @@ func (h *RecommendHandler) GetRecommend(c fiber.Ctx) error {
 	}
 	songs, _ := v.([]domain.Song)
 	c.Set("Cache-Control", "private, max-age=21600")
-	return c.JSON(songs)
+	return c.JSON(h.signer.SignSongs(h.steerRadio(c, radio, songs)))
 }
 
 var errRecommendEmpty = fmt.Errorf("recommend empty")
@@ -399,7 +449,10 @@ func (h *RecommendHandler) pairsStore(key string, pairs []lastfmPair) {
 	h.pairsCache[key] = pairsEntry{pairs: cp, at: time.Now()}
 }
 
-type lastfmPair struct{ artist, title string }
+type lastfmPair struct {
+	artist, title string
+	duration      int // seconds from the Last.fm row; 0 = unknown
+}
 
 func (h *RecommendHandler) collectPairs(ctx context.Context, seed lastfmPair, artists []string, radio bool) ([]lastfmPair, error) {
 	pairCap := recommendResolveCap * 2
@@ -73,45 +73,13 @@ func TestCoreTitleStripsRemixVariants(t *testing.T) {
 	}
 }
 
-func TestParseChartTitle(t *testing.T) {
-	cases := []struct {
-		raw, artist, title string
-	}{
-		{"VANILLA x ALEX VELEA - 7 din 7 (VIDEOCLIP OFICIAL)", "VANILLA", "7 din 7"},
-		{"Denis Ramniceanu x BABASHA - Vara nu e vara | Official video", "Denis Ramniceanu", "Vara nu e vara"},
-		{"IAN x AZTECA - FOCU'", "IAN", "FOCU'"},
-		{"MIRA - Caramela | Official Video", "MIRA", "Caramela"},
-	}
-	for _, tc := range cases {
-		a, title, ok := parseChartTitle(tc.raw)
-		if !ok || a != tc.artist || title != tc.title {
-			t.Fatalf("%q → (%q, %q, %v) want (%q, %q)", tc.raw, a, title, ok, tc.artist, tc.title)
-		}
-	}
-}
-
-func TestParseKworbMusicPairsUsesMusicTable(t *testing.T) {
-	html := `
-<div class="overall"><table><tbody>
-<tr><td class="text"><div><a href="#">Gaming video not music</a></div></td></tr>
-</tbody></table></div>
-<div class="music" style="display: none;"><table id="trendingcountry"><tbody>
-<tr><td class="text"><div><a href="#">VANILLA x ALEX VELEA - 7 din 7 | Official</a></div></td></tr>
-<tr><td class="text"><div><a href="#">MIRA - Caramela | Official Video</a></div></td></tr>
-</tbody></table></div>`
-	got := parseKworbMusicPairs(html)
-	if len(got) != 2 || got[0].artist != "VANILLA" || got[1].title != "Caramela" {
-		t.Fatalf("got %+v", got)
-	}
-}
-
 func TestUniquePairsDropsRemixDupes(t *testing.T) {
-	seed := lastfmPair{"Vicetone", "Collide"}
+	seed := lastfmPair{artist: "Vicetone", title: "Collide"}
 	got := uniquePairs([]lastfmPair{
-		{"Vicetone", "Collide (Original Mix)"},
-		{"Vicetone", "Collide (Extended Mix)"},
-		{"Nero", "Promises"},
-		{"Nero", "Promises (Original Mix)"},
+		{"Vicetone", "Collide (Original Mix)", 0},
+		{"Vicetone", "Collide (Extended Mix)", 0},
+		{"Nero", "Promises", 0},
+		{"Nero", "Promises (Original Mix)", 0},
 	}, seed)
 	if len(got) != 1 || got[0].artist != "Nero" {
 		t.Fatalf("got %+v", got)
@@ -135,7 +103,7 @@ func TestSearchFallbackByArtist(t *testing.T) {
 			},
 		}},
 	}
-	seed := lastfmPair{"Satoshi, magnat, feoctist", "Pațanii"}
+	seed := lastfmPair{artist: "Satoshi, magnat, feoctist", title: "Pațanii"}
 	got := h.searchFallback(context.Background(), []string{"Satoshi"}, seed)
 	if len(got) != 2 || got[0].Link != "https://a.mp3" {
 		t.Fatalf("got %+v", got)
@@ -157,11 +125,11 @@ func TestResolveSkipsSeedAndRemixDupes(t *testing.T) {
 			},
 		}},
 	}
-	seed := lastfmPair{"Vicetone", "Collide"}
+	seed := lastfmPair{artist: "Vicetone", title: "Collide"}
 	got := h.resolve(context.Background(), []lastfmPair{
-		{"Vicetone", "Collide"},
-		{"Nero", "Promises"},
-		{"Flux Pavilion", "Bass Cannon"},
+		{"Vicetone", "Collide", 0},
+		{"Nero", "Promises", 0},
+		{"Flux Pavilion", "Bass Cannon", 0},
 	}, seed)
 	if len(got) != 2 {
 		t.Fatalf("want 2 unique other tracks, got %+v", got)
@@ -190,34 +158,41 @@ func TestPickResolvedAlbumKeepsSameArtist(t *testing.T) {
 
 func TestExploreCacheRoundTrip(t *testing.T) {
 	h := &RecommendHandler{}
-	if _, ok := h.exploreSnap(true); ok {
+	ro := h.exploreRegionFor("")
+	if _, ok := h.exploreSnap(ro, true); ok {
 		t.Fatal("empty cache should miss")
 	}
-	h.exploreStore([]ExploreSection{{
+	h.exploreStore(ro.shelves[0], ExploreSection{
 		ID: "romania", Title: "Romania",
 		Songs: []domain.Song{{Title: "A", Artist: "B", Link: "https://x.mp3"}},
-	}})
-	got, ok := h.exploreSnap(true)
+	})
+	got, ok := h.exploreSnap(ro, true)
 	if !ok || len(got) != 1 || got[0].ID != "romania" || len(got[0].Songs) != 1 {
 		t.Fatalf("got %+v ok=%v", got, ok)
 	}
-	status := h.exploreCacheStatus()
-	if status["cached"] != true || status["fresh"] != true || status["sections"] != 1 || status["songs"] != 1 {
+	if _, ok := h.exploreSnap(h.exploreRegionFor("zz"), false); ok {
+		t.Fatal("worldwide must not see Romania's own shelves")
+	}
+	status := h.exploreCacheStatus(ro)
+	if status["country"] != "Romania" || status["cached"] != true || status["fresh"] != true || status["sections"] != 1 || status["songs"] != 1 {
 		t.Fatalf("fresh status %+v", status)
 	}
 	if rem, _ := status["remainingSeconds"].(int64); rem <= 0 || rem > int64(exploreTTL/time.Second) {
 		t.Fatalf("remainingSeconds %+v", status["remainingSeconds"])
 	}
 	// Stale-beyond-TTL still serves last good payload.
-	h.exploreAt = h.exploreAt.Add(-exploreTTL - time.Minute)
-	if _, ok := h.exploreSnap(true); ok {
+	key := ro.shelves[0].key
+	e := h.exploreCache[key]
+	e.at = e.at.Add(-exploreTTL - time.Minute)
+	h.exploreCache[key] = e
+	if _, ok := h.exploreSnap(ro, true); ok {
 		t.Fatal("fresh snap should miss after TTL")
 	}
-	stale, ok := h.exploreSnap(false)
+	stale, ok := h.exploreSnap(ro, false)
 	if !ok || len(stale) != 1 {
 		t.Fatalf("stale snap got %+v ok=%v", stale, ok)
 	}
-	staleStatus := h.exploreCacheStatus()
+	staleStatus := h.exploreCacheStatus(ro)
 	if staleStatus["cached"] != true || staleStatus["fresh"] != false || staleStatus["remainingSeconds"] != int64(0) {
 		t.Fatalf("stale status %+v", staleStatus)
 	}
@@ -231,7 +206,7 @@ func TestResolveStrictRejectsWeakHits(t *testing.T) {
 			},
 		}},
 	}
-	want := lastfmPair{"Nero", "Collide"}
+	want := lastfmPair{artist: "Nero", title: "Collide"}
 	if _, ok := h.resolveOne(context.Background(), want, lastfmPair{}, false); ok {
 		t.Fatal("must reject artist-only / wrong-title hit")
 	}
@@ -245,7 +220,7 @@ func TestResolveAcceptsTitleArtistOverlap(t *testing.T) {
 			},
 		}},
 	}
-	want := lastfmPair{"Nero", "Promises"}
+	want := lastfmPair{artist: "Nero", title: "Promises"}
 	got, ok := h.resolveOne(context.Background(), want, lastfmPair{}, false)
 	if !ok || got.Link != "https://ok.mp3" {
 		t.Fatalf("expected overlap match, got %+v ok=%v", got, ok)
@@ -262,7 +237,7 @@ func TestResolvePicksBestAmongPeek(t *testing.T) {
 			},
 		}},
 	}
-	want := lastfmPair{"magnat, feoctist", "Auzeam, Gheorghe"}
+	want := lastfmPair{artist: "magnat, feoctist", title: "Auzeam, Gheorghe"}
 	got, ok := h.resolveOne(context.Background(), want, lastfmPair{}, false)
 	if !ok || got.Link != "https://right.mp3" {
 		t.Fatalf("want clean title hit, got %+v ok=%v", got, ok)
@@ -285,7 +260,7 @@ func TestResolveIgnoresStaleWrongCache(t *testing.T) {
 			{Title: "Promises", Artist: "Nero", Link: "https://ok.mp3"},
 		},
 	}}, nil)
-	want := lastfmPair{"Nero", "Promises"}
+	want := lastfmPair{artist: "Nero", title: "Promises"}
 	key := songKey(want.artist, want.title)
 	h.resolveStore(key, domain.Song{Title: "Miagy", Artist: "Wrong", Link: "https://stale.mp3"})
 	got, ok := h.resolveOne(context.Background(), want, lastfmPair{}, false)
@@ -300,7 +275,7 @@ func TestResolveRefreshBypassesCache(t *testing.T) {
 			{Title: "Promises", Artist: "Nero", Link: "https://fresh.mp3"},
 		},
 	}}, nil)
-	want := lastfmPair{"Nero", "Promises"}
+	want := lastfmPair{artist: "Nero", title: "Promises"}
 	key := songKey(want.artist, want.title)
 	h.resolveStore(key, domain.Song{Title: "Promises", Artist: "Nero", Link: "https://cached.mp3"})
 	cached, ok := h.resolveOne(context.Background(), want, lastfmPair{}, false)
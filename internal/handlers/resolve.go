@@ -142,7 +142,7 @@ func (h *RecommendHandler) resolveOne(ctx context.Context, want, seed lastfmPair
 	}
 
 	seedKey := songKey(seed.artist, seed.title)
-	song, ok := services.PickPlayableSong(want.artist, want.title, songs, seedKey, recommendSearchPeek)
+	song, ok := services.PickPlayableSongNear(want.artist, want.title, want.duration, songs, seedKey, recommendSearchPeek)
 	if !ok {
 		// Never return an unrelated playable hit under this want key — that stamped the wrong
 		// stream URL onto radio/explore/vault rows (UI title ≠ audio).
@@ -16,12 +16,16 @@ import (
 	"github.com/gofiber/fiber/v3"
 )
 
-// Match explore: don't let cold iTunes dominate search p95.
-const searchCoverBudget = 2500 * time.Millisecond
+const (
+	// Match explore: don't let cold iTunes dominate search p95.
+	searchCoverBudget  = 2500 * time.Millisecond
+	searchStreamBudget = time.Minute
+)
 
 type SearchHandler struct {
 	searchService ports.ISearchService
 	covers        *services.CoverService
+	signer        *services.StreamSigner
 }
 
 func NewSearchHandler(
@@ -85,6 +89,11 @@ func (sh *SearchHandler) Search(c fiber.Ctx) error {
 	cancel()
 
 	c.Set("Cache-Control", "private, max-age=120")
+	if sh.signer != nil {
+		signed := *response
+		signed.Songs = sh.signer.SignSongs(response.Songs)
+		return c.JSON(signed)
+	}
 	return c.JSON(response)
 }
 
@@ -95,6 +104,9 @@ func (sh *SearchHandler) streamSearch(c fiber.Ctx, query string, page int) error
 	c.Set("X-Accel-Buffering", "no")
 
 	return c.SendStreamWriter(func(w *bufio.Writer) {
+		// c is recycled once the writer runs; a gone client stops us through write.
+		ctx, cancel := context.WithTimeout(context.Background(), searchStreamBudget)
+		defer cancel()
 		enc := json.NewEncoder(w)
 		write := func(ev searchStreamEvent) bool {
 			if err := enc.Encode(ev); err != nil {
@@ -122,6 +134,9 @@ func (sh *SearchHandler) streamSearch(c fiber.Ctx, query string, page int) error
 			if !services.HasRealCover(s.Image) {
 				s.Image = ""
 			}
+			if sh.signer != nil {
+				s = sh.signer.SignSong(s)
+			}
 			streamed++
 			if !write(searchStreamEvent{Type: "song", Song: &s}) {
 				return context.Canceled
@@ -129,7 +144,7 @@ func (sh *SearchHandler) streamSearch(c fiber.Ctx, query string, page int) error
 			return nil
 		}
 
-		resp, err := sh.searchService.SearchWithProgress(c.Context(), query, page, onMeta, onSong)
+		resp, err := sh.searchService.SearchWithProgress(ctx, query, page, onMeta, onSong)
 		if err != nil && streamed == 0 {
 			_ = write(searchStreamEvent{Type: "error", Error: "Couldn't search"})
 			return
@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
 	"github.com/gofiber/fiber/v3"
 )
 
@@ -63,6 +64,20 @@ func (h *SpotifyHandler) GetPlaylist(c fiber.Ctx) error {
 	return c.JSON(SpotifyPlaylistResponse{Name: name, Tracks: tracks})
 }
 
+// Playlist is GetPlaylist without the HTTP layer (ZIP downloads). Bad links are
+// domain.ErrInvalidInput, private/missing playlists domain.ErrNotFound.
+func (h *SpotifyHandler) Playlist(ctx context.Context, rawURL string) (string, []SpotifyTrackMeta, error) {
+	id := ParseSpotifyPlaylistID(rawURL)
+	if id == "" {
+		return "", nil, domain.ErrInvalidInput
+	}
+	name, tracks, status, err := h.fetchEmbedPlaylist(ctx, id)
+	if status == http.StatusNotFound || status == http.StatusForbidden {
+		return "", nil, fmt.Errorf("spotify playlist %s: %w", id, domain.ErrNotFound)
+	}
+	return name, tracks, err
+}
+
 // ParseSpotifyPlaylistID extracts a 22-char playlist id from URL, URI, or bare id.
 func ParseSpotifyPlaylistID(raw string) string {
 	raw = strings.TrimSpace(raw)
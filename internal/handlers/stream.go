@@ -1,27 +1,50 @@
 package handlers
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/services"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/providers"
 	"github.com/gofiber/fiber/v3"
 )
 
 // GET /stream?artist=&title= → proxy CDN bytes (fallback when the phone can't hotlink).
-// Cache-first resolve; re-resolve once if the cached link is dead.
+// Cache-first resolve; re-resolve once if the cached link is dead, and resume with a
+// Range request if the upstream drops mid-body.
 // Not the default play path — clients must try the direct song.link first.
+// GET /stream?local=<id> → library file (Range aware); that is the song.link for local hits.
+// With STREAM_SIGNING_KEY set both forms need the exp/kid/sig params from a response's streamUrl.
+// With STREAM_CACHE_DIR set, proxied bytes are served from / filled into the disk cache.
 func (h *RecommendHandler) GetStream(c fiber.Ctx) error {
+	if id := strings.TrimSpace(c.Query(providers.LocalStreamParam)); id != "" {
+		msg := h.streamSignatureError(c, services.LocalSubject(id))
+		if msg != "" {
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": msg})
+		}
+		if retry, ok := h.allowStream(c); !ok {
+			return quotaExceeded(c, retry)
+		}
+		return h.sendLocal(c, id)
+	}
 	artist := strings.TrimSpace(c.Query("artist"))
 	title := strings.TrimSpace(c.Query("title"))
 	if artist == "" || title == "" {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "artist and title required"})
 	}
+	msg := h.streamSignatureError(c, services.SongSubject(artist, title))
+	if msg != "" {
+		return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": msg})
+	}
+	if retry, ok := h.allowStream(c); !ok {
+		return quotaExceeded(c, retry)
+	}
 	if h.upstream == nil {
 		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "stream proxy unavailable"})
 	}
@@ -30,33 +53,25 @@ func (h *RecommendHandler) GetStream(c fiber.Ctx) error {
 	defer cancel()
 
 	want := lastfmPair{artist: artist, title: title}
-	song, ok := h.resolveOne(ctx, want, lastfmPair{}, false)
+	song, ok := h.resolveStream(ctx, want)
 	if !ok {
-		song, ok = h.resolveOne(ctx, want, lastfmPair{}, true)
-		if !ok {
-			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "no match"})
-		}
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "no match"})
 	}
 
-	rng := strings.TrimSpace(c.Get("Range"))
-	resp, err := h.openStreamUpstream(ctx, song.Link, rng)
-	if err != nil || resp == nil || resp.StatusCode >= 400 {
-		if resp != nil {
-			resp.Body.Close()
-		}
-		fresh, freshed := h.resolveOne(ctx, want, lastfmPair{}, true)
-		if !freshed || strings.TrimSpace(fresh.Link) == "" || fresh.Link == song.Link {
-			return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": "Couldn't fetch stream"})
-		}
-		resp, err = h.openStreamUpstream(ctx, fresh.Link, rng)
-		if err != nil || resp == nil || resp.StatusCode >= 400 {
-			if resp != nil {
-				resp.Body.Close()
-			}
-			return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": "Upstream stream failed"})
+	if id, local := h.localTrackID(song.Link); local {
+		return h.sendLocal(c, id)
+	}
+	if h.streamCache != nil {
+		if handled, err := h.sendCached(ctx, c, want, song.Link); handled {
+			return err
 		}
 	}
 
+	resp, body, err := h.openProxied(ctx, want, song, strings.TrimSpace(c.Get("Range")))
+	if err != nil {
+		return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	ct := resp.Header.Get("Content-Type")
 	if ct == "" || strings.HasPrefix(ct, "text/") {
 		ct = "audio/mpeg"
@@ -64,28 +79,71 @@ func (h *RecommendHandler) GetStream(c fiber.Ctx) error {
 	c.Set("Content-Type", ct)
 	c.Set("Cache-Control", "private, no-store")
 	c.Set("Accept-Ranges", "bytes")
-	if cl := resp.Header.Get("Content-Length"); cl != "" {
-		c.Set("Content-Length", cl)
-	}
 	if cr := resp.Header.Get("Content-Range"); cr != "" {
 		c.Set("Content-Range", cr)
 	}
 	c.Status(resp.StatusCode)
 
-	return c.SendStreamWriter(func(w *bufio.Writer) {
-		defer resp.Body.Close()
-		_, _ = io.Copy(w, io.LimitReader(resp.Body, 80<<20)) // hard cap ~80MB
-		_ = w.Flush()
+	return c.SendStream(h.metered(c, body), body.Size())
+}
+
+// resolveStream is the cache-first resolve behind /stream and /download.
+func (h *RecommendHandler) resolveStream(ctx context.Context, want lastfmPair) (domain.Song, bool) {
+	if song, ok := h.resolveOne(ctx, want, lastfmPair{}, false); ok {
+		return song, true
+	}
+	return h.resolveOne(ctx, want, lastfmPair{}, true)
+}
+
+// openProxied opens song.Link (re-resolving once when it's dead). The body is read after
+// the handler returns, so it runs on its own context, released by body.Close. A drop
+// mid-body resumes from the sent offset (same link, then a re-resolved mirror); if that
+// fails the response is cut short of its Content-Length instead of ending clean.
+func (h *RecommendHandler) openProxied(ctx context.Context, want lastfmPair, song domain.Song, rng string) (*http.Response, *resumableBody, error) {
+	streamCtx, streamCancel := context.WithTimeout(context.Background(), streamMaxDuration)
+	link := song.Link
+	resp, err := h.openStreamUpstream(streamCtx, link, rng)
+	if err != nil || resp == nil || resp.StatusCode >= 400 {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		fresh, freshed := h.resolveOne(ctx, want, lastfmPair{}, true)
+		if !freshed || strings.TrimSpace(fresh.Link) == "" || fresh.Link == song.Link {
+			streamCancel()
+			return nil, nil, errors.New("Couldn't fetch stream")
+		}
+		link = fresh.Link
+		resp, err = h.openStreamUpstream(streamCtx, link, rng)
+		if err != nil || resp == nil || resp.StatusCode >= 400 {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			streamCancel()
+			return nil, nil, errors.New("Upstream stream failed")
+		}
+	}
+	body := newResumableBody(streamCtx, streamCancel, resp, link, h.openStreamUpstream, func(ctx context.Context) string {
+		if fresh, ok := h.resolveOne(ctx, want, lastfmPair{}, true); ok {
+			return strings.TrimSpace(fresh.Link)
+		}
+		return ""
 	})
+	return resp, body, nil
 }
 
 func (h *RecommendHandler) openStreamUpstream(ctx context.Context, link, rangeHeader string) (*http.Response, error) {
 	link = strings.TrimSpace(link)
 	upstreamURL, err := url.Parse(link)
-	if err != nil || !streamProxyAllowed(upstreamURL) {
+	if err != nil || !h.streamAllowed(upstreamURL) {
 		return nil, fmt.Errorf("stream host not allowed")
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	target := link
+	if authorize := h.streamAuth[strings.ToLower(upstreamURL.Host)]; authorize != nil {
+		signed := *upstreamURL
+		authorize(&signed)
+		target = signed.String()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -93,9 +151,50 @@ func (h *RecommendHandler) openStreamUpstream(ctx context.Context, link, rangeHe
 	if rangeHeader != "" {
 		req.Header.Set("Range", rangeHeader)
 	}
+	// Configured library hosts go direct — scrape proxies are for the mirrors.
+	if h.streamHosts[strings.ToLower(upstreamURL.Host)] && h.client != nil {
+		return h.client.Do(req)
+	}
 	return h.upstream.Do(req)
 }
 
+// AllowStreamHost admits one configured host (exact authority, e.g. a Navidrome
+// server) without widening the scraped-mirror suffix rules. Call before serving.
+func (h *RecommendHandler) AllowStreamHost(host string) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" {
+		return
+	}
+	if h.streamHosts == nil {
+		h.streamHosts = make(map[string]bool)
+	}
+	h.streamHosts[host] = true
+}
+
+// AuthorizeStreamHost admits host like AllowStreamHost and has authorize add
+// credentials to each upstream request, so client-facing links stay bare.
+func (h *RecommendHandler) AuthorizeStreamHost(host string, authorize func(*url.URL)) {
+	h.AllowStreamHost(host)
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" || authorize == nil {
+		return
+	}
+	if h.streamAuth == nil {
+		h.streamAuth = make(map[string]func(*url.URL))
+	}
+	h.streamAuth[host] = authorize
+}
+
+func (h *RecommendHandler) streamAllowed(u *url.URL) bool {
+	if streamProxyAllowed(u) {
+		return true
+	}
+	if u == nil || !strings.EqualFold(u.Scheme, "https") || u.User != nil {
+		return false
+	}
+	return h.streamHosts[strings.ToLower(u.Host)]
+}
+
 func streamProxyAllowed(u *url.URL) bool {
 	if u == nil || !strings.EqualFold(u.Scheme, "https") {
 		return false
@@ -119,6 +218,11 @@ func streamProxyAllowed(u *url.URL) bool {
 	return false
 }
 
+// StreamUpstreamHeaders sets the UA/Referer a mirror expects on req (link verification).
+func StreamUpstreamHeaders(req *http.Request) {
+	applyStreamUpstreamHeaders(req, req.URL)
+}
+
 func applyStreamUpstreamHeaders(req *http.Request, u *url.URL) {
 	req.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 18_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.0 Mobile/15E148 Safari/604.1")
 	req.Header.Set("Accept", "*/*")
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/services/streamcache"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// UseStreamCache keeps proxied /stream bytes on disk (optional).
+func (h *RecommendHandler) UseStreamCache(cache *streamcache.Cache) {
+	h.streamCache = cache
+}
+
+// sendCached serves link through the disk cache. handled=false means nothing was
+// written (not cacheable / upstream failed) and the caller should proxy directly.
+func (h *RecommendHandler) sendCached(ctx context.Context, c fiber.Ctx, want lastfmPair, link string) (handled bool, err error) {
+	open := func(ctx context.Context, rangeHeader string) (*http.Response, error) {
+		return h.openStreamUpstream(ctx, link, rangeHeader)
+	}
+	key := streamcache.Key(songKey(want.artist, want.title), link)
+	entry, err := h.streamCache.Acquire(ctx, key, rangeStart(c.Get("Range")), open)
+	if err != nil {
+		utils.GetLogger().Debug("stream cache miss", "error", err)
+		return false, nil
+	}
+	size := entry.Size()
+
+	ct := entry.ContentType()
+	if ct == "" || strings.HasPrefix(ct, "text/") {
+		ct = "audio/mpeg"
+	}
+	c.Set("Content-Type", ct)
+	c.Set("Cache-Control", "private, no-store")
+	c.Set("Accept-Ranges", "bytes")
+	start, length, ok := serveRange(c, size)
+	if !ok {
+		entry.Release()
+		return true, c.SendStatus(http.StatusRequestedRangeNotSatisfiable)
+	}
+	body := struct {
+		io.Reader
+		io.Closer
+	}{entry.NewReader(start, start+length-1), releaser{entry}} // fasthttp closes the body stream when done
+	return true, c.SendStream(h.metered(c, body), int(length))
+}
+
+type releaser struct{ e *streamcache.Entry }
+
+func (r releaser) Close() error {
+	r.e.Release()
+	return nil
+}
+
+// rangeStart is the first byte of a "bytes=N-…" header (0 for suffix ranges / none) —
+// where a cold fill should begin so a seek isn't stuck behind the file's head.
+func rangeStart(header string) int64 {
+	v, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok {
+		return 0
+	}
+	first, _, _ := strings.Cut(v, "-")
+	n, err := strconv.ParseInt(strings.TrimSpace(first), 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
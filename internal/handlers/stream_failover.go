@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/services/streamcache"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+)
+
+const (
+	streamMaxBytes    = 80 << 20 // hard cap per proxied response
+	streamMaxDuration = 30 * time.Minute
+	streamMaxResumes  = 3
+)
+
+var errStreamResume = errors.New("stream: upstream ended early and could not be resumed")
+
+// streamOpener fetches link with an optional Range header (openStreamUpstream).
+type streamOpener func(ctx context.Context, link, rangeHeader string) (*http.Response, error)
+
+// resumableBody relays one upstream body. When it ends short of the announced length it
+// re-requests the missing bytes — same link first, then a freshly resolved mirror — and
+// only continues if the new source answers 206 at the exact offset with the same total
+// size. When that fails Read returns an error instead of io.EOF, so the server drops the
+// connection mid-body (short Content-Length / no final chunk) and the client can tell.
+type resumableBody struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	open   streamOpener
+	mirror func(ctx context.Context) string // re-resolved link, "" = none
+
+	body    io.ReadCloser
+	link    string
+	start   int64 // file offset of the first byte served
+	want    int64 // bytes the client was promised (-1 = unknown)
+	total   int64 // full file size (-1 = unknown)
+	sent    int64
+	resumes int
+}
+
+// newResumableBody takes ownership of resp and of ctx's cancel func (called on Close).
+func newResumableBody(ctx context.Context, cancel context.CancelFunc, resp *http.Response, link string, open streamOpener, mirror func(context.Context) string) *resumableBody {
+	b := &resumableBody{ctx: ctx, cancel: cancel, open: open, mirror: mirror, body: resp.Body, link: link, want: -1, total: -1}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if first, last, total, ok := streamcache.ParseContentRange(resp.Header.Get("Content-Range")); ok {
+			b.start, b.want, b.total = first, last-first+1, total
+		}
+	case http.StatusOK:
+		if resp.ContentLength >= 0 {
+			b.want, b.total = resp.ContentLength, resp.ContentLength
+		}
+	}
+	return b
+}
+
+// Size is the response length to announce, or -1 for chunked.
+func (b *resumableBody) Size() int {
+	if b.want < 0 || b.want > streamMaxBytes {
+		return -1
+	}
+	return int(b.want)
+}
+
+func (b *resumableBody) Read(p []byte) (int, error) {
+	for {
+		if b.want >= 0 && b.sent >= b.want {
+			return 0, io.EOF
+		}
+		if b.sent >= streamMaxBytes {
+			return 0, fmt.Errorf("stream: over %d bytes", streamMaxBytes)
+		}
+		limit := int64(streamMaxBytes) - b.sent
+		if b.want >= 0 {
+			limit = min(limit, b.want-b.sent)
+		}
+		if int64(len(p)) > limit {
+			p = p[:limit]
+		}
+		n, err := b.body.Read(p)
+		b.sent += int64(n)
+		switch {
+		case err == nil || n > 0:
+			return n, nil // a trailing error resurfaces on the next Read
+		case errors.Is(err, io.EOF) && b.want < 0:
+			return 0, io.EOF
+		}
+		if rerr := b.resume(err); rerr != nil {
+			return 0, rerr
+		}
+	}
+}
+
+func (b *resumableBody) resume(cause error) error {
+	_ = b.body.Close()
+	b.body = http.NoBody
+	if b.want < 0 || b.total < 0 {
+		return fmt.Errorf("%w at byte %d: %v", errStreamResume, b.sent, cause)
+	}
+	from, to := b.start+b.sent, b.start+b.want-1
+	mirror, mirrored := "", false
+	for b.resumes < streamMaxResumes {
+		b.resumes++
+		link := b.link
+		if b.resumes > 1 { // the same link already failed once; try a new mirror
+			if !mirrored {
+				mirror, mirrored = b.resolveMirror(), true
+			}
+			if mirror != "" {
+				link = mirror
+			}
+		}
+		resp, err := b.open(b.ctx, link, fmt.Sprintf("bytes=%d-%d", from, to))
+		if err != nil {
+			continue
+		}
+		first, _, total, ok := streamcache.ParseContentRange(resp.Header.Get("Content-Range"))
+		if resp.StatusCode != http.StatusPartialContent || !ok || first != from || total != b.total {
+			resp.Body.Close()
+			continue
+		}
+		utils.GetLogger().Debug("stream resumed", "offset", from, "total", b.total, "attempt", b.resumes)
+		b.body, b.link = resp.Body, link
+		return nil
+	}
+	return fmt.Errorf("%w at byte %d: %v", errStreamResume, b.sent, cause)
+}
+
+func (b *resumableBody) resolveMirror() string {
+	if b.mirror == nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(b.ctx, 20*time.Second)
+	defer cancel()
+	return b.mirror(ctx)
+}
+
+func (b *resumableBody) Close() error {
+	err := b.body.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// dropHalf sends a full Content-Length but only half the bytes, then kills the connection.
+func dropHalf(t *testing.T, w http.ResponseWriter, audio []byte) {
+	w.Header().Set("Content-Length", strconv.Itoa(len(audio)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(audio[:len(audio)/2])
+	w.(http.Flusher).Flush()
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	conn.Close()
+}
+
+func TestResumableBodyFailover(t *testing.T) {
+	audio := bytes.Repeat([]byte("0123456789abcdef"), 8192)
+	other := audio[:len(audio)-1] // a different encode of the "same" song
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Range") == "" && r.URL.Path != "/ok.mp3":
+			dropHalf(t, w, audio)
+		case r.URL.Path == "/flaky.mp3" || r.URL.Path == "/ok.mp3":
+			http.ServeContent(w, r, "a.mp3", time.Time{}, bytes.NewReader(audio))
+		case r.URL.Path == "/dead.mp3":
+			http.Error(w, "gone", http.StatusNotFound)
+		default:
+			http.ServeContent(w, r, "b.mp3", time.Time{}, bytes.NewReader(other))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	open := func(ctx context.Context, link, rng string) (*http.Response, error) {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+		if rng != "" {
+			req.Header.Set("Range", rng)
+		}
+		return srv.Client().Do(req)
+	}
+	read := func(link, mirror string) ([]byte, error) {
+		resp, err := open(context.Background(), srv.URL+link, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		b := newResumableBody(ctx, cancel, resp, srv.URL+link, open, func(context.Context) string {
+			if mirror == "" {
+				return ""
+			}
+			return srv.URL + mirror
+		})
+		defer b.Close()
+		if b.Size() != len(audio) {
+			t.Fatalf("size %d", b.Size())
+		}
+		return io.ReadAll(b)
+	}
+
+	if got, err := read("/flaky.mp3", ""); err != nil || !bytes.Equal(got, audio) {
+		t.Fatalf("same link resume: %d bytes, %v", len(got), err)
+	}
+	if got, err := read("/dead.mp3", "/ok.mp3"); err != nil || !bytes.Equal(got, audio) {
+		t.Fatalf("mirror resume: %d bytes, %v", len(got), err)
+	}
+	if got, err := read("/dead.mp3", "/other.mp3"); !errors.Is(err, errStreamResume) || len(got) >= len(audio) {
+		t.Fatalf("size mismatch must not resume: %d bytes, %v", len(got), err)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/services/providers"
+	"github.com/gofiber/fiber/v3"
+)
+
+// localLibrary maps a library track id to a file inside the library root.
+type localLibrary interface {
+	FilePath(ctx context.Context, id string) (string, error)
+}
+
+// UseLibrary enables /stream?local=<id>; host is the public authority local links carry.
+func (h *RecommendHandler) UseLibrary(lib localLibrary, host string) {
+	h.library = lib
+	h.libraryHost = strings.ToLower(strings.TrimSpace(host))
+}
+
+// localTrackID recognises links minted by LocalLibraryProvider.
+func (h *RecommendHandler) localTrackID(link string) (string, bool) {
+	if h.library == nil || h.libraryHost == "" {
+		return "", false
+	}
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || !strings.EqualFold(u.Host, h.libraryHost) || !strings.HasSuffix(u.Path, "/stream") {
+		return "", false
+	}
+	id := u.Query().Get(providers.LocalStreamParam)
+	return id, id != ""
+}
+
+// sendLocal serves one library file with single-range support. Paths come only from
+// the index and are re-checked against the root, so the query can't name a file.
+func (h *RecommendHandler) sendLocal(c fiber.Ctx, id string) error {
+	if h.library == nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "library not configured"})
+	}
+	path, err := h.library.FilePath(c.Context(), strings.TrimSpace(id))
+	if errors.Is(err, domain.ErrNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "track not found"})
+	}
+	if err != nil {
+		return HandleError(c, err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "track not found"})
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return HandleError(c, err)
+	}
+	size := st.Size()
+
+	c.Set("Content-Type", localContentType(path))
+	c.Set("Cache-Control", "private, max-age=3600")
+	c.Set("Accept-Ranges", "bytes")
+
+	start, length, ok := serveRange(c, size)
+	if !ok {
+		f.Close()
+		return c.SendStatus(http.StatusRequestedRangeNotSatisfiable)
+	}
+	body := struct {
+		io.Reader
+		io.Closer
+	}{io.NewSectionReader(f, start, length), f} // fasthttp closes the body stream when done
+	return c.SendStream(h.metered(c, body), int(length))
+}
+
+// serveRange applies the request's Range header to a body of size bytes: sets
+// Content-Range/206 for a satisfiable single range, Content-Range "*/size" when not (ok=false).
+func serveRange(c fiber.Ctx, size int64) (start, length int64, ok bool) {
+	if strings.TrimSpace(c.Get("Range")) == "" {
+		return 0, size, true
+	}
+	rng, err := c.Range(size)
+	if err != nil || len(rng.Ranges) == 0 {
+		c.Set("Content-Range", "bytes */"+strconv.FormatInt(size, 10))
+		return 0, 0, false
+	}
+	// Players only ask for one range; extra ones get the first.
+	r := rng.Ranges[0]
+	c.Set("Content-Range", "bytes "+strconv.FormatInt(r.Start, 10)+"-"+strconv.FormatInt(r.End, 10)+"/"+strconv.FormatInt(size, 10))
+	c.Status(http.StatusPartialContent)
+	return r.Start, r.End - r.Start + 1, true
+}
+
+func localContentType(path string) string {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".mp3":
+		return "audio/mpeg"
+	case ".flac":
+		return "audio/flac"
+	case ".m4a", ".mp4":
+		return "audio/mp4"
+	default:
+		if ct := mime.TypeByExtension(ext); ct != "" {
+			return ct
+		}
+		return "application/octet-stream"
+	}
+}
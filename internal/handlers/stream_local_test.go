@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/core/services"
+	"github.com/gofiber/fiber/v3"
+)
+
+type fakeLibrary map[string]string
+
+func (f fakeLibrary) FilePath(_ context.Context, id string) (string, error) {
+	if p, ok := f[id]; ok {
+		return p, nil
+	}
+	return "", domain.ErrNotFound
+}
+
+func TestGetStreamLocalRange(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "a.mp3")
+	if err := os.WriteFile(p, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h := &RecommendHandler{}
+	h.UseLibrary(fakeLibrary{"t1": p}, "vibe.example.net")
+	app := fiber.New()
+	app.Get("/stream", h.GetStream)
+
+	cases := []struct {
+		rng, body, contentRange string
+		status                  int
+	}{
+		{"", "0123456789", "", http.StatusOK},
+		{"bytes=2-5", "2345", "bytes 2-5/10", http.StatusPartialContent},
+		{"bytes=7-", "789", "bytes 7-9/10", http.StatusPartialContent},
+		{"bytes=20-30", "", "bytes */10", http.StatusRequestedRangeNotSatisfiable},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/stream?local=t1", nil)
+		if tc.rng != "" {
+			req.Header.Set("Range", tc.rng)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.status || (tc.status < 400 && string(body) != tc.body) || resp.Header.Get("Content-Range") != tc.contentRange {
+			t.Fatalf("%q: status %d body %q range %q", tc.rng, resp.StatusCode, body, resp.Header.Get("Content-Range"))
+		}
+		if tc.status < 400 && resp.Header.Get("Content-Type") != "audio/mpeg" {
+			t.Fatalf("content-type %q", resp.Header.Get("Content-Type"))
+		}
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/stream?local=../../etc/passwd", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown id: %d", resp.StatusCode)
+	}
+}
+
+func TestLocalTrackIDOnlyOwnHost(t *testing.T) {
+	h := &RecommendHandler{}
+	h.UseLibrary(fakeLibrary{}, "vibe.example.net")
+	if id, ok := h.localTrackID("https://vibe.example.net/stream?local=abc"); !ok || id != "abc" {
+		t.Fatalf("own link: %q %v", id, ok)
+	}
+	if _, ok := h.localTrackID("https://other.example.net/stream?local=abc"); ok {
+		t.Fatal("foreign host treated as local")
+	}
+}
+
+func TestGetStreamRequiresSignature(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "a.mp3")
+	if err := os.WriteFile(p, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := services.NewStreamSigner("secret", nil, time.Hour, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &RecommendHandler{}
+	h.UseLibrary(fakeLibrary{"t1": p}, "vibe.example.net")
+	h.UseStreamSigner(signer)
+	app := fiber.New()
+	app.Get("/stream", h.GetStream)
+
+	for target, want := range map[string]int{
+		"/stream?local=t1":    http.StatusForbidden,
+		signer.LocalURL("t1"): http.StatusOK,
+		strings.Replace(signer.LocalURL("t1"), "local=t1", "local=t2", 1): http.StatusForbidden, // sig for another id
+		"/stream?artist=Nero&title=Promises":                              http.StatusForbidden,
+	} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("%s: status %d want %d", target, resp.StatusCode, want)
+		}
+	}
+}
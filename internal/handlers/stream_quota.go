@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/services"
+	"github.com/gofiber/fiber/v3"
+)
+
+// Locals key carrying who the current response's bytes are charged to.
+const quotaSubjectLocal = "streamQuotaSubject"
+
+// UseStreamQuota meters /stream, /download and ZIP bytes per client IP and day. There
+// is no verified user identity (?userId= and /:userId are caller-supplied), so the IP
+// is the only subject that can't be spoofed into someone else's quota.
+func (h *RecommendHandler) UseStreamQuota(q *services.StreamQuota) {
+	h.quota = q
+}
+
+// allowStream remembers the caller's IP subject for metered and checks today's quota.
+// A response already streaming finishes even if it crosses the limit.
+func (h *RecommendHandler) allowStream(c fiber.Ctx) (time.Duration, bool) {
+	if h.quota == nil {
+		return 0, true
+	}
+	subject := services.IPSubject(c.IP())
+	c.Locals(quotaSubjectLocal, subject)
+	return h.quota.Allow(c.Context(), subject)
+}
+
+func quotaExceeded(c fiber.Ctx, retry time.Duration) error {
+	c.Set("Retry-After", strconv.Itoa(max(int(math.Ceil(retry.Seconds())), 1)))
+	return c.Status(http.StatusTooManyRequests).JSON(fiber.Map{"error": "daily stream quota used up"})
+}
+
+// metered charges every byte read from body to the request's subject (Close is kept).
+func (h *RecommendHandler) metered(c fiber.Ctx, body io.Reader) io.Reader {
+	subject, _ := c.Locals(quotaSubjectLocal).(string)
+	if h.quota == nil || subject == "" {
+		return body
+	}
+	r := meterReader{r: body, q: h.quota, subject: subject}
+	if cl, ok := body.(io.Closer); ok {
+		return struct {
+			io.Reader
+			io.Closer
+		}{r, cl}
+	}
+	return r
+}
+
+type meterReader struct {
+	r       io.Reader
+	q       *services.StreamQuota
+	subject string
+}
+
+func (m meterReader) Read(p []byte) (int, error) {
+	n, err := m.r.Read(p)
+	m.q.Add(m.subject, int64(n))
+	return n, err
+}
+
+type meterWriter struct {
+	w       io.Writer
+	q       *services.StreamQuota
+	subject string
+}
+
+func (m meterWriter) Write(p []byte) (int, error) {
+	n, err := m.w.Write(p)
+	m.q.Add(m.subject, int64(n))
+	return n, err
+}
+
+// meteredWriter is metered for responses written through SendStreamWriter.
+func (h *RecommendHandler) meteredWriter(subject string, w io.Writer) io.Writer {
+	if h.quota == nil || subject == "" {
+		return w
+	}
+	return meterWriter{w: w, q: h.quota, subject: subject}
+}
+
+// GET /stream/usage → today's proxied bytes, limit and reset time for the caller's IP.
+func (h *RecommendHandler) GetStreamUsage(c fiber.Ctx) error {
+	if h.quota == nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "stream quotas not enabled"})
+	}
+	subject := services.IPSubject(c.IP())
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	return c.JSON(h.quota.Usage(ctx, subject))
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/services"
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestStreamQuota(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "a.mp3")
+	if err := os.WriteFile(p, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h := &RecommendHandler{}
+	h.UseLibrary(fakeLibrary{"t1": p}, "vibe.example.net")
+	h.UseStreamQuota(services.NewStreamQuota(nil, 5))
+	app := fiber.New()
+	app.Get("/stream/usage", h.GetStreamUsage)
+	app.Get("/stream", h.GetStream)
+
+	get := func(target string) *http.Response {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// Per IP: the first stream may cross the limit, the next is refused.
+	resp := get("/stream?local=t1")
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "0123456789" {
+		t.Fatalf("first stream: %d %q", resp.StatusCode, body)
+	}
+	resp = get("/stream?local=t1")
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("over quota: %d", resp.StatusCode)
+	}
+	if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err != nil || s < 1 || s > 86400 {
+		t.Fatalf("Retry-After %q", resp.Header.Get("Retry-After"))
+	}
+
+	// An unverified ?userId= neither moves the charge nor exposes that user's usage.
+	if resp = get("/stream?local=t1&userId=u1"); resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("userId escaped the IP quota: %d", resp.StatusCode)
+	}
+
+	var u services.StreamUsage
+	resp = get("/stream/usage?userId=u1")
+	if err := json.NewDecoder(resp.Body).Decode(&u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Subject != "ip:0.0.0.0" || u.Used != 10 || u.Limit != 5 {
+		t.Fatalf("usage %+v", u)
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/services"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// UseStreamSigner turns on signed /stream URLs: responses carry streamUrl and
+// /stream rejects unsigned or expired requests.
+func (h *RecommendHandler) UseStreamSigner(s *services.StreamSigner) {
+	h.signer = s
+}
+
+// UseStreamSigner adds streamUrl to search results.
+func (sh *SearchHandler) UseStreamSigner(s *services.StreamSigner) {
+	sh.signer = s
+}
+
+// UseStreamSigner adds streamUrl to vault rows.
+func (fh *FavoritesHandler) UseStreamSigner(s *services.StreamSigner) {
+	fh.signer = s
+}
+
+// streamUser is the optional, unverified ?userId= — for personalization (radio
+// sessions, feedback) only, never for quotas.
+func streamUser(c fiber.Ctx) string {
+	id := c.Query("userId")
+	if id == "" || utils.ValidateUserID(id) != nil {
+		return ""
+	}
+	return id
+}
+
+// streamSignatureError says why a /stream request wasn't minted by us ("" = fine, or
+// signing is off). subject is services.SongSubject / LocalSubject.
+func (h *RecommendHandler) streamSignatureError(c fiber.Ctx, subject string) string {
+	if h.signer == nil {
+		return ""
+	}
+	q := url.Values{}
+	for _, k := range []string{services.StreamParamExpires, services.StreamParamKeyID, services.StreamParamSig} {
+		if v := c.Query(k); v != "" {
+			q.Set(k, v)
+		}
+	}
+	err := h.signer.Verify(subject, q)
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, services.ErrStreamExpired):
+		return "stream url expired"
+	default:
+		return "stream url not signed"
+	}
+}
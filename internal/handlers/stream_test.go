@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStreamAllowedConfiguredHostOnly(t *testing.T) {
+	h := &RecommendHandler{}
+	h.AllowStreamHost("music.example.net:4443")
+
+	cases := map[string]bool{
+		"https://cs1.mp3.pm/listen/1/a.mp3":               true,
+		"https://music.example.net:4443/rest/stream?id=1": true,
+		"https://music.example.net/rest/stream?id=1":      false, // port is part of the grant
+		"https://evil.music.example.net:4443/rest/stream": false,
+		"http://music.example.net:4443/rest/stream?id=1":  false,
+		"https://u:p@music.example.net:4443/rest/stream":  false,
+		"https://example.org/a.mp3":                       false,
+	}
+	for raw, want := range cases {
+		u, _ := url.Parse(raw)
+		if got := h.streamAllowed(u); got != want {
+			t.Fatalf("%s: got %v want %v", raw, got, want)
+		}
+	}
+}
+
+func TestOpenStreamUpstreamAuthorizesConfiguredHost(t *testing.T) {
+	var got atomic.Value
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.Store(r.URL.RawQuery)
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write([]byte("ID3"))
+	}))
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+
+	h := &RecommendHandler{client: srv.Client(), upstream: srv.Client()}
+	h.AuthorizeStreamHost(u.Host, func(u *url.URL) {
+		q := u.Query()
+		q.Set("t", "token")
+		u.RawQuery = q.Encode()
+	})
+	resp, err := h.openStreamUpstream(context.Background(), srv.URL+"/rest/stream?id=1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if q, _ := got.Load().(string); q != "id=1&t=token" {
+		t.Fatalf("upstream query %q", q)
+	}
+}
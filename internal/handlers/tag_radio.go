@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	tagRadioMaxTags    = 3
+	tagRadioTrackPage  = 20 // tag.getTopTracks rows per page
+	tagRadioArtistPage = 8  // tag.getTopArtists rows per page; two top tracks each
+	tagRadioArtistTops = 2
+
+	popularTagsCap = 24
+	popularTagsTTL = 24 * time.Hour
+)
+
+// PopularTag is one genre-picker entry; Image is the cover of the tag's top track.
+type PopularTag struct {
+	Name  string `json:"name"`
+	Image string `json:"image,omitempty"`
+}
+
+// GET /recommend/tag?tags=chill,manele&page=N → a station from Last.fm tags: each
+// tag's top tracks mixed with its top artists' best tracks. page walks deeper into
+// the charts (cached 6h per tags+page); session=new|<id> continues server-side
+// instead, paging on its own.
+func (h *RecommendHandler) GetTagRadio(c fiber.Ctx) error {
+	tags := parseTags(c.Query("tags"))
+	session := c.Query("session") != ""
+	if len(tags) == 0 && !session {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "tags are required"})
+	}
+	if h.apiKey == "" {
+		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "LASTFM_API_KEY not set"})
+	}
+	refresh := c.Query("refresh") == "1" || strings.EqualFold(c.Query("refresh"), "true")
+	if session {
+		return h.getRadioSession(c, radioStart{tags: tags}, refresh)
+	}
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	page = min(max(page, 1), radioTagMaxPages)
+	key := "tag|" + strings.Join(tags, ",") + "|" + strconv.Itoa(page)
+	if !refresh {
+		if songs, ok := h.recommendSnap(key); ok {
+			c.Set("Cache-Control", "private, max-age=21600")
+			return c.JSON(h.signer.SignSongs(h.steerRadio(c, true, songs)))
+		}
+	}
+
+	sfKey := key
+	if refresh {
+		sfKey = key + "|refresh"
+	}
+	v, err, _ := h.recommendSF.Do(sfKey, func() (any, error) {
+		if !refresh {
+			if songs, ok := h.recommendSnap(key); ok {
+				return songs, nil
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Context()), 30*time.Second)
+		defer cancel()
+
+		pairs, err := h.tagPairs(ctx, tags, page)
+		if err != nil {
+			return nil, err
+		}
+		songs := h.resolveN(ctx, pairs, lastfmPair{}, radioResolveCap, refresh, false)
+		if len(songs) == 0 {
+			return nil, errRecommendEmpty
+		}
+		h.covers.FillSongs(ctx, songs)
+		h.recommendStore(key, songs)
+		return songs, nil
+	})
+	if err != nil {
+		if stale, ok := h.recommendStale(key); ok {
+			c.Set("Cache-Control", "private, max-age=60")
+			return c.JSON(h.signer.SignSongs(h.steerRadio(c, true, stale)))
+		}
+		if err == errRecommendEmpty {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Nothing playable for these tags"})
+		}
+		return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": "Radio lookup failed. Try again."})
+	}
+	songs, _ := v.([]domain.Song)
+	c.Set("Cache-Control", "private, max-age=21600")
+	return c.JSON(h.signer.SignSongs(h.steerRadio(c, true, songs)))
+}
+
+// parseTags: "Chill, manele,chill" → [chill manele]; at most tagRadioMaxTags.
+func parseTags(raw string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for t := range strings.SplitSeq(raw, ",") {
+		t = strings.ToLower(strings.Join(strings.Fields(t), " "))
+		if t == "" || len(t) > 64 || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+		if len(out) == tagRadioMaxTags {
+			break
+		}
+	}
+	return out
+}
+
+// tagPairs is page `page` of every tag — top tracks alternating with the best tracks
+// of that page's top artists — mixed round-robin across tags. A tag that fails only
+// loses its share; empty means the charts ran out.
+func (h *RecommendHandler) tagPairs(ctx context.Context, tags []string, page int) ([]lastfmPair, error) {
+	mixes := make([][]lastfmPair, len(tags))
+	errs := make([]error, len(tags))
+	var wg sync.WaitGroup
+	for i, tag := range tags {
+		wg.Go(func() {
+			mixes[i], errs[i] = h.tagPagePairs(ctx, tag, page)
+		})
+	}
+	wg.Wait()
+
+	merged := uniquePairs(interleavePairs(mixes...), lastfmPair{})
+	if len(merged) == 0 {
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+	return balanceArtists(merged, vaultRadioArtistCap), nil
+}
+
+func (h *RecommendHandler) tagPagePairs(ctx context.Context, tag string, page int) ([]lastfmPair, error) {
+	p := strconv.Itoa(page)
+	tracks, err := h.lastfmTracks(ctx, url.Values{
+		"method": {"tag.getTopTracks"}, "tag": {tag}, "page": {p}, "limit": {strconv.Itoa(tagRadioTrackPage)},
+	}, "tracks")
+	if err != nil {
+		return nil, err
+	}
+	artists, err := h.lastfmArtistNames(ctx, url.Values{
+		"method": {"tag.getTopArtists"}, "tag": {tag}, "page": {p}, "limit": {strconv.Itoa(tagRadioArtistPage)},
+	}, "topartists")
+	if err != nil {
+		artists = nil // tracks alone still make a station
+	}
+	tops := make([][]lastfmPair, len(artists))
+	var wg sync.WaitGroup
+	for i, a := range artists {
+		wg.Go(func() {
+			if t, err := h.lastfmArtistTop(ctx, a, ""); err == nil {
+				tops[i] = t[:min(len(t), tagRadioArtistTops)]
+			}
+		})
+	}
+	wg.Wait()
+	return interleavePairs(tracks, interleavePairs(tops...)), nil
+}
+
+// GET /recommend/tags → popular Last.fm tags with a cover each, for a genre picker.
+// Cached 24h.
+func (h *RecommendHandler) GetPopularTags(c fiber.Ctx) error {
+	if h.apiKey == "" {
+		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "LASTFM_API_KEY not set"})
+	}
+	h.tagsMu.Lock()
+	tags, at := h.popularTags, h.popularTagsAt
+	h.tagsMu.Unlock()
+	if len(tags) > 0 && time.Since(at) < popularTagsTTL {
+		c.Set("Cache-Control", "public, max-age=3600")
+		return c.JSON(tags)
+	}
+
+	v, err, _ := h.tagsSF.Do("tags", func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Context()), 20*time.Second)
+		defer cancel()
+		tags, err := h.buildPopularTags(ctx)
+		if err != nil || len(tags) == 0 {
+			return nil, err
+		}
+		h.tagsMu.Lock()
+		h.popularTags, h.popularTagsAt = tags, time.Now()
+		h.tagsMu.Unlock()
+		return tags, nil
+	})
+	fresh, _ := v.([]PopularTag)
+	if err != nil || len(fresh) == 0 {
+		if len(tags) > 0 {
+			c.Set("Cache-Control", "public, max-age=60")
+			return c.JSON(tags)
+		}
+		return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": "Couldn't load tags"})
+	}
+	c.Set("Cache-Control", "public, max-age=3600")
+	return c.JSON(fresh)
+}
+
+func (h *RecommendHandler) buildPopularTags(ctx context.Context) ([]PopularTag, error) {
+	names, err := h.lastfmTopTags(ctx, popularTagsCap)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PopularTag, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		out[i].Name = name
+		wg.Go(func() {
+			tops, err := h.lastfmTracks(ctx, url.Values{
+				"method": {"tag.getTopTracks"}, "tag": {name}, "limit": {"1"},
+			}, "tracks")
+			if err == nil && len(tops) > 0 {
+				out[i].Image = h.covers.Lookup(ctx, tops[0].artist+" "+tops[0].title)
+			}
+		})
+	}
+	wg.Wait()
+	return out, nil
+}
+
+func (h *RecommendHandler) lastfmTopTags(ctx context.Context, limit int) ([]string, error) {
+	names, err := h.lastfmNames(ctx, url.Values{
+		"method": {"chart.getTopTags"}, "limit": {strconv.Itoa(limit)},
+	}, "tags", "tag")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(names))
+	seen := map[string]bool{}
+	for _, n := range names {
+		if n = strings.ToLower(n); !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/gofiber/fiber/v3"
+)
+
+// fakeTagLastfm serves `pages` pages per tag: six chart tracks and two top artists
+// per page, each artist with three top tracks.
+func fakeTagLastfm(pages int) (*http.Client, stubSearch) {
+	f := newFakeLastfm()
+	chart := func(tag string, page int) (tracks []lastfmPair, artists []string) {
+		for i := range 6 {
+			tracks = append(tracks, lastfmPair{artist: fmt.Sprintf("%s p%d t%d", tag, page, i), title: "Hit"})
+		}
+		for i := range 2 {
+			artists = append(artists, fmt.Sprintf("%s p%d a%d", tag, page, i))
+		}
+		return tracks, artists
+	}
+	tops := func(artist string) []lastfmPair {
+		var out []lastfmPair
+		for s := range 3 {
+			out = append(out, lastfmPair{artist: artist, title: fmt.Sprintf("Top %d", s)})
+		}
+		return out
+	}
+	for _, tag := range []string{"chill", "manele"} {
+		for page := 1; page <= pages; page++ {
+			tracks, artists := chart(tag, page)
+			for _, t := range tracks {
+				f.song(t.artist, t.title)
+			}
+			for _, a := range artists {
+				for _, t := range tops(a) {
+					f.song(t.artist, t.title)
+				}
+			}
+		}
+	}
+
+	page := func(q url.Values) int {
+		p, _ := strconv.Atoi(q.Get("page"))
+		return max(p, 1)
+	}
+	f.on("tag.getTopTracks", func(q url.Values) any {
+		var tracks []lastfmPair
+		if p := page(q); p <= pages {
+			tracks, _ = chart(q.Get("tag"), p)
+		}
+		return map[string]any{"tracks": map[string]any{"track": lastfmTracks(tracks)}}
+	})
+	f.on("tag.getTopArtists", func(q url.Values) any {
+		var artists []string
+		if p := page(q); p <= pages {
+			_, artists = chart(q.Get("tag"), p)
+		}
+		return map[string]any{"topartists": map[string]any{"artist": lastfmNames(artists)}}
+	})
+	f.on("artist.getTopTracks", func(q url.Values) any {
+		return map[string]any{"toptracks": map[string]any{"track": lastfmTracks(tops(q.Get("artist")))}}
+	})
+	f.on("chart.getTopTags", func(url.Values) any {
+		return map[string]any{"tags": map[string]any{"tag": lastfmNames([]string{"Chill", "manele", "chill"})}}
+	})
+	return f.client()
+}
+
+func TestParseTags(t *testing.T) {
+	got := parseTags(" Chill ,manele,,chill, hip  hop, rock")
+	if want := []string{"chill", "manele", "hip hop"}; !slices.Equal(got, want) {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestTagRadio(t *testing.T) {
+	client, search := fakeTagLastfm(3)
+	h := &RecommendHandler{client: client, apiKey: "k", search: search}
+	app := fiber.New()
+	app.Get("/recommend/tag", h.GetTagRadio)
+	app.Get("/recommend/tags", h.GetPopularTags)
+
+	get := func(path string, out any) *http.Response {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return resp
+	}
+
+	var p1, p2 []domain.Song
+	if resp := get("/recommend/tag?tags=chill,manele&page=1", &p1); resp.StatusCode != http.StatusOK {
+		t.Fatalf("page 1: status %d", resp.StatusCode)
+	}
+	get("/recommend/tag?tags=chill,manele&page=2", &p2)
+	tags := map[string]bool{}
+	fromArtistTops := false
+	for _, s := range p1 {
+		tags[strings.Fields(s.Artist)[0]] = true
+		fromArtistTops = fromArtistTops || strings.HasPrefix(s.Title, "Top ")
+		if !strings.Contains(s.Artist, " p1 ") {
+			t.Fatalf("page 1 served %+v", s)
+		}
+	}
+	if !tags["chill"] || !tags["manele"] || !fromArtistTops {
+		t.Fatalf("page 1 not mixed: %+v", p1)
+	}
+	if len(p2) == 0 || !strings.Contains(p2[0].Artist, " p2 ") {
+		t.Fatalf("page 2: %+v", p2)
+	}
+
+	// A session pages on by itself and ends when the charts run out.
+	var songs []domain.Song
+	resp := get("/recommend/tag?tags=chill&session=new", &songs)
+	id := resp.Header.Get("X-Radio-Session")
+	seen := map[string]bool{}
+	for batch := 0; resp.StatusCode == http.StatusOK; batch++ {
+		if batch > 20 {
+			t.Fatal("session never ended")
+		}
+		for _, s := range songs {
+			if seen[s.Id] {
+				t.Fatalf("repeated %s", s.Id)
+			}
+			seen[s.Id] = true
+		}
+		songs = nil
+		resp = get("/recommend/tag?session="+id, &songs)
+	}
+	if resp.StatusCode != http.StatusGone || len(seen) < 15 {
+		t.Fatalf("session: status %d after %d songs", resp.StatusCode, len(seen))
+	}
+
+	var popular []PopularTag
+	if resp := get("/recommend/tags", &popular); resp.StatusCode != http.StatusOK || len(popular) != 2 || popular[0].Name != "chill" {
+		t.Fatalf("tags: status %d %+v", resp.StatusCode, popular)
+	}
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	vaultRadioSeeds     = 4
+	vaultRadioHalfLife  = 30 * 24 * time.Hour // a save/play a month old weighs half
+	vaultRadioArtistCap = 2                   // tracks per artist in one batch
+)
+
+// favoritesLister is the vault read behind /favorites/:userId/zip and vault radio.
+type favoritesLister interface {
+	GetFavorites(ctx context.Context, userId string) ([]domain.FavoriteSong, error)
+}
+
+// UseVault lets vault ZIPs and /recommend/vault read a user's favorites.
+func (h *RecommendHandler) UseVault(favs favoritesLister) {
+	h.favorites = favs
+}
+
+// GET /recommend/vault?userId=&offset=N → radio seeded from several of the user's
+// favorites (weighted by plays and recency), minus what's already in the vault.
+// Cached 6h per user + offset. The seed draw, and so the pick order, is stable within
+// a UTC day; offset=N starts the page N picks in, so pages step by radioResolveCap.
+func (h *RecommendHandler) GetVaultRadio(c fiber.Ctx) error {
+	userId := strings.TrimSpace(c.Query("userId"))
+	if err := utils.ValidateUserID(userId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if h.favorites == nil {
+		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "vault not available"})
+	}
+	if h.apiKey == "" {
+		return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "LASTFM_API_KEY not set"})
+	}
+	offset, _ := strconv.Atoi(c.Query("offset"))
+	offset = max(offset, 0)
+
+	refresh := c.Query("refresh") == "1" || strings.EqualFold(c.Query("refresh"), "true")
+	key := "vault|" + userId + "|" + strconv.Itoa(offset)
+	if !refresh {
+		if songs, ok := h.recommendSnap(key); ok {
+			c.Set("Cache-Control", "private, max-age=21600")
+			return c.JSON(h.signer.SignSongs(h.steerVault(c, userId, songs)))
+		}
+	}
+
+	sfKey := key
+	if refresh {
+		sfKey = key + "|refresh"
+	}
+	v, err, _ := h.recommendSF.Do(sfKey, func() (any, error) {
+		if !refresh {
+			if songs, ok := h.recommendSnap(key); ok {
+				return songs, nil
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Context()), 30*time.Second)
+		defer cancel()
+
+		favs, err := h.favorites.GetFavorites(ctx, userId)
+		if err != nil {
+			return nil, err
+		}
+		if len(favs) == 0 {
+			return nil, errRecommendEmpty
+		}
+		now := time.Now().UTC()
+		seeds := sampleVaultSeeds(favs, vaultRadioSeeds, now, vaultRadioRand(userId, now))
+
+		pairs, err := h.vaultPairs(ctx, seeds, refresh)
+		if err != nil {
+			return nil, err
+		}
+		inVault := make(map[string]bool, len(favs))
+		for _, f := range favs {
+			inVault[songKey(f.Artist, f.Title)] = true
+		}
+		fresh := pairs[:0]
+		for _, p := range pairs {
+			if !inVault[songKey(p.artist, p.title)] {
+				fresh = append(fresh, p)
+			}
+		}
+		pairs = balanceArtists(steerPairs(fresh, h.feedbackProfile(ctx, userId)), vaultRadioArtistCap)
+		// Each page is its own window of the day's sequence, so consecutive pages never
+		// share a pick (past the end it starts over).
+		if len(pairs) > 0 {
+			off := offset % len(pairs)
+			pairs = pairs[off:min(off+radioResolveCap, len(pairs))]
+		}
+
+		// Resolution can land on a vault track under another title spelling.
+		resolved := h.resolveN(ctx, pairs, lastfmPair{}, radioResolveCap, refresh, false)
+		songs := make([]domain.Song, 0, len(resolved))
+		for _, s := range resolved {
+			if !inVault[songKey(s.Artist, s.Title)] {
+				songs = append(songs, s)
+			}
+		}
+		if len(songs) == 0 {
+			return nil, errRecommendEmpty
+		}
+		h.covers.FillSongs(ctx, songs)
+		h.recommendStore(key, songs)
+		return songs, nil
+	})
+	if err != nil {
+		if stale, ok := h.recommendStale(key); ok {
+			c.Set("Cache-Control", "private, max-age=60")
+			return c.JSON(h.signer.SignSongs(h.steerVault(c, userId, stale)))
+		}
+		if err == errRecommendEmpty {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Save a few songs to get a vault radio"})
+		}
+		return c.Status(http.StatusBadGateway).JSON(fiber.Map{"error": "Radio lookup failed. Try again."})
+	}
+	songs, _ := v.([]domain.Song)
+	c.Set("Cache-Control", "private, max-age=21600")
+	return c.JSON(h.signer.SignSongs(h.steerVault(c, userId, songs)))
+}
+
+// steerVault re-applies feedback given since the batch was cached.
+func (h *RecommendHandler) steerVault(c fiber.Ctx, userId string, songs []domain.Song) []domain.Song {
+	if h.feedback == nil {
+		return songs
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 3*time.Second)
+	defer cancel()
+	return steerSongs(songs, h.feedbackProfile(ctx, userId))
+}
+
+// vaultPairs merges each seed's radio neighborhood round-robin so no single seed
+// dominates the top of the queue. One seed failing only loses its share.
+func (h *RecommendHandler) vaultPairs(ctx context.Context, seeds []lastfmPair, refresh bool) ([]lastfmPair, error) {
+	hoods := make([][]lastfmPair, len(seeds))
+	errs := make([]error, len(seeds))
+	var wg sync.WaitGroup
+	for i, seed := range seeds {
+		wg.Go(func() {
+			hoods[i], errs[i] = h.pairsFor(ctx, seed, artistCandidates(seed.artist), true, refresh)
+		})
+	}
+	wg.Wait()
+
+	merged := interleavePairs(hoods...)
+	if len(merged) == 0 {
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, errRecommendEmpty
+	}
+	return uniquePairs(merged, lastfmPair{}), nil
+}
+
+// interleavePairs takes one from each list in turn until all are used up.
+func interleavePairs(lists ...[]lastfmPair) []lastfmPair {
+	var out []lastfmPair
+	for i := 0; ; i++ {
+		added := false
+		for _, l := range lists {
+			if i < len(l) {
+				out = append(out, l[i])
+				added = true
+			}
+		}
+		if !added {
+			return out
+		}
+	}
+}
+
+// vaultRadioRand keeps a user's draw stable for the day so offset pages line up.
+func vaultRadioRand(userId string, now time.Time) *rand.Rand {
+	f := fnv.New64a()
+	f.Write([]byte(userId + "|" + now.Format(time.DateOnly)))
+	return rand.New(rand.NewPCG(f.Sum64(), 0))
+}
+
+// sampleVaultSeeds draws up to n favorites without replacement, one per artist,
+// weighted by (1 + plays) halved every vaultRadioHalfLife since last save/play.
+func sampleVaultSeeds(favs []domain.FavoriteSong, n int, now time.Time, rng *rand.Rand) []lastfmPair {
+	type cand struct {
+		pair   lastfmPair
+		artist string
+		weight float64
+	}
+	cands := make([]cand, 0, len(favs))
+	for _, f := range favs {
+		if strings.TrimSpace(f.Artist) == "" || strings.TrimSpace(f.Title) == "" {
+			continue
+		}
+		last := f.CreatedAt
+		if f.LastPlayedAt != nil && f.LastPlayedAt.After(last) {
+			last = *f.LastPlayedAt
+		}
+		age := max(now.Sub(last), 0)
+		w := float64(1+max(f.PlayCount, 0)) * math.Exp2(-float64(age)/float64(vaultRadioHalfLife))
+		cands = append(cands, cand{
+			pair:   lastfmPair{artist: f.Artist, title: f.Title},
+			artist: utils.NormalizeString(f.Artist),
+			weight: w,
+		})
+	}
+
+	var out []lastfmPair
+	for len(out) < n && len(cands) > 0 {
+		total := 0.0
+		for _, c := range cands {
+			total += c.weight
+		}
+		r := rng.Float64() * total
+		pick := len(cands) - 1
+		for i, c := range cands {
+			if r < c.weight {
+				pick = i
+				break
+			}
+			r -= c.weight
+		}
+		chosen := cands[pick]
+		out = append(out, chosen.pair)
+		kept := cands[:0]
+		for _, c := range cands {
+			if c.artist != chosen.artist {
+				kept = append(kept, c)
+			}
+		}
+		cands = kept
+	}
+	return out
+}
+
+// balanceArtists keeps at most perArtist pairs per artist and, where it can, avoids
+// the same artist twice in a row; otherwise order is kept.
+func balanceArtists(pairs []lastfmPair, perArtist int) []lastfmPair {
+	counts := map[string]int{}
+	var pending []lastfmPair
+	for _, p := range pairs {
+		a := utils.NormalizeString(p.artist)
+		if counts[a] >= perArtist {
+			continue
+		}
+		counts[a]++
+		pending = append(pending, p)
+	}
+
+	out := make([]lastfmPair, 0, len(pending))
+	prev := ""
+	for len(pending) > 0 {
+		pick := 0
+		for i, p := range pending {
+			if utils.NormalizeString(p.artist) != prev {
+				pick = i
+				break
+			}
+		}
+		p := pending[pick]
+		pending = append(pending[:pick], pending[pick+1:]...)
+		out = append(out, p)
+		prev = utils.NormalizeString(p.artist)
+	}
+	return out
+}
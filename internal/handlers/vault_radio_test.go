@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestSampleVaultSeeds(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	played := now.Add(-time.Hour)
+	favs := []domain.FavoriteSong{
+		{Artist: "Old", Title: "Dusty", CreatedAt: now.Add(-365 * 24 * time.Hour)},
+		{Artist: "Loved", Title: "Anthem", CreatedAt: now.Add(-365 * 24 * time.Hour), PlayCount: 40, LastPlayedAt: &played},
+		{Artist: "Loved", Title: "B-Side", CreatedAt: now},
+		{Artist: "New", Title: "Fresh", CreatedAt: now},
+		{Artist: "", Title: "No Artist", CreatedAt: now},
+	}
+
+	hits := map[string]int{}
+	for i := range 200 {
+		seeds := sampleVaultSeeds(favs, 2, now, rand.New(rand.NewPCG(uint64(i), 0)))
+		if len(seeds) != 2 || seeds[0].artist == seeds[1].artist {
+			t.Fatalf("want 2 seeds from distinct artists, got %+v", seeds)
+		}
+		for _, s := range seeds {
+			hits[s.title]++
+		}
+	}
+	if hits["Anthem"] < hits["B-Side"] || hits["Anthem"] < 150 {
+		t.Fatalf("play count / recency not weighted: %v", hits)
+	}
+	if hits["Dusty"] > 20 || hits["No Artist"] != 0 {
+		t.Fatalf("stale or blank favorites drawn too often: %v", hits)
+	}
+
+	all := sampleVaultSeeds(favs, 10, now, rand.New(rand.NewPCG(1, 0)))
+	if len(all) != 3 {
+		t.Fatalf("one seed per artist: %+v", all)
+	}
+}
+
+func TestBalanceArtists(t *testing.T) {
+	in := []lastfmPair{
+		{artist: "A", title: "1"}, {artist: "A", title: "2"}, {artist: "A", title: "3"},
+		{artist: "B", title: "1"}, {artist: "b", title: "2"}, {artist: "C", title: "1"},
+	}
+	got := ""
+	for _, p := range balanceArtists(in, 2) {
+		got += p.artist + p.title + " "
+	}
+	if want := "A1 B1 A2 b2 C1 "; got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestVaultRadioPagesDoNotOverlap(t *testing.T) {
+	client, search := fakeLastfm(40)
+	var vault fakeVault
+	for _, a := range []int{5, 15, 25, 35} {
+		vault = append(vault, domain.FavoriteSong{Artist: fmt.Sprintf("A%d", a), Title: "Song 0", CreatedAt: time.Now()})
+	}
+	h := &RecommendHandler{client: client, apiKey: "k", search: search}
+	h.UseVault(vault)
+	app := fiber.New()
+	app.Get("/recommend/vault", h.GetVaultRadio)
+
+	// The day's sequence holds more than one page of picks but less than two.
+	seen := map[string]int{}
+	for page := range 2 {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet,
+			fmt.Sprintf("/recommend/vault?userId=9b2f3c1e-7a0d-4c5e-8f1a-2b3c4d5e6f70&offset=%d", page*radioResolveCap), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var songs []domain.Song
+		err = json.NewDecoder(resp.Body).Decode(&songs)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != http.StatusOK || len(songs) == 0 || (page == 0 && len(songs) != radioResolveCap) {
+			t.Fatalf("page %d: status %d, %d songs, %v", page, resp.StatusCode, len(songs), err)
+		}
+		for _, s := range songs {
+			if p, dup := seen[s.Id]; dup {
+				t.Fatalf("%s on page %d and %d", s.Id, p, page)
+			}
+			seen[s.Id] = page
+		}
+	}
+}
+
+// fakeLastfm is a chain of artists A0 → A1 → … → A{n-1}, each similar only to its
+// neighbours and with three top tracks.
+func fakeLastfm(n int) (*http.Client, stubSearch) {
+	f := newFakeLastfm()
+	for a := range n {
+		for s := range 3 {
+			f.song(fmt.Sprintf("A%d", a), fmt.Sprintf("Song %d", s))
+		}
+	}
+	index := func(q url.Values) int {
+		i, _ := strconv.Atoi(strings.TrimPrefix(q.Get("artist"), "A"))
+		return i
+	}
+	f.on("artist.getSimilar", func(q url.Values) any {
+		var names []string
+		for _, i := range []int{index(q) - 1, index(q) + 1} {
+			if i >= 0 && i < n {
+				names = append(names, fmt.Sprintf("A%d", i))
+			}
+		}
+		return map[string]any{"similarartists": map[string]any{"artist": lastfmNames(names)}}
+	})
+	f.on("artist.getTopTracks", func(q url.Values) any {
+		var tracks []lastfmPair
+		for s := range 3 {
+			tracks = append(tracks, lastfmPair{artist: q.Get("artist"), title: fmt.Sprintf("Song %d", s)})
+		}
+		return map[string]any{"toptracks": map[string]any{"track": lastfmTracks(tracks)}}
+	})
+	return f.client()
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RequireAdmin gates operator endpoints on "Authorization: Bearer <token>" (or X-Admin-Token).
+// An empty token disables the routes entirely — they 404 rather than advertise themselves.
+func RequireAdmin(token string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if token == "" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "not found"})
+		}
+		got := c.Get("X-Admin-Token")
+		if auth := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(auth, "Bearer ") {
+			got = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
+		return c.Next()
+	}
+}
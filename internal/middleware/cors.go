@@ -30,7 +30,7 @@ func NewCORS() cors.Config {
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "ngrok-skip-browser-warning", "X-Requested-With"},
 		AllowCredentials: true,
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "Retry-After", "X-Radio-Session"},
 		MaxAge:           86400, // cache preflight — fewer OPTIONS round-trips
 	}
 
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CookieRepository persists the scrape cookie jar (utils.CookieStore).
+type CookieRepository struct {
+	DB *gorm.DB
+}
+
+type scrapeCookieRow struct {
+	Egress   string `gorm:"primaryKey"`
+	Host     string `gorm:"primaryKey"`
+	Domain   string `gorm:"primaryKey"`
+	Path     string `gorm:"primaryKey"`
+	Name     string `gorm:"primaryKey"`
+	Value    string
+	Expires  time.Time
+	Secure   bool
+	HttpOnly bool `gorm:"column:http_only"`
+}
+
+func (scrapeCookieRow) TableName() string { return "scrape_cookies" }
+
+func NewCookieRepository(db *gorm.DB) *CookieRepository {
+	return &CookieRepository{DB: db}
+}
+
+func (cr *CookieRepository) LoadCookies(ctx context.Context) ([]utils.StoredCookie, error) {
+	var rows []scrapeCookieRow
+	if err := cr.DB.WithContext(ctx).Where("expires > ?", time.Now()).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("cookie repository: load failed: %w", err)
+	}
+	out := make([]utils.StoredCookie, len(rows))
+	for i, r := range rows {
+		out[i] = utils.StoredCookie{
+			Egress: r.Egress, Host: r.Host, Domain: r.Domain, Path: r.Path, Name: r.Name,
+			Value: r.Value, Expires: r.Expires, Secure: r.Secure, HttpOnly: r.HttpOnly,
+		}
+	}
+	return out, nil
+}
+
+// SaveCookies upserts the jar snapshot per (egress, host, domain, path, name) and
+// drops only expired rows, so instances sharing a DB never wipe each other's jars.
+func (cr *CookieRepository) SaveCookies(ctx context.Context, cookies []utils.StoredCookie) error {
+	rows := make([]scrapeCookieRow, len(cookies))
+	for i, c := range cookies {
+		rows[i] = scrapeCookieRow{
+			Egress: c.Egress, Host: c.Host, Domain: c.Domain, Path: c.Path, Name: c.Name,
+			Value: c.Value, Expires: c.Expires, Secure: c.Secure, HttpOnly: c.HttpOnly,
+		}
+	}
+	return cr.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM scrape_cookies WHERE expires <= ?", time.Now()).Error; err != nil {
+			return fmt.Errorf("cookie repository: prune failed: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(rows, 200).Error; err != nil {
+			return fmt.Errorf("cookie repository: upsert failed: %w", err)
+		}
+		return nil
+	})
+}
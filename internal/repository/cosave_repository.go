@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"gorm.io/gorm"
+)
+
+type CoSaveRepository struct {
+	DB *gorm.DB
+}
+
+func NewCoSaveRepository(db *gorm.DB) *CoSaveRepository {
+	return &CoSaveRepository{DB: db}
+}
+
+func (cr *CoSaveRepository) Saves(ctx context.Context, perUser int) ([]domain.FavoriteSong, error) {
+	var rows []domain.FavoriteSong
+	if err := cr.DB.WithContext(ctx).Raw(
+		`SELECT user_uuid, artist, title FROM (
+			SELECT user_uuid, artist, title, row_number() OVER (PARTITION BY user_uuid ORDER BY created_at DESC) AS rn
+			FROM favorite_songs
+		) f WHERE rn <= ?`,
+		perUser,
+	).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("cosave repository: saves read failed: %w", err)
+	}
+	return rows, nil
+}
+
+// ReplaceCoSaves swaps the table under a transaction-scoped advisory lock, so two
+// overlapping rebuilds queue instead of colliding on the primary key.
+func (cr *CoSaveRepository) ReplaceCoSaves(ctx context.Context, rows []domain.CoSave) error {
+	return cr.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('song_cosaves'))").Error; err != nil {
+			return fmt.Errorf("cosave repository: lock failed: %w", err)
+		}
+		if err := tx.Exec("DELETE FROM song_cosaves").Error; err != nil {
+			return fmt.Errorf("cosave repository: clear failed: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+			return fmt.Errorf("cosave repository: insert failed: %w", err)
+		}
+		return nil
+	})
+}
+
+func (cr *CoSaveRepository) GetCoSaves(ctx context.Context, keys []string, perKey int) ([]domain.CoSave, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	var rows []domain.CoSave
+	if err := cr.DB.WithContext(ctx).Raw(
+		`SELECT song_key, other_key, artist, title, users, score FROM (
+			SELECT *, row_number() OVER (PARTITION BY song_key ORDER BY score DESC, users DESC) AS rn
+			FROM song_cosaves WHERE song_key IN ?
+		) c WHERE rn <= ?`,
+		keys, perKey,
+	).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("cosave repository: read failed: %w", err)
+	}
+	return rows, nil
+}
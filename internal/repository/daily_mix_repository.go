@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"gorm.io/gorm"
+)
+
+type DailyMixRepository struct {
+	DB *gorm.DB
+}
+
+func NewDailyMixRepository(db *gorm.DB) *DailyMixRepository {
+	return &DailyMixRepository{DB: db}
+}
+
+func (dr *DailyMixRepository) UsersDue(ctx context.Context, day time.Time, minSaves int) ([]string, error) {
+	var users []string
+	if err := dr.DB.WithContext(ctx).Raw(
+		`SELECT f.user_uuid FROM favorite_songs f
+		WHERE NOT EXISTS (SELECT 1 FROM daily_mixes m WHERE m.user_uuid = f.user_uuid AND m.day = ?)
+		GROUP BY f.user_uuid HAVING count(*) >= ?`,
+		day.UTC().Format(time.DateOnly), minSaves,
+	).Scan(&users).Error; err != nil {
+		return nil, fmt.Errorf("daily mix repository: due users read failed: %w", err)
+	}
+	return users, nil
+}
+
+func (dr *DailyMixRepository) GetMixes(ctx context.Context, userId string) ([]domain.DailyMix, error) {
+	var mixes []domain.DailyMix
+	if err := dr.DB.WithContext(ctx).
+		Where("user_uuid = ? AND day = (SELECT max(day) FROM daily_mixes WHERE user_uuid = ?)", userId, userId).
+		Order("idx").
+		Find(&mixes).Error; err != nil {
+		return nil, fmt.Errorf("daily mix repository: read failed: %w", err)
+	}
+	return mixes, nil
+}
+
+func (dr *DailyMixRepository) SaveMixes(ctx context.Context, userId string, mixes []domain.DailyMix) error {
+	return dr.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM daily_mixes WHERE user_uuid = ?", userId).Error; err != nil {
+			return fmt.Errorf("daily mix repository: clear failed: %w", err)
+		}
+		if len(mixes) == 0 {
+			return nil
+		}
+		if err := tx.Create(&mixes).Error; err != nil {
+			return fmt.Errorf("daily mix repository: insert failed: %w", err)
+		}
+		return nil
+	})
+}
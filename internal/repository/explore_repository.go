@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExploreRepository struct {
+	DB *gorm.DB
+}
+
+func NewExploreRepository(db *gorm.DB) *ExploreRepository {
+	return &ExploreRepository{DB: db}
+}
+
+func (er *ExploreRepository) GetSnapshots(ctx context.Context) ([]domain.ExploreSnapshot, error) {
+	var snaps []domain.ExploreSnapshot
+	if err := er.DB.WithContext(ctx).Find(&snaps).Error; err != nil {
+		return nil, fmt.Errorf("explore repository: read failed: %w", err)
+	}
+	return snaps, nil
+}
+
+func (er *ExploreRepository) SaveSnapshot(ctx context.Context, snap domain.ExploreSnapshot) error {
+	if err := er.DB.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&snap).Error; err != nil {
+		return fmt.Errorf("explore repository: save failed: %w", err)
+	}
+	return nil
+}
@@ -82,6 +82,23 @@ func (fr *FavoritesRepository) UpdateFavoriteLink(ctx context.Context, songId, l
 	return fr.updateFavoriteField(ctx, songId, "link", link)
 }
 
+// RecordFavoritePlay bumps play_count in place (concurrent plays never lose a count).
+func (fr *FavoritesRepository) RecordFavoritePlay(ctx context.Context, songId string) error {
+	res := fr.DB.WithContext(ctx).Model(&domain.FavoriteSong{}).
+		Where("id = ?", songId).
+		Updates(map[string]any{
+			"play_count":     gorm.Expr("play_count + 1"),
+			"last_played_at": gorm.Expr("CURRENT_TIMESTAMP"),
+		})
+	if res.Error != nil {
+		return fmt.Errorf("favorites repository: record play failed: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
 // Update first; only Count when RowsAffected is 0 (unchanged value or missing row).
 func (fr *FavoritesRepository) updateFavoriteField(ctx context.Context, songId, column, value string) error {
 	res := fr.DB.WithContext(ctx).Model(&domain.FavoriteSong{}).
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"gorm.io/gorm"
+)
+
+type FeedbackRepository struct {
+	DB *gorm.DB
+}
+
+func NewFeedbackRepository(db *gorm.DB) *FeedbackRepository {
+	return &FeedbackRepository{DB: db}
+}
+
+func (fr *FeedbackRepository) SetVerdict(ctx context.Context, fb domain.RadioFeedback) error {
+	if err := fr.DB.WithContext(ctx).Exec(
+		`INSERT INTO radio_feedback (user_uuid, song_key, artist, title, verdict, updated_at) VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_uuid, song_key) DO UPDATE SET verdict = EXCLUDED.verdict, artist = EXCLUDED.artist, title = EXCLUDED.title, updated_at = CURRENT_TIMESTAMP`,
+		fb.UserID, fb.SongKey, fb.Artist, fb.Title, fb.Verdict,
+	).Error; err != nil {
+		return fmt.Errorf("feedback repository: set verdict failed: %w", err)
+	}
+	return nil
+}
+
+func (fr *FeedbackRepository) AddSkip(ctx context.Context, fb domain.RadioFeedback) error {
+	if err := fr.DB.WithContext(ctx).Exec(
+		`INSERT INTO radio_feedback (user_uuid, song_key, artist, title, skips, updated_at) VALUES (?, ?, ?, ?, 1, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_uuid, song_key) DO UPDATE SET skips = radio_feedback.skips + 1, updated_at = CURRENT_TIMESTAMP`,
+		fb.UserID, fb.SongKey, fb.Artist, fb.Title,
+	).Error; err != nil {
+		return fmt.Errorf("feedback repository: add skip failed: %w", err)
+	}
+	return nil
+}
+
+func (fr *FeedbackRepository) GetFeedback(ctx context.Context, userId string, limit int) ([]domain.RadioFeedback, error) {
+	var rows []domain.RadioFeedback
+	if err := fr.DB.WithContext(ctx).
+		Where("user_uuid = ?", userId).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("feedback repository: read failed: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JobLeaseRepository keeps one lease row per background job so only one instance
+// runs it per period. Taking a lease is a single upsert that only wins once the
+// previous one ran out.
+type JobLeaseRepository struct {
+	DB *gorm.DB
+}
+
+func NewJobLeaseRepository(db *gorm.DB) *JobLeaseRepository {
+	return &JobLeaseRepository{DB: db}
+}
+
+func (jr *JobLeaseRepository) TryLease(ctx context.Context, job string, ttl time.Duration) (bool, error) {
+	res := jr.DB.WithContext(ctx).Exec(
+		`INSERT INTO job_leases (job, until) VALUES (?, CURRENT_TIMESTAMP + ?::interval)
+		ON CONFLICT (job) DO UPDATE SET until = EXCLUDED.until
+		WHERE job_leases.until <= CURRENT_TIMESTAMP`,
+		job, fmt.Sprintf("%d milliseconds", ttl.Milliseconds()),
+	)
+	if res.Error != nil {
+		return false, fmt.Errorf("job lease repository: lease failed: %w", res.Error)
+	}
+	return res.RowsAffected == 1, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andiq123/FindVibeFiber/internal/core/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LibraryRepository struct {
+	DB *gorm.DB
+}
+
+func NewLibraryRepository(db *gorm.DB) *LibraryRepository {
+	return &LibraryRepository{DB: db}
+}
+
+func (lr *LibraryRepository) GetTrack(ctx context.Context, id string) (*domain.LibraryTrack, error) {
+	var t domain.LibraryTrack
+	err := lr.DB.WithContext(ctx).Where("id = ?", id).Take(&t).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("library repository: get failed: %w", err)
+	}
+	return &t, nil
+}
+
+func (lr *LibraryRepository) SearchTracks(ctx context.Context, query string, limit, offset int) ([]domain.LibraryTrack, error) {
+	tokens := strings.Fields(query)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	tx := lr.DB.WithContext(ctx).Model(&domain.LibraryTrack{})
+	for _, tok := range tokens {
+		tx = tx.Where("search_text LIKE ?", "%"+escapeLike(tok)+"%")
+	}
+	var tracks []domain.LibraryTrack
+	if err := tx.Order("artist ASC, title ASC").Limit(limit).Offset(offset).Find(&tracks).Error; err != nil {
+		return nil, fmt.Errorf("library repository: search failed: %w", err)
+	}
+	return tracks, nil
+}
+
+func (lr *LibraryRepository) TrackStamps(ctx context.Context) (map[string]domain.LibraryTrack, error) {
+	var rows []domain.LibraryTrack
+	if err := lr.DB.WithContext(ctx).Select("id", "size", "mod_time").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("library repository: stamps failed: %w", err)
+	}
+	out := make(map[string]domain.LibraryTrack, len(rows))
+	for _, r := range rows {
+		out[r.ID] = r
+	}
+	return out, nil
+}
+
+func (lr *LibraryRepository) UpsertTrack(ctx context.Context, track domain.LibraryTrack) error {
+	err := lr.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		UpdateAll: true,
+	}).Create(&track).Error
+	if err != nil {
+		return fmt.Errorf("library repository: upsert failed: %w", err)
+	}
+	return nil
+}
+
+func (lr *LibraryRepository) DeleteTracks(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := lr.DB.WithContext(ctx).Delete(&domain.LibraryTrack{}, "id IN ?", ids).Error; err != nil {
+		return fmt.Errorf("library repository: delete failed: %w", err)
+	}
+	return nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
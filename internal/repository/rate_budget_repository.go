@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// RateBudgetRepository keeps one token bucket row per provider so every instance
+// draws from the same budget. Refill + take is a single conditional UPDATE.
+type RateBudgetRepository struct {
+	DB *gorm.DB
+}
+
+func NewRateBudgetRepository(db *gorm.DB) *RateBudgetRepository {
+	return &RateBudgetRepository{DB: db}
+}
+
+func (rr *RateBudgetRepository) TryTake(ctx context.Context, provider string, rate float64, burst int) (bool, error) {
+	db := rr.DB.WithContext(ctx)
+	if err := db.Exec(
+		`INSERT INTO provider_rate_buckets (provider, tokens, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (provider) DO NOTHING`, provider, burst,
+	).Error; err != nil {
+		return false, fmt.Errorf("rate budget repository: seed failed: %w", err)
+	}
+	res := db.Exec(
+		`UPDATE provider_rate_buckets
+		SET tokens = LEAST(?::float8, tokens + EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - updated_at)) * ?::float8) - 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE provider = ?
+			AND LEAST(?::float8, tokens + EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - updated_at)) * ?::float8) >= 1`,
+		burst, rate, provider, burst, rate,
+	)
+	if res.Error != nil {
+		return false, fmt.Errorf("rate budget repository: take failed: %w", res.Error)
+	}
+	return res.RowsAffected == 1, nil
+}
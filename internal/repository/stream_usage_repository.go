@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StreamUsageRepository counts proxied bytes per subject per UTC day. Adds are one
+// upsert so instances never overwrite each other.
+type StreamUsageRepository struct {
+	DB *gorm.DB
+}
+
+func NewStreamUsageRepository(db *gorm.DB) *StreamUsageRepository {
+	return &StreamUsageRepository{DB: db}
+}
+
+func (sr *StreamUsageRepository) AddUsage(ctx context.Context, subject string, day time.Time, n int64) error {
+	if err := sr.DB.WithContext(ctx).Exec(
+		`INSERT INTO stream_usage (subject, day, bytes, updated_at) VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (subject, day) DO UPDATE SET bytes = stream_usage.bytes + EXCLUDED.bytes, updated_at = CURRENT_TIMESTAMP`,
+		subject, day.UTC().Format(time.DateOnly), n,
+	).Error; err != nil {
+		return fmt.Errorf("stream usage repository: add failed: %w", err)
+	}
+	return nil
+}
+
+func (sr *StreamUsageRepository) Usage(ctx context.Context, subject string, day time.Time) (int64, error) {
+	var bytes []int64
+	if err := sr.DB.WithContext(ctx).Raw(
+		`SELECT bytes FROM stream_usage WHERE subject = ? AND day = ?`,
+		subject, day.UTC().Format(time.DateOnly),
+	).Scan(&bytes).Error; err != nil {
+		return 0, fmt.Errorf("stream usage repository: read failed: %w", err)
+	}
+	if len(bytes) == 0 {
+		return 0, nil
+	}
+	return bytes[0], nil
+}
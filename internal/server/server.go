@@ -44,7 +44,7 @@ func NewServer(cfg config.ServerConfig) *Server {
 	app.Use(compress.New(compress.Config{
 		Next: func(c fiber.Ctx) bool {
 			path := c.Path()
-			if path == "/stream" {
+			if path == "/stream" || path == "/download" || strings.HasSuffix(path, "/zip") {
 				return true
 			}
 			stream := c.Query("stream") == "1" || strings.EqualFold(c.Query("stream"), "true")
@@ -74,6 +74,9 @@ func NewServer(cfg config.ServerConfig) *Server {
 	app.Get("/health/sources", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
 		return h.Health.GetSources(c)
 	}))
+	app.Get("/health/limits", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Health.GetLimits(c)
+	}))
 	app.Get("/suggest", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
 		return h.Suggestions.GetSuggestions(c)
 	}))
@@ -83,6 +86,30 @@ func NewServer(cfg config.ServerConfig) *Server {
 	app.Get("/recommend", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
 		return h.Recommend.GetRecommend(c)
 	}))
+	app.Post("/recommend/feedback", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Recommend.PostFeedback(c)
+	}))
+	app.Delete("/recommend/session/:id", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Recommend.DeleteRadioSession(c)
+	}))
+	app.Get("/recommend/tag", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Recommend.GetTagRadio(c)
+	}))
+	app.Get("/recommend/tags", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Recommend.GetPopularTags(c)
+	}))
+	app.Get("/recommend/artist", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Recommend.GetArtistRadio(c)
+	}))
+	app.Get("/recommend/because", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Recommend.GetBecauseSaved(c)
+	}))
+	app.Get("/recommend/mixes", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Recommend.GetDailyMixes(c)
+	}))
+	app.Get("/recommend/vault", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Recommend.GetVaultRadio(c)
+	}))
 	app.Get("/similar-artists", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
 		return h.Recommend.GetSimilarArtists(c)
 	}))
@@ -110,14 +137,31 @@ func NewServer(cfg config.ServerConfig) *Server {
 	app.Get("/stream", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
 		return h.Recommend.GetStream(c)
 	}))
+	app.Get("/stream/usage", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Recommend.GetStreamUsage(c)
+	}))
+	app.Get("/download", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Recommend.GetDownload(c)
+	}))
 	app.Get("/spotify/playlist", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
 		return h.Spotify.GetPlaylist(c)
 	}))
+	app.Get("/spotify/playlist/zip", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Recommend.GetPlaylistZip(c)
+	}))
+
+	admin := app.Group("/admin", middleware.RequireAdmin(cfg.AdminToken))
+	admin.Get("/proxies", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Admin.GetProxies(c)
+	}))
 
 	favorites := app.Group("/favorites")
 	favorites.Get("/:userId", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
 		return h.Favorites.GetFavorites(c)
 	}))
+	favorites.Get("/:userId/zip", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Recommend.GetFavoritesZip(c)
+	}))
 	favorites.Post("/:userId", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
 		return h.Favorites.AddFavorite(c)
 	}))
@@ -130,6 +174,9 @@ func NewServer(cfg config.ServerConfig) *Server {
 	favorites.Patch("/:songId/link", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
 		return h.Favorites.UpdateFavoriteLink(c)
 	}))
+	favorites.Post("/:songId/play", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
+		return h.Favorites.RecordFavoritePlay(c)
+	}))
 	favorites.Delete("/:songId", s.withHandlers(func(h *di.Handlers, c fiber.Ctx) error {
 		return h.Favorites.DeleteFavorite(c)
 	}))
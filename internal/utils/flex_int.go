@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// FlexInt decodes Last.fm-style numbers that arrive as either 213 or "213". Bad values are 0.
+type FlexInt int
+
+func (f *FlexInt) UnmarshalJSON(b []byte) error {
+	b = bytes.Trim(bytes.TrimSpace(b), `"`)
+	n, err := strconv.Atoi(string(b))
+	if err != nil {
+		var fl float64
+		if json.Unmarshal(b, &fl) != nil {
+			*f = 0
+			return nil
+		}
+		n = int(fl)
+	}
+	*f = FlexInt(n)
+	return nil
+}
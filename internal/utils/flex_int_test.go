@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFlexInt(t *testing.T) {
+	var v struct {
+		A, B, C, D FlexInt
+	}
+	if err := json.Unmarshal([]byte(`{"A":213,"B":"295000","C":"","D":null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != 213 || v.B != 295000 || v.C != 0 || v.D != 0 {
+		t.Fatalf("got %+v", v)
+	}
+}
@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RecordMode selects how HTTPRecorder treats outbound requests.
+type RecordMode string
+
+const (
+	RecordOff    RecordMode = ""
+	RecordReplay RecordMode = "replay"
+	RecordRecord RecordMode = "record"
+)
+
+// Query params that carry secrets — stripped from fixture keys and saved URLs.
+var recorderSecretParams = []string{"api_key"}
+
+var recorderNameClean = regexp.MustCompile(`[^a-z0-9]+`)
+
+// HTTPRecorder is a record/replay RoundTripper for provider + Last.fm fixtures.
+// Record: forwards to Next and saves each exchange under Dir. Replay: serves from Dir, never dials.
+type HTTPRecorder struct {
+	Mode RecordMode
+	Dir  string
+	Next http.RoundTripper
+
+	mu sync.Mutex
+}
+
+type recordedExchange struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"requestBody,omitempty"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        string      `json:"body"`
+}
+
+func NewHTTPRecorder(mode RecordMode, dir string, next http.RoundTripper) *HTTPRecorder {
+	return &HTTPRecorder{Mode: mode, Dir: dir, Next: next}
+}
+
+// Wrap swaps c's transport for the recorder (keeps the old one as Next). Off mode is a no-op.
+func (r *HTTPRecorder) Wrap(c *http.Client) *http.Client {
+	if r == nil || c == nil || r.Mode == RecordOff {
+		return c
+	}
+	if r.Next == nil {
+		r.Next = c.Transport
+		if r.Next == nil {
+			r.Next = http.DefaultTransport
+		}
+	}
+	c.Transport = r
+	return c
+}
+
+func (r *HTTPRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = b
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+	path := filepath.Join(r.Dir, fixtureName(req.Method, req.URL, reqBody))
+
+	switch r.Mode {
+	case RecordReplay:
+		return r.replay(req, path)
+	case RecordRecord:
+		return r.record(req, path, reqBody)
+	default:
+		return r.next().RoundTrip(req)
+	}
+}
+
+func (r *HTTPRecorder) next() http.RoundTripper {
+	if r.Next != nil {
+		return r.Next
+	}
+	return http.DefaultTransport
+}
+
+func (r *HTTPRecorder) replay(req *http.Request, path string) (*http.Response, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: no fixture for %s %s (%s)", req.Method, redactedURL(req.URL), filepath.Base(path))
+	}
+	var ex recordedExchange
+	if err := json.Unmarshal(raw, &ex); err != nil {
+		return nil, fmt.Errorf("replay: %s: %w", filepath.Base(path), err)
+	}
+	header := ex.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", ex.Status, http.StatusText(ex.Status)),
+		StatusCode:    ex.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(ex.Body)),
+		ContentLength: int64(len(ex.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *HTTPRecorder) record(req *http.Request, path string, reqBody []byte) (*http.Response, error) {
+	resp, err := r.next().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	// Cookies are session-bound; replay must not depend on them.
+	header.Del("Set-Cookie")
+	ex := recordedExchange{
+		Method:      req.Method,
+		URL:         redactedURL(req.URL),
+		RequestBody: string(reqBody),
+		Status:      resp.StatusCode,
+		Header:      header,
+		Body:        string(body),
+	}
+	// No HTML escaping — fixtures stay diffable when markup drifts.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(ex); err != nil {
+		return resp, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		GetLogger().Warn("fixture dir create failed", "dir", r.Dir, "error", err)
+		return resp, nil
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		GetLogger().Warn("fixture write failed", "path", path, "error", err)
+	}
+	return resp, nil
+}
+
+// fixtureName is host slug + short hash of method, redacted URL and body — stable across api keys.
+func fixtureName(method string, u *url.URL, body []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(method + " " + redactedURL(u) + "\n"))
+	sum.Write(body)
+	host := recorderNameClean.ReplaceAllString(strings.ToLower(u.Hostname()), "-")
+	host = strings.Trim(host, "-")
+	if host == "" {
+		host = "local"
+	}
+	return host + "-" + hex.EncodeToString(sum.Sum(nil))[:16] + ".json"
+}
+
+// redactedURL drops secret params and sorts the rest so keys are order-independent.
+func redactedURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	c := *u
+	c.User = nil
+	q := c.Query()
+	for _, k := range recorderSecretParams {
+		q.Del(k)
+	}
+	c.RawQuery = q.Encode() // Encode sorts by key
+	return c.String()
+}
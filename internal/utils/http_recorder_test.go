@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type deadTransport struct{}
+
+func (deadTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("network disabled")
+}
+
+func TestHTTPRecorderRecordThenReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "sid=secret")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("hello " + r.URL.Query().Get("q") + " " + string(body)))
+	}))
+	t.Cleanup(srv.Close)
+	dir := t.TempDir()
+
+	rec := NewHTTPRecorder(RecordRecord, dir, nil).Wrap(srv.Client())
+	resp, err := rec.Post(srv.URL+"/s?q=adele&api_key=k1", "text/plain", strings.NewReader("form"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	live, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// Different api_key + dead network — must still hit the fixture.
+	rep := NewHTTPRecorder(RecordReplay, dir, deadTransport{}).Wrap(&http.Client{})
+	resp, err = rep.Post(srv.URL+"/s?api_key=k2&q=adele", "text/plain", strings.NewReader("form"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(got) != string(live) || string(got) != "hello adele form" {
+		t.Fatalf("replay %q live %q", got, live)
+	}
+	if resp.Header.Get("Set-Cookie") != "" {
+		t.Fatal("cookies must not be recorded")
+	}
+
+	if _, err := rep.Post(srv.URL+"/s?q=adele", "text/plain", strings.NewReader("other")); err == nil {
+		t.Fatal("different body should miss")
+	}
+}
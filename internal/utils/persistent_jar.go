@@ -0,0 +1,254 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cookies without Expires/Max-Age live this long on disk — challenge sessions outlast a deploy.
+const defaultSessionCookieTTL = 24 * time.Hour
+
+// StoredCookie is one persisted cookie, scoped to the egress (proxy) that received it.
+type StoredCookie struct {
+	Egress   string    `json:"egress"`
+	Host     string    `json:"host"`
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	Expires  time.Time `json:"expires"`
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"httpOnly,omitempty"`
+}
+
+// CookieStore persists the scrape jar (Postgres table or a JSON file).
+type CookieStore interface {
+	LoadCookies(ctx context.Context) ([]StoredCookie, error)
+	SaveCookies(ctx context.Context, cookies []StoredCookie) error
+}
+
+// PersistentJar keeps one cookiejar per egress so a clearance cookie earned through
+// proxy A is never replayed through proxy B, and mirrors every cookie to a CookieStore.
+type PersistentJar struct {
+	egress     func() string
+	store      CookieStore
+	sessionTTL time.Duration
+
+	mu      sync.Mutex
+	jars    map[string]*cookiejar.Jar
+	records map[string]StoredCookie
+	dirty   bool
+}
+
+func NewPersistentJar(store CookieStore, egress func() string) *PersistentJar {
+	if egress == nil {
+		egress = func() string { return "direct" }
+	}
+	return &PersistentJar{
+		egress:     egress,
+		store:      store,
+		sessionTTL: defaultSessionCookieTTL,
+		jars:       make(map[string]*cookiejar.Jar),
+		records:    make(map[string]StoredCookie),
+	}
+}
+
+func (j *PersistentJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.setCookies(j.egress(), u, cookies)
+}
+
+func (j *PersistentJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.cookies(j.egress(), u)
+}
+
+// View shares this jar (and its store) with a client that picks its own egress,
+// e.g. a per-provider ScrapeClient fork. Cookies stay keyed by egress either way.
+func (j *PersistentJar) View(egress func() string) http.CookieJar {
+	return jarView{jar: j, egress: egress}
+}
+
+type jarView struct {
+	jar    *PersistentJar
+	egress func() string
+}
+
+func (v jarView) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	v.jar.setCookies(v.egress(), u, cookies)
+}
+
+func (v jarView) Cookies(u *url.URL) []*http.Cookie {
+	return v.jar.cookies(v.egress(), u)
+}
+
+func (j *PersistentJar) setCookies(egress string, u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.jarLocked(egress).SetCookies(u, cookies)
+	now := time.Now()
+	for _, c := range cookies {
+		rec := StoredCookie{
+			Egress: egress, Host: u.Hostname(), Name: c.Name, Value: c.Value,
+			Domain: c.Domain, Path: c.Path, Secure: c.Secure, HttpOnly: c.HttpOnly,
+		}
+		key := cookieRecordKey(rec)
+		switch {
+		case c.MaxAge < 0:
+			delete(j.records, key)
+			j.dirty = true
+			continue
+		case c.MaxAge > 0:
+			rec.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case !c.Expires.IsZero():
+			rec.Expires = c.Expires
+		default:
+			rec.Expires = now.Add(j.sessionTTL)
+		}
+		if !rec.Expires.After(now) {
+			delete(j.records, key)
+		} else {
+			j.records[key] = rec
+		}
+		j.dirty = true
+	}
+}
+
+func (j *PersistentJar) cookies(egress string, u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.jarLocked(egress).Cookies(u)
+}
+
+// Load replays unexpired stored cookies into their egress jars.
+func (j *PersistentJar) Load(ctx context.Context) (int, error) {
+	if j.store == nil {
+		return 0, nil
+	}
+	cookies, err := j.store.LoadCookies(ctx)
+	if err != nil {
+		return 0, err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	n := 0
+	for _, rec := range cookies {
+		if rec.Egress == "" || rec.Host == "" || !rec.Expires.After(now) {
+			continue
+		}
+		u := &url.URL{Scheme: "https", Host: rec.Host, Path: "/"}
+		j.jarLocked(rec.Egress).SetCookies(u, []*http.Cookie{{
+			Name: rec.Name, Value: rec.Value, Domain: rec.Domain, Path: rec.Path,
+			Expires: rec.Expires, Secure: rec.Secure, HttpOnly: rec.HttpOnly,
+		}})
+		j.records[cookieRecordKey(rec)] = rec
+		n++
+	}
+	return n, nil
+}
+
+// Flush writes the unexpired snapshot when anything changed since the last flush.
+func (j *PersistentJar) Flush(ctx context.Context) error {
+	if j.store == nil {
+		return nil
+	}
+	j.mu.Lock()
+	if !j.dirty {
+		j.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	out := make([]StoredCookie, 0, len(j.records))
+	for k, rec := range j.records {
+		if !rec.Expires.After(now) {
+			delete(j.records, k)
+			continue
+		}
+		out = append(out, rec)
+	}
+	j.dirty = false
+	j.mu.Unlock()
+
+	if err := j.store.SaveCookies(ctx, out); err != nil {
+		j.mu.Lock()
+		j.dirty = true
+		j.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes every interval until ctx ends (final flush on the way out).
+func (j *PersistentJar) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = j.Flush(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			if err := j.Flush(ctx); err != nil {
+				GetLogger().Warn("scrape cookie flush failed", "error", err)
+			}
+		}
+	}
+}
+
+func (j *PersistentJar) jarLocked(egress string) *cookiejar.Jar {
+	jar := j.jars[egress]
+	if jar == nil {
+		jar, _ = cookiejar.New(nil)
+		j.jars[egress] = jar
+	}
+	return jar
+}
+
+func cookieRecordKey(c StoredCookie) string {
+	return c.Egress + "\x00" + c.Host + "\x00" + c.Domain + "\x00" + c.Path + "\x00" + c.Name
+}
+
+// FileCookieStore keeps the jar in one JSON file (0600 — cookies are credentials).
+type FileCookieStore struct {
+	Path string
+}
+
+func (s FileCookieStore) LoadCookies(context.Context) ([]StoredCookie, error) {
+	raw, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []StoredCookie
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s FileCookieStore) SaveCookies(_ context.Context, cookies []StoredCookie) error {
+	raw, err := json.Marshal(cookies)
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(s.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+	}
+	// Write + rename so a crash mid-write never leaves a truncated jar.
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
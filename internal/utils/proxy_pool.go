@@ -1,30 +1,75 @@
 package utils
 
 import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 )
 
-// ProxyPool round-robins outbound proxies and cools down ones that get blocked.
+// ProxyPool hands out a sticky outbound proxy, scores each one (success rate, latency,
+// last block) and cools failing ones down exponentially. When the sticky proxy has to
+// change, the next one is drawn weighted by score.
 // Empty pool → callers should fall back to http.ProxyFromEnvironment / direct.
 type ProxyPool struct {
-	mu       sync.Mutex
-	entries  []*url.URL
-	index    int
-	cooldown map[string]time.Time
-	coolFor  time.Duration
+	mu      sync.Mutex
+	entries []*url.URL
+	index   int
+	health  map[string]*proxyHealth
+	coolFor time.Duration
+	maxCool time.Duration
+	rnd     func() float64
 }
 
+type proxyHealth struct {
+	successes   int
+	failures    int
+	latency     time.Duration // EWMA of time-to-headers on success
+	lastBlocked time.Time
+	strikes     int // consecutive MarkBad/probe failures → cool-down exponent
+	coolUntil   time.Time
+	blockUntil  time.Time // MarkBad's share of coolUntil; a passing probe can't lift it
+}
+
+// ProxyHealth is one proxy's score snapshot; Proxy has the password redacted.
+type ProxyHealth struct {
+	Proxy        string     `json:"proxy"`
+	Score        float64    `json:"score"`
+	Successes    int        `json:"successes"`
+	Failures     int        `json:"failures"`
+	SuccessRate  float64    `json:"successRate"`
+	LatencyMs    int64      `json:"latencyMs"`
+	Strikes      int        `json:"strikes"`
+	LastBlocked  *time.Time `json:"lastBlocked,omitempty"`
+	CoolingUntil *time.Time `json:"coolingUntil,omitempty"`
+	Current      bool       `json:"current"`
+}
+
+const (
+	// Recently blocked proxies keep working but at half weight for this long.
+	proxyBlockMemory = 10 * time.Minute
+	proxyMinScore    = 0.01
+)
+
 func NewProxyPool(entries []*url.URL) *ProxyPool {
 	if len(entries) == 0 {
 		return nil
 	}
+	health := make(map[string]*proxyHealth, len(entries))
+	for _, u := range entries {
+		health[u.String()] = &proxyHealth{}
+	}
 	return &ProxyPool{
-		entries:  entries,
-		cooldown: make(map[string]time.Time, len(entries)),
-		coolFor:  2 * time.Minute,
+		entries: entries,
+		health:  health,
+		coolFor: 2 * time.Minute,
+		maxCool: time.Hour,
+		rnd:     rand.Float64,
 	}
 }
 
@@ -74,7 +119,8 @@ func (p *ProxyPool) Len() int {
 	return len(p.entries)
 }
 
-// Current returns a sticky proxy (skips cooled-down entries). nil pool → nil URL (direct/env).
+// Current returns the sticky proxy, or a score-weighted pick when it is cooling down.
+// nil pool → nil URL (direct/env).
 func (p *ProxyPool) Current() *url.URL {
 	if p == nil || len(p.entries) == 0 {
 		return nil
@@ -84,7 +130,7 @@ func (p *ProxyPool) Current() *url.URL {
 	return p.pickLocked()
 }
 
-// MarkBad cools down the current proxy and advances to the next one.
+// MarkBad cools down the current proxy (2m, 4m, 8m … capped at maxCool) and moves off it.
 func (p *ProxyPool) MarkBad() {
 	if p == nil || len(p.entries) == 0 {
 		return
@@ -92,28 +138,108 @@ func (p *ProxyPool) MarkBad() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	cur := p.entries[p.index%len(p.entries)]
-	p.cooldown[cur.String()] = time.Now().Add(p.coolFor)
-	p.index = (p.index + 1) % len(p.entries)
-	GetLogger().Warn("provider proxy cooled down", "proxy", redactedProxy(cur), "coolFor", p.coolFor.String())
+	h := p.health[cur.String()]
+	h.failures++
+	h.lastBlocked = time.Now()
+	cool := p.strikeLocked(h)
+	h.blockUntil = h.coolUntil
+	GetLogger().Warn("provider proxy cooled down", "proxy", redactedProxy(cur), "coolFor", cool.String(), "strikes", h.strikes)
+}
+
+// strikeLocked extends h's cool-down exponentially and returns its length.
+func (p *ProxyPool) strikeLocked(h *proxyHealth) time.Duration {
+	h.strikes++
+	cool := p.coolFor
+	for i := 1; i < h.strikes && cool < p.maxCool; i++ {
+		cool *= 2
+	}
+	if cool > p.maxCool {
+		cool = p.maxCool
+	}
+	h.coolUntil = time.Now().Add(cool)
+	return cool
+}
+
+// record folds one request outcome into the proxy's score. Success clears strikes;
+// failure only counts — cooling is MarkBad's (and the prober's) call.
+func (p *ProxyPool) record(key string, ok bool, latency time.Duration) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h := p.health[key]
+	if h == nil {
+		return
+	}
+	if !ok {
+		h.failures++
+		return
+	}
+	h.successes++
+	h.strikes = 0
+	if h.latency == 0 {
+		h.latency = latency
+	} else {
+		h.latency = (h.latency*4 + latency) / 5
+	}
+}
+
+// score: Laplace-smoothed success rate ÷ (1 + latency in seconds), halved after a recent block.
+func (h *proxyHealth) score(now time.Time) float64 {
+	s := float64(h.successes+1) / float64(h.successes+h.failures+2)
+	s /= 1 + h.latency.Seconds()
+	if !h.lastBlocked.IsZero() && now.Sub(h.lastBlocked) < proxyBlockMemory {
+		s /= 2
+	}
+	if s < proxyMinScore {
+		s = proxyMinScore
+	}
+	return s
 }
 
 func (p *ProxyPool) pickLocked() *url.URL {
 	n := len(p.entries)
 	now := time.Now()
-	for i := 0; i < n; i++ {
-		idx := (p.index + i) % n
-		u := p.entries[idx]
-		if until, ok := p.cooldown[u.String()]; ok && now.Before(until) {
-			continue
+	cur := p.entries[p.index%n]
+	if now.After(p.health[cur.String()].coolUntil) {
+		return cur
+	}
+
+	// Sticky proxy is cooling — weighted draw over the rest.
+	total := 0.0
+	weights := make([]float64, n)
+	for i, u := range p.entries {
+		if h := p.health[u.String()]; now.After(h.coolUntil) {
+			weights[i] = h.score(now)
+			total += weights[i]
 		}
-		p.index = idx
-		return u
 	}
+	if total > 0 {
+		r := p.rnd() * total
+		for i, w := range weights {
+			if w == 0 {
+				continue
+			}
+			r -= w
+			if r < 0 {
+				p.index = i
+				return p.entries[i]
+			}
+		}
+		for i := n - 1; i >= 0; i-- { // float slack
+			if weights[i] > 0 {
+				p.index = i
+				return p.entries[i]
+			}
+		}
+	}
+
 	// All cooling — pick the one that frees soonest.
-	best := p.entries[p.index%n]
-	bestUntil := p.cooldown[best.String()]
+	best := cur
+	bestUntil := p.health[best.String()].coolUntil
 	for _, u := range p.entries {
-		if until := p.cooldown[u.String()]; until.Before(bestUntil) {
+		if until := p.health[u.String()].coolUntil; until.Before(bestUntil) {
 			best = u
 			bestUntil = until
 		}
@@ -122,6 +248,118 @@ func (p *ProxyPool) pickLocked() *url.URL {
 	return best
 }
 
+// Health snapshots every proxy's score, best first.
+func (p *ProxyPool) Health() []ProxyHealth {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	cur := p.entries[p.index%len(p.entries)].String()
+	out := make([]ProxyHealth, 0, len(p.entries))
+	for _, u := range p.entries {
+		h := p.health[u.String()]
+		row := ProxyHealth{
+			Proxy:     redactedProxy(u),
+			Score:     h.score(now),
+			Successes: h.successes,
+			Failures:  h.failures,
+			LatencyMs: h.latency.Milliseconds(),
+			Strikes:   h.strikes,
+			Current:   u.String() == cur,
+		}
+		if total := h.successes + h.failures; total > 0 {
+			row.SuccessRate = float64(h.successes) / float64(total)
+		}
+		if !h.lastBlocked.IsZero() {
+			t := h.lastBlocked
+			row.LastBlocked = &t
+		}
+		if now.Before(h.coolUntil) {
+			t := h.coolUntil
+			row.CoolingUntil = &t
+		}
+		out = append(out, row)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// Probe GETs probeURL through every proxy (concurrently, each bounded by timeout).
+// A pass clears probe-failure cool-downs early (a site block from MarkBad still
+// runs its course — the probe URL isn't the blocking site); a failure strikes like MarkBad.
+func (p *ProxyPool) Probe(ctx context.Context, probeURL string, timeout time.Duration) {
+	if p == nil || probeURL == "" {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, u := range p.entries {
+		wg.Add(1)
+		go func(u *url.URL) {
+			defer wg.Done()
+			latency, err := probeProxy(ctx, u, probeURL, timeout)
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			h := p.health[u.String()]
+			if err != nil {
+				h.failures++
+				cool := p.strikeLocked(h)
+				GetLogger().Debug("proxy probe failed", "proxy", redactedProxy(u), "coolFor", cool.String(), "error", err)
+				return
+			}
+			h.successes++
+			h.strikes = 0
+			h.coolUntil = h.blockUntil
+			if h.latency == 0 {
+				h.latency = latency
+			} else {
+				h.latency = (h.latency*4 + latency) / 5
+			}
+		}(u)
+	}
+	wg.Wait()
+}
+
+// RunProber probes every interval until ctx ends. interval ≤ 0 disables it.
+func (p *ProxyPool) RunProber(ctx context.Context, probeURL string, interval, timeout time.Duration) {
+	if p == nil || probeURL == "" || interval <= 0 {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		p.Probe(ctx, probeURL, timeout)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func probeProxy(ctx context.Context, u *url.URL, probeURL string, timeout time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	tr := buildTransport(u, 1, 1, timeout)
+	defer tr.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return 0, fmt.Errorf("probe status %d", resp.StatusCode)
+	}
+	return time.Since(start), nil
+}
+
 func indexOfURL(list []*url.URL, want *url.URL) int {
 	for i, u := range list {
 		if u.String() == want.String() {
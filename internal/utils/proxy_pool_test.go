@@ -1,7 +1,13 @@
 package utils
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseProxyList(t *testing.T) {
@@ -35,3 +41,86 @@ func TestProxyPoolNil(t *testing.T) {
 	}
 	pool.MarkBad() // must not panic
 }
+
+func TestProxyPoolExponentialCooldown(t *testing.T) {
+	pool := NewProxyPool(ParseProxyList("http://p1:1,http://p2:2"))
+	h := pool.health["http://p1:1"]
+	pool.MarkBad()
+	first := time.Until(h.coolUntil)
+	pool.index = 0
+	pool.MarkBad()
+	second := time.Until(h.coolUntil)
+	if first < time.Minute || second < 3*time.Minute || h.strikes != 2 {
+		t.Fatalf("cool-downs %v then %v (strikes %d)", first, second, h.strikes)
+	}
+	for i := 0; i < 10; i++ {
+		pool.index = 0
+		pool.MarkBad()
+	}
+	if time.Until(h.coolUntil) > pool.maxCool {
+		t.Fatalf("cool-down exceeds cap: %v", time.Until(h.coolUntil))
+	}
+	pool.record("http://p1:1", true, 50*time.Millisecond)
+	if h.strikes != 0 {
+		t.Fatal("success should clear strikes")
+	}
+}
+
+func TestProxyPoolWeightedPick(t *testing.T) {
+	pool := NewProxyPool(ParseProxyList("http://cur:1,http://good:2,http://flaky:3"))
+	for i := 0; i < 20; i++ {
+		pool.record("http://good:2", true, 100*time.Millisecond)
+		pool.record("http://flaky:3", false, 0)
+	}
+	pool.rnd = func() float64 { return 0.5 }
+	pool.MarkBad() // cur cools; good dominates the remaining weight
+	if got := pool.Current().Host; got != "good:2" {
+		t.Fatalf("weighted pick = %q", got)
+	}
+	// Sticky afterwards.
+	pool.rnd = func() float64 { return 0.999 }
+	if got := pool.Current().Host; got != "good:2" {
+		t.Fatalf("not sticky: %q", got)
+	}
+}
+
+func TestProxyPoolProbeAndHealth(t *testing.T) {
+	// httptest server as an HTTP proxy: answers every forwarded GET itself.
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(proxySrv.Close)
+	good, _ := url.Parse(proxySrv.URL)
+	good.User = url.UserPassword("user", "secret")
+	dead, _ := url.Parse("http://127.0.0.1:1")
+	pool := NewProxyPool([]*url.URL{good, dead})
+
+	pool.Probe(context.Background(), "http://probe.invalid/generate_204", 2*time.Second)
+
+	rows := pool.Health()
+	if len(rows) != 2 {
+		t.Fatalf("rows: %d", len(rows))
+	}
+	best, worst := rows[0], rows[1]
+	if best.Successes != 1 || best.CoolingUntil != nil || strings.Contains(best.Proxy, "secret") || !strings.HasPrefix(best.Proxy, "http://user:") {
+		t.Fatalf("good proxy row: %+v", best)
+	}
+	if worst.Failures != 1 || worst.CoolingUntil == nil || worst.Strikes != 1 {
+		t.Fatalf("dead proxy row: %+v", worst)
+	}
+}
+
+func TestProxyPoolProbeKeepsBlockCooldown(t *testing.T) {
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(proxySrv.Close)
+	good, _ := url.Parse(proxySrv.URL)
+	pool := NewProxyPool([]*url.URL{good})
+
+	pool.MarkBad()
+	pool.Probe(context.Background(), "http://probe.invalid/generate_204", 2*time.Second)
+	if row := pool.Health()[0]; row.Successes != 1 || row.CoolingUntil == nil {
+		t.Fatalf("probe lifted a block cool-down: %+v", row)
+	}
+}
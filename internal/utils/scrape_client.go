@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,6 +21,31 @@ type ScrapeClient struct {
 	rt   *rotatingTransport
 }
 
+// Fork builds a client with its own proxy pool for one provider: proxies when given,
+// else a private copy of this client's entries (so one provider's blocks and rotation
+// never move another's egress). A PersistentJar is shared through a per-egress view.
+func (c *ScrapeClient) Fork(name string, proxies []*url.URL) *ScrapeClient {
+	if len(proxies) == 0 && c.Pool != nil {
+		proxies = c.Pool.entries
+	}
+	pool := NewProxyPool(proxies)
+	rt := newRotatingTransport(pool, c.rt.maxIdleConns, c.rt.maxIdlePerHost, c.rt.idleTimeout)
+	fork := &ScrapeClient{
+		Client: &http.Client{Timeout: c.Timeout, Transport: rt},
+		Pool:   pool,
+		rt:     rt,
+	}
+	if pj, ok := c.Jar.(*PersistentJar); ok {
+		fork.Jar = pj.View(fork.EgressKey)
+	} else if jar, err := cookiejar.New(nil); err == nil {
+		fork.Jar = jar
+	}
+	if pool != nil {
+		GetLogger().Info("provider proxy pool ready", "provider", name, "count", pool.Len())
+	}
+	return fork
+}
+
 // NewScrapeClient builds a jar-enabled client. proxies may be empty (still honors HTTP(S)_PROXY).
 func NewScrapeClient(timeout time.Duration, maxIdleConns, maxIdlePerHost int, idleTimeout time.Duration, proxies []*url.URL) *ScrapeClient {
 	pool := NewProxyPool(proxies)
@@ -39,6 +65,30 @@ func NewScrapeClient(timeout time.Duration, maxIdleConns, maxIdlePerHost int, id
 	return &ScrapeClient{Client: client, Pool: pool, rt: rt}
 }
 
+// UsePersistentJar swaps the in-memory jar for one keyed by egress and backed by store.
+// Stored cookies are loaded before returning; the caller owns the flush loop.
+func (c *ScrapeClient) UsePersistentJar(ctx context.Context, store CookieStore) (*PersistentJar, error) {
+	jar := NewPersistentJar(store, c.EgressKey)
+	n, err := jar.Load(ctx)
+	c.Jar = jar
+	if err != nil {
+		return jar, err
+	}
+	GetLogger().Info("scrape cookies restored", "count", n)
+	return jar, nil
+}
+
+// EgressKey names the current egress for cookie scoping: "direct" or the proxy (password redacted).
+func (c *ScrapeClient) EgressKey() string {
+	if c == nil || c.Pool == nil || c.Pool.Len() == 0 {
+		return "direct"
+	}
+	if u := c.Pool.Current(); u != nil {
+		return redactedProxy(u)
+	}
+	return "direct"
+}
+
 // Rotate moves to the next proxy after a block / hard failure.
 func (c *ScrapeClient) Rotate() {
 	if c == nil {
@@ -53,13 +103,22 @@ func (c *ScrapeClient) Rotate() {
 	}
 	// Cookies are IP/session-bound — only drop them when egress actually changes.
 	// Clearing the jar on a direct-only client makes 403 loops worse (loses _vt etc.).
-	if hadProxy {
+	// A PersistentJar is already scoped per egress, so rotation just switches jars.
+	if hadProxy && !egressScoped(c.Jar) {
 		if jar, err := cookiejar.New(nil); err == nil {
 			c.Jar = jar
 		}
 	}
 }
 
+func egressScoped(jar http.CookieJar) bool {
+	switch jar.(type) {
+	case *PersistentJar, jarView:
+		return true
+	}
+	return false
+}
+
 // AttemptBudget how many fetch tries (direct/env + each proxy, capped).
 func (c *ScrapeClient) AttemptBudget(fallback int) int {
 	if fallback < 1 {
@@ -102,8 +161,14 @@ func newRotatingTransport(pool *ProxyPool, maxIdleConns, maxIdlePerHost int, idl
 }
 
 func (rt *rotatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	tr := rt.pickTransport()
-	return tr.RoundTrip(req)
+	tr, key := rt.pickTransport()
+	start := time.Now()
+	resp, err := tr.RoundTrip(req)
+	if key != "" {
+		ok := err == nil && resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests
+		rt.pool.record(key, ok, time.Since(start))
+	}
+	return resp, err
 }
 
 func (rt *rotatingTransport) invalidateSticky() {
@@ -112,13 +177,14 @@ func (rt *rotatingTransport) invalidateSticky() {
 	rt.mu.Unlock()
 }
 
-func (rt *rotatingTransport) pickTransport() *http.Transport {
+// pickTransport returns the transport and its proxy key ("" = direct/env).
+func (rt *rotatingTransport) pickTransport() (*http.Transport, string) {
 	if rt.pool == nil || rt.pool.Len() == 0 {
-		return rt.direct
+		return rt.direct, ""
 	}
 	u := rt.pool.Current()
 	if u == nil {
-		return rt.direct
+		return rt.direct, ""
 	}
 	key := u.String()
 
@@ -129,16 +195,16 @@ func (rt *rotatingTransport) pickTransport() *http.Transport {
 	}
 	// Prefer sticky until Rotate clears it.
 	if t := rt.cached[rt.sticky]; t != nil {
-		return t
+		return t, rt.sticky
 	}
 	if t := rt.cached[key]; t != nil {
 		rt.sticky = key
-		return t
+		return t, key
 	}
 	t := buildTransport(u, rt.maxIdleConns, rt.maxIdlePerHost, rt.idleTimeout)
 	rt.cached[key] = t
 	rt.sticky = key
-	return t
+	return t, key
 }
 
 func buildTransport(proxyURL *url.URL, maxIdleConns, maxIdlePerHost int, idleTimeout time.Duration) *http.Transport {
@@ -156,7 +222,7 @@ func buildTransport(proxyURL *url.URL, maxIdleConns, maxIdlePerHost int, idleTim
 		return tr
 	}
 
-	scheme := proxyURL.Scheme
+	scheme := strings.ToLower(proxyURL.Scheme)
 	switch scheme {
 	case "socks5", "socks5h":
 		dialer, err := proxy.FromURL(proxyURL, proxy.Direct)
@@ -165,14 +231,34 @@ func buildTransport(proxyURL *url.URL, maxIdleConns, maxIdlePerHost int, idleTim
 			return tr
 		}
 		tr.Proxy = nil
+		dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.Dial(network, addr)
+		}
 		if cd, ok := dialer.(proxy.ContextDialer); ok {
-			tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
-				return cd.DialContext(ctx, network, addr)
+			dial = cd.DialContext
+		}
+		if scheme == "socks5h" {
+			// Hostname goes to the proxy; it resolves (no local DNS leak).
+			tr.DialContext = dial
+			break
+		}
+		// socks5: resolve here, hand the proxy an IP.
+		tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
 			}
-		} else {
-			tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
-				return dialer.Dial(network, addr)
+			if net.ParseIP(host) == nil {
+				ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+				if err != nil {
+					return nil, err
+				}
+				if len(ips) == 0 {
+					return nil, &net.DNSError{Err: "no addresses", Name: host, IsNotFound: true}
+				}
+				addr = net.JoinHostPort(ips[0].IP.String(), port)
 			}
+			return dial(ctx, network, addr)
 		}
 	default:
 		tr.Proxy = http.ProxyURL(proxyURL)
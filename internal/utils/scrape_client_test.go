@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestForkRotatesOwnPool(t *testing.T) {
+	shared := NewScrapeClient(time.Second, 2, 2, time.Second, ParseProxyList("http://a:1,http://b:2"))
+	mp3pm := shared.Fork("Mp3pm", nil)
+	musify := shared.Fork("Musify", ParseProxyList("http://m:3,http://n:4"))
+
+	before := shared.Pool.Current().Host
+	mp3pm.Rotate()
+	if shared.Pool.Current().Host != before {
+		t.Fatal("forked rotate moved the shared pool")
+	}
+	if mp3pm.Pool.Len() != 2 || mp3pm.Pool.Current().Host == before {
+		t.Fatalf("fork should copy shared entries and rotate them: %v", mp3pm.Pool.Current())
+	}
+	if musify.Pool.Current().Host != "m:3" {
+		t.Fatalf("own list ignored: %v", musify.Pool.Current())
+	}
+}
+
+func TestForkSharesPersistentJarPerEgress(t *testing.T) {
+	shared := NewScrapeClient(time.Second, 2, 2, time.Second, nil)
+	store := &memCookieStore{}
+	if _, err := shared.UsePersistentJar(t.Context(), store); err != nil {
+		t.Fatal(err)
+	}
+	fork := shared.Fork("Musify", ParseProxyList("http://m:3"))
+	u, _ := url.Parse("https://musify.club/")
+	fork.Jar.SetCookies(u, []*http.Cookie{{Name: "vt", Value: "1"}})
+
+	if len(shared.Jar.Cookies(u)) != 0 {
+		t.Fatal("direct egress must not see the proxy's cookie")
+	}
+	if got := fork.Jar.Cookies(u); len(got) != 1 || got[0].Value != "1" {
+		t.Fatalf("fork cookies: %v", got)
+	}
+	if err := shared.Jar.(*PersistentJar).Flush(t.Context()); err != nil || len(store.saved) != 1 || store.saved[0].Egress != "http://m:3" {
+		t.Fatalf("flush: err=%v saved=%v", err, store.saved)
+	}
+}
+
+func TestSocksProxyDNSAndAuth(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(target.Close)
+	_, port, _ := net.SplitHostPort(target.Listener.Addr().String())
+
+	for scheme, wantDomain := range map[string]bool{"socks5h": true, "socks5": false} {
+		seen := make(chan byte, 1)
+		proxyAddr := serveSocks5(t, "user", "pass", seen)
+		pu, _ := url.Parse(scheme + "://user:pass@" + proxyAddr)
+		client := &http.Client{Transport: buildTransport(pu, 1, 1, time.Second), Timeout: 5 * time.Second}
+
+		resp, err := client.Get("http://localhost:" + port + "/")
+		if err != nil {
+			t.Fatalf("%s: %v", scheme, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "ok" {
+			t.Fatalf("%s body %q", scheme, body)
+		}
+		if atyp := <-seen; (atyp == 0x03) != wantDomain {
+			t.Fatalf("%s: address type %#x", scheme, atyp)
+		}
+	}
+}
+
+type memCookieStore struct{ saved []StoredCookie }
+
+func (m *memCookieStore) LoadCookies(_ context.Context) ([]StoredCookie, error) { return nil, nil }
+func (m *memCookieStore) SaveCookies(_ context.Context, c []StoredCookie) error {
+	m.saved = c
+	return nil
+}
+
+// serveSocks5 is a one-connection RFC 1928/1929 server requiring user/pass; it reports
+// the CONNECT address type (0x01 IPv4, 0x03 domain, 0x04 IPv6) on seen.
+func serveSocks5(t *testing.T, user, pass string, seen chan<- byte) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		hdr := make([]byte, 2)
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			return
+		}
+		methods := make([]byte, hdr[1])
+		_, _ = io.ReadFull(r, methods)
+		_, _ = conn.Write([]byte{0x05, 0x02}) // username/password
+
+		ver := make([]byte, 2)
+		_, _ = io.ReadFull(r, ver)
+		u := make([]byte, ver[1])
+		_, _ = io.ReadFull(r, u)
+		plen, _ := r.ReadByte()
+		p := make([]byte, plen)
+		_, _ = io.ReadFull(r, p)
+		if string(u) != user || string(p) != pass {
+			_, _ = conn.Write([]byte{0x01, 0x01})
+			return
+		}
+		_, _ = conn.Write([]byte{0x01, 0x00})
+
+		req := make([]byte, 4)
+		if _, err := io.ReadFull(r, req); err != nil {
+			return
+		}
+		var host string
+		switch req[3] {
+		case 0x01:
+			ip := make([]byte, 4)
+			_, _ = io.ReadFull(r, ip)
+			host = net.IP(ip).String()
+		case 0x03:
+			n, _ := r.ReadByte()
+			name := make([]byte, n)
+			_, _ = io.ReadFull(r, name)
+			host = string(name)
+		case 0x04:
+			ip := make([]byte, 16)
+			_, _ = io.ReadFull(r, ip)
+			host = net.IP(ip).String()
+		}
+		pb := make([]byte, 2)
+		_, _ = io.ReadFull(r, pb)
+		seen <- req[3]
+
+		up, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(pb)))))
+		if err != nil {
+			_, _ = conn.Write([]byte{0x05, 0x04, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+			return
+		}
+		defer up.Close()
+		_, _ = conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+		go func() { _, _ = io.Copy(up, r) }()
+		_, _ = io.Copy(conn, up)
+	}()
+	return ln.Addr().String()
+}